@@ -0,0 +1,205 @@
+// Package aip implements the filtering, ordering and pagination conventions of Google's API
+// Improvement Proposals for List methods:
+//
+//   - AIP-160 filter strings, parsed into a filter.FilterGroup
+//   - AIP-132 order_by strings, parsed into []sort.Criteria
+//   - AIP-158 page_size and opaque page_token values, translated to and from the limit and
+//     offset used by the pagination package
+//
+// Example handler for a ListBooks RPC:
+//
+//	group, err := aip.ParseFilter(req.Filter)
+//	if err != nil {
+//	    return nil, status.Error(codes.InvalidArgument, err.Error())
+//	}
+//	orderBy, err := aip.ParseOrderBy(req.OrderBy)
+//	...
+//	limit, offset, err := aip.ParsePage(req.PageSize, req.PageToken, req.Filter+"|"+req.OrderBy, aip.PageOptions{})
+//	...
+//	page := pagination.NewPage(books, total, limit, offset)
+//	return &pb.ListBooksResponse{
+//	    Books:         page.Data,
+//	    NextPageToken: aip.NextPageToken(page, req.Filter+"|"+req.OrderBy),
+//	}, nil
+package aip
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/tone-labs/dewey/internal/parse"
+	"github.com/tone-labs/dewey/pagination"
+	"github.com/tone-labs/dewey/sort"
+)
+
+// SyntaxError reports a malformed filter or order_by string. Pos is the byte offset in the
+// input where the problem was found. Err carries the matching filter sentinel, if any, such
+// as filter.ErrUnsupportedOperator for an unknown function.
+type SyntaxError parse.Error
+
+func (e *SyntaxError) Error() string {
+	return (*parse.Error)(e).Message("aip")
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+func syntaxError(pos int, format string, args ...any) *SyntaxError {
+	return (*SyntaxError)(parse.Errorf(pos, format, args...))
+}
+
+// ParseOrderBy parses an AIP-132 order_by string such as "create_time desc, author.name".
+// Fields are ascending unless followed by "desc"; an explicit "asc" is also accepted.
+func ParseOrderBy(orderBy string) ([]sort.Criteria, error) {
+	if strings.TrimSpace(orderBy) == "" {
+		return nil, nil
+	}
+
+	var criteria []sort.Criteria
+	for start := 0; start <= len(orderBy); {
+		end := strings.IndexByte(orderBy[start:], ',')
+		if end < 0 {
+			end = len(orderBy)
+		} else {
+			end += start
+		}
+
+		item := orderBy[start:end]
+		words := strings.Fields(item)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, syntaxError(start, "expected field and optional direction, got %q", strings.TrimSpace(item))
+		}
+		if !validField(words[0]) {
+			return nil, syntaxError(start+strings.Index(item, words[0]), "invalid field %q", words[0])
+		}
+
+		c := sort.Criteria{Field: words[0], Order: sort.Asc}
+		if len(words) == 2 {
+			switch words[1] {
+			case "asc":
+			case "desc":
+				c.Order = sort.Desc
+			default:
+				return nil, syntaxError(start+strings.LastIndex(item, words[1]), "expected asc or desc, got %q", words[1])
+			}
+		}
+		criteria = append(criteria, c)
+		start = end + 1
+	}
+	return criteria, nil
+}
+
+// validField reports whether s is a dotted path of identifiers, e.g. "author.name".
+func validField(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if part == "" {
+			return false
+		}
+		for i, c := range part {
+			letter := c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+			if !letter && (i == 0 || c < '0' || c > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ErrInvalidPageToken is reported for page tokens that are malformed or were issued for a
+// request with different parameters.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// ErrInvalidPageSize is reported for negative page sizes.
+var ErrInvalidPageSize = errors.New("invalid page size")
+
+// Default page sizes used when the corresponding PageOptions field is zero.
+const (
+	DefaultPageSize    = 50
+	DefaultMaxPageSize = 1000
+)
+
+// PageOptions configures page_size handling.
+type PageOptions struct {
+	// DefaultSize is used when page_size is 0 (default DefaultPageSize)
+	DefaultSize int
+
+	// MaxSize caps page_size; larger values are coerced down as AIP-158 requires
+	// (default DefaultMaxPageSize)
+	MaxSize int
+}
+
+// pageToken is the decoded form of a page_token.
+type pageToken struct {
+	Offset int    `json:"o"`
+	Query  uint64 `json:"q"` // fingerprint of the request parameters
+}
+
+// ParsePage converts page_size and page_token into the limit and offset for pagination.Apply.
+//
+// query identifies the request parameters that must stay the same between pages, typically the
+// filter and order_by strings. A token issued by NextPageToken for a different query is rejected
+// with ErrInvalidPageToken. An empty token starts at offset 0.
+func ParsePage[S ~int | ~int32 | ~int64](pageSize S, token, query string, opts PageOptions) (limit, offset int, err error) {
+	if pageSize < 0 {
+		return 0, 0, fmt.Errorf("aip: %w: %d", ErrInvalidPageSize, pageSize)
+	}
+
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxPageSize
+	}
+	limit = opts.DefaultSize
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if pageSize > 0 {
+		limit = int(min(int64(pageSize), int64(maxSize)))
+	}
+	limit = min(limit, maxSize)
+
+	if token == "" {
+		return limit, 0, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, 0, fmt.Errorf("aip: %w", ErrInvalidPageToken)
+	}
+	var t pageToken
+	if err := json.Unmarshal(data, &t); err != nil || t.Offset < 0 {
+		return 0, 0, fmt.Errorf("aip: %w", ErrInvalidPageToken)
+	}
+	if t.Query != fingerprint(query) {
+		return 0, 0, fmt.Errorf("aip: %w: request parameters changed", ErrInvalidPageToken)
+	}
+
+	return limit, t.Offset, nil
+}
+
+// NextPageToken returns the page_token for the page after p, or "" when p is the last page.
+// query must be the same value passed to ParsePage.
+//
+// Tokens are opaque to clients but not encrypted or signed; they only carry the next offset
+// and a fingerprint of query.
+func NextPageToken[T any](p pagination.Page[T], query string) string {
+	if p.Limit <= 0 || !p.HasNextPage() {
+		return ""
+	}
+
+	data, err := json.Marshal(pageToken{Offset: p.Offset + p.Limit, Query: fingerprint(query)})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func fingerprint(query string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(query))
+	return h.Sum64()
+}
@@ -0,0 +1,323 @@
+package aip_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tone-labs/dewey/aip"
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/pagination"
+	"github.com/tone-labs/dewey/sort"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected filter.FilterGroup
+	}{
+		{
+			name: "comparison",
+			expr: `rating >= 4`,
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "rating", Operator: filter.OpGte, Value: "4"}},
+			},
+		},
+		{
+			name: "or binds tighter than and",
+			expr: `a = 1 AND b = 2 OR c = 3`,
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "a", Operator: filter.OpEq, Value: "1"}},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "or",
+						Filters: []filter.Filter{
+							{Field: "b", Operator: filter.OpEq, Value: "2"},
+							{Field: "c", Operator: filter.OpEq, Value: "3"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "whitespace is an implicit and",
+			expr: `state = "ACTIVE" price < 9.99`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "state", Operator: filter.OpEq, Value: "ACTIVE"},
+					{Field: "price", Operator: filter.OpLt, Value: "9.99"},
+				},
+			},
+		},
+		{
+			name: "negation",
+			expr: `NOT archived = true -state = "DELETED"`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Groups: []filter.FilterGroup{
+					{Logic: "and", Not: true, Filters: []filter.Filter{{Field: "archived", Operator: filter.OpEq, Value: "true"}}},
+					{Logic: "and", Not: true, Filters: []filter.Filter{{Field: "state", Operator: filter.OpEq, Value: "DELETED"}}},
+				},
+			},
+		},
+		{
+			name: "traversal and has",
+			expr: `author.address.city = "Paris" AND labels:urgent AND metadata.owner:*`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "author.address.city", Operator: filter.OpEq, Value: "Paris"},
+					{Field: "labels", Operator: filter.OpHas, Value: "urgent"},
+					{Field: "metadata.owner", Operator: filter.OpNnull},
+				},
+			},
+		},
+		{
+			name: "wildcards",
+			expr: `name = "proj-*" AND email = "*@acme.com" AND title != "*draft*" AND code = "a*b"`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "name", Operator: filter.OpStartsWithCS, Value: "proj-"},
+					{Field: "email", Operator: filter.OpEndsWithCS, Value: "@acme.com"},
+					{Field: "code", Operator: filter.OpEq, Value: "a*b"},
+				},
+				Groups: []filter.FilterGroup{
+					{Logic: "and", Not: true, Filters: []filter.Filter{{Field: "title", Operator: filter.OpContainsCS, Value: "draft"}}},
+				},
+			},
+		},
+		{
+			name: "value lists",
+			expr: `region = (eu OR "us-east") AND rating > (1 AND 2)`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "region", Operator: filter.OpIn, Value: []any{"eu", "us-east"}},
+				},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "and",
+						Filters: []filter.Filter{
+							{Field: "rating", Operator: filter.OpGt, Value: "1"},
+							{Field: "rating", Operator: filter.OpGt, Value: "2"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "negative numbers and timestamps",
+			expr: `delta > -30 AND create_time > "2024-01-01T00:00:00Z"`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "delta", Operator: filter.OpGt, Value: "-30"},
+					{Field: "create_time", Operator: filter.OpGt, Value: "2024-01-01T00:00:00Z"},
+				},
+			},
+		},
+		{
+			name:     "empty",
+			expr:     "  ",
+			expected: filter.FilterGroup{Logic: "and"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := aip.ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(group, tt.expected) {
+				t.Errorf("\nexpected: %#v\ngot:      %#v", tt.expected, group)
+			}
+		})
+	}
+}
+
+func TestParser_Hooks(t *testing.T) {
+	p := aip.Parser{
+		Functions: map[string]aip.Function{
+			"regex": func(args []aip.Arg) (filter.FilterGroup, error) {
+				if len(args) != 2 || args[0].Quoted || !args[1].Quoted {
+					return filter.FilterGroup{}, fmt.Errorf("%w: regex(field, \"pattern\")", filter.ErrInvalidValue)
+				}
+				return filter.FilterGroup{
+					Logic:   "and",
+					Filters: []filter.Filter{{Field: args[0].Text, Operator: filter.OpRegex, Value: args[1].Text}},
+				}, nil
+			},
+		},
+		Search: func(text string) (filter.FilterGroup, error) {
+			return filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "title", Operator: filter.OpContains, Value: text}},
+			}, nil
+		},
+	}
+
+	group, err := p.ParseFilter(`regex(author.name, "^A") "space opera"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := filter.FilterGroup{
+		Logic: "and",
+		Groups: []filter.FilterGroup{
+			{Logic: "and", Filters: []filter.Filter{{Field: "author.name", Operator: filter.OpRegex, Value: "^A"}}},
+			{Logic: "and", Filters: []filter.Filter{{Field: "title", Operator: filter.OpContains, Value: "space opera"}}},
+		},
+	}
+	if !reflect.DeepEqual(group, expected) {
+		t.Errorf("\nexpected: %#v\ngot:      %#v", expected, group)
+	}
+
+	if _, err := p.ParseFilter(`regex(author.name)`); !errors.Is(err, filter.ErrInvalidValue) {
+		t.Errorf("expected the function's error to be wrapped, got %v", err)
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		pos  int
+		err  error
+	}{
+		{name: "bare value without search hook", expr: `prod`, pos: 0, err: filter.ErrUnsupportedOperator},
+		{name: "unknown function", expr: `time.within(create_time, "1h")`, pos: 0, err: filter.ErrUnsupportedOperator},
+		{name: "function on the left of a comparison", expr: `size(tags) > 2`, pos: 0, err: filter.ErrUnsupportedOperator},
+		{name: "missing value", expr: `a =`, pos: 3},
+		{name: "dangling and", expr: `a = 1 AND`, pos: 9},
+		{name: "unclosed parenthesis", expr: `(a = 1`, pos: 6},
+		{name: "unterminated string", expr: `a = "x`, pos: 4},
+		{name: "bang without equals", expr: `a ! 1`, pos: 2},
+		{name: "mixed value list", expr: `a = (1 OR 2 AND 3)`, pos: 12},
+		{name: "broken traversal", expr: `a. b = 1`, pos: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := aip.ParseFilter(tt.expr)
+
+			var syntaxErr *aip.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected SyntaxError, got %v", err)
+			}
+			if syntaxErr.Pos != tt.pos {
+				t.Errorf("expected position %d, got %d (%v)", tt.pos, syntaxErr.Pos, err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestParseOrderBy(t *testing.T) {
+	criteria, err := aip.ParseOrderBy("create_time desc, author.name,rating asc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []sort.Criteria{
+		{Field: "create_time", Order: sort.Desc},
+		{Field: "author.name", Order: sort.Asc},
+		{Field: "rating", Order: sort.Asc},
+	}
+	if !reflect.DeepEqual(criteria, expected) {
+		t.Errorf("expected %v, got %v", expected, criteria)
+	}
+
+	for expr, pos := range map[string]int{
+		"name DESC":     5,
+		"name,":         5,
+		"a.b..c":        0,
+		"name asc desc": 0,
+	} {
+		var syntaxErr *aip.SyntaxError
+		if _, err := aip.ParseOrderBy(expr); !errors.As(err, &syntaxErr) || syntaxErr.Pos != pos {
+			t.Errorf("ParseOrderBy(%q): expected SyntaxError at %d, got %v", expr, pos, err)
+		}
+	}
+}
+
+func TestPageTokens(t *testing.T) {
+	const query = `state = "ACTIVE"|create_time desc`
+
+	limit, offset, err := aip.ParsePage(int32(2), "", query, aip.PageOptions{})
+	if err != nil || limit != 2 || offset != 0 {
+		t.Fatalf("first page: got limit %d, offset %d, err %v", limit, offset, err)
+	}
+
+	// Walk a 5-item collection two at a time
+	var offsets []int
+	token := ""
+	for {
+		limit, offset, err := aip.ParsePage(int32(2), token, query, aip.PageOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		offsets = append(offsets, offset)
+
+		page := pagination.NewPage(make([]int, min(limit, 5-offset)), 5, limit, offset)
+		token = aip.NextPageToken(page, query)
+		if token == "" {
+			break
+		}
+	}
+	if !reflect.DeepEqual(offsets, []int{0, 2, 4}) {
+		t.Errorf("expected offsets [0 2 4], got %v", offsets)
+	}
+
+	page := pagination.NewPage([]int{1, 2}, 5, 2, 0)
+	next := aip.NextPageToken(page, query)
+	if _, _, err := aip.ParsePage(2, next, `state = "DELETED"|create_time desc`, aip.PageOptions{}); !errors.Is(err, aip.ErrInvalidPageToken) {
+		t.Errorf("expected ErrInvalidPageToken for changed parameters, got %v", err)
+	}
+	if _, _, err := aip.ParsePage(2, "not a token", query, aip.PageOptions{}); !errors.Is(err, aip.ErrInvalidPageToken) {
+		t.Errorf("expected ErrInvalidPageToken for garbage, got %v", err)
+	}
+}
+
+func TestParsePage_Size(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageSize int
+		opts     aip.PageOptions
+		expected int
+		err      error
+	}{
+		{name: "default", pageSize: 0, expected: aip.DefaultPageSize},
+		{name: "configured default", pageSize: 0, opts: aip.PageOptions{DefaultSize: 20}, expected: 20},
+		{name: "requested", pageSize: 10, expected: 10},
+		{name: "coerced to max", pageSize: 5000, opts: aip.PageOptions{MaxSize: 100}, expected: 100},
+		{name: "default above max", pageSize: 0, opts: aip.PageOptions{DefaultSize: 200, MaxSize: 100}, expected: 100},
+		{name: "negative", pageSize: -1, err: aip.ErrInvalidPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, _, err := aip.ParsePage(tt.pageSize, "", "", tt.opts)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if limit != tt.expected {
+				t.Errorf("expected limit %d, got %d", tt.expected, limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,474 @@
+package aip
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/internal/parse"
+)
+
+// Arg is a function argument: a quoted string, or unquoted text such as a number or a
+// traversal like "resource.name".
+type Arg struct {
+	Text   string
+	Quoted bool
+}
+
+// Function builds the filter for a function call such as regex(name, "^a").
+type Function func(args []Arg) (filter.FilterGroup, error)
+
+// Parser configures the hooks for the parts of AIP-160 that depend on the service.
+// The zero value is ready to use and rejects functions and bare values.
+type Parser struct {
+	// Functions maps function names, including any dotted prefix (e.g. "time.within"),
+	// to their implementations.
+	Functions map[string]Function
+
+	// Search handles bare values that aren't part of a comparison, e.g. "prod" in
+	// `prod AND region = "eu"`. AIP-160 leaves their meaning to the service.
+	Search func(text string) (filter.FilterGroup, error)
+}
+
+// comparators maps AIP-160 comparators onto dewey operators. ":" (has) is handled separately.
+var comparators = map[string]filter.Operator{
+	"=":  filter.OpEq,
+	"!=": filter.OpNe,
+	"<":  filter.OpLt,
+	"<=": filter.OpLte,
+	">":  filter.OpGt,
+	">=": filter.OpGte,
+}
+
+// ParseFilter parses an AIP-160 filter with the zero Parser.
+func ParseFilter(expr string) (filter.FilterGroup, error) {
+	return Parser{}.ParseFilter(expr)
+}
+
+// ParseFilter parses an AIP-160 filter into a filter group. An empty filter yields an empty group.
+//
+// Terms separated by whitespace or AND are combined with AND, OR binds tighter than AND, and
+// NOT or "-" negates a term:
+//
+//	a = 1 b = 2 OR c = 3     a = 1 AND (b = 2 OR c = 3)
+//	-state = "DELETED"       NOT state = "DELETED"
+//	labels:urgent            labels has "urgent"
+//	owner.email:*            owner.email is set
+//	name = "proj-*"          name starts with "proj-"
+//	region = (eu OR us)      region in (eu, us)
+//
+// Traversal such as owner.email becomes the dotted field "owner.email". Values are passed to the
+// field builders as strings; the builders convert them to the field's type. In = and != a leading
+// or trailing "*" is a wildcard, mapping to startswiths, endswiths or containss.
+func (p Parser) ParseFilter(expr string) (filter.FilterGroup, error) {
+	ps := &parser{Parser: p, lexer: lexer{input: expr}}
+	if err := ps.advance(); err != nil {
+		return filter.FilterGroup{}, err
+	}
+	if ps.tok.kind == tokenEOF {
+		return filter.FilterGroup{Logic: "and"}, nil
+	}
+
+	n, err := ps.expression(0)
+	if err != nil {
+		return filter.FilterGroup{}, err
+	}
+	if ps.tok.kind != tokenEOF {
+		return filter.FilterGroup{}, ps.unexpected()
+	}
+	return n.ToGroup(), nil
+}
+
+type parser struct {
+	Parser
+	lexer lexer
+	tok   token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return syntaxError(p.tok.pos, "unexpected end of input")
+	}
+	return syntaxError(p.tok.pos, "unexpected %s", p.tok.describe())
+}
+
+func (p *parser) expect(kind tokenKind, what string) error {
+	if p.tok.kind != kind {
+		return syntaxError(p.tok.pos, "expected %s, got %s", what, p.tok.describe())
+	}
+	return p.advance()
+}
+
+// expression parses: sequence { AND sequence }
+func (p *parser) expression(depth int) (parse.Node, error) {
+	first, err := p.sequence(depth)
+	if err != nil {
+		return parse.Node{}, err
+	}
+	nodes := []parse.Node{first}
+	for p.tok.is("AND") {
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		n, err := p.sequence(depth)
+		if err != nil {
+			return parse.Node{}, err
+		}
+		nodes = append(nodes, n)
+	}
+	return parse.Combine("and", nodes...), nil
+}
+
+// sequence parses: factor { factor }, an implicit AND
+func (p *parser) sequence(depth int) (parse.Node, error) {
+	first, err := p.factor(depth)
+	if err != nil {
+		return parse.Node{}, err
+	}
+	nodes := []parse.Node{first}
+	for p.startsTerm() {
+		n, err := p.factor(depth)
+		if err != nil {
+			return parse.Node{}, err
+		}
+		nodes = append(nodes, n)
+	}
+	return parse.Combine("and", nodes...), nil
+}
+
+// startsTerm reports whether the current token can begin another term of a sequence.
+func (p *parser) startsTerm() bool {
+	switch p.tok.kind {
+	case tokenLParen, tokenMinus, tokenString:
+		return true
+	case tokenText:
+		return !p.tok.is("AND") && !p.tok.is("OR")
+	default:
+		return false
+	}
+}
+
+// factor parses: term { OR term }
+func (p *parser) factor(depth int) (parse.Node, error) {
+	first, err := p.term(depth)
+	if err != nil {
+		return parse.Node{}, err
+	}
+	nodes := []parse.Node{first}
+	for p.tok.is("OR") {
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		n, err := p.term(depth)
+		if err != nil {
+			return parse.Node{}, err
+		}
+		nodes = append(nodes, n)
+	}
+	return parse.Combine("or", nodes...), nil
+}
+
+// term parses: [ NOT | "-" ] simple
+func (p *parser) term(depth int) (parse.Node, error) {
+	if depth >= parse.MaxDepth {
+		return parse.Node{}, syntaxError(p.tok.pos, "filter nested deeper than %d levels", parse.MaxDepth)
+	}
+
+	negated := false
+	if p.tok.is("NOT") || p.tok.kind == tokenMinus {
+		negated = true
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+	}
+
+	var n parse.Node
+	var err error
+	if p.tok.kind == tokenLParen {
+		if err = p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		if n, err = p.expression(depth + 1); err != nil {
+			return parse.Node{}, err
+		}
+		err = p.expect(tokenRParen, `")"`)
+	} else {
+		n, err = p.restriction()
+	}
+	if err != nil {
+		return parse.Node{}, err
+	}
+
+	if negated {
+		n = n.Negate()
+	}
+	return n, nil
+}
+
+// operand is a parsed member, quoted string or function call.
+type operand struct {
+	pos      int
+	name     string // dotted member or function name
+	quoted   bool   // a single quoted string rather than a member
+	function bool
+	args     []Arg
+}
+
+// restriction parses: comparable [ comparator arg ]
+func (p *parser) restriction() (parse.Node, error) {
+	left, err := p.operand()
+	if err != nil {
+		return parse.Node{}, err
+	}
+
+	if p.tok.kind != tokenComparator {
+		return p.bare(left)
+	}
+	if left.function || left.quoted {
+		return parse.Node{}, &SyntaxError{
+			Pos: left.pos,
+			Msg: "the left side of a comparison must be a field",
+			Err: filter.ErrUnsupportedOperator,
+		}
+	}
+
+	comparator := p.tok
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	if p.tok.kind == tokenLParen {
+		return p.valueList(left.name, comparator)
+	}
+
+	right, err := p.operand()
+	if err != nil {
+		return parse.Node{}, err
+	}
+	if right.function {
+		return parse.Node{}, &SyntaxError{
+			Pos: right.pos,
+			Msg: "functions are not supported as comparison values",
+			Err: filter.ErrUnsupportedOperator,
+		}
+	}
+	return comparison(left.name, comparator.text, right.name), nil
+}
+
+// bare handles a restriction without a comparator: a function call or a search value.
+func (p *parser) bare(c operand) (parse.Node, error) {
+	if c.function {
+		fn, ok := p.Functions[c.name]
+		if !ok {
+			return parse.Node{}, &SyntaxError{
+				Pos: c.pos,
+				Msg: fmt.Sprintf("unknown function %q", c.name),
+				Err: filter.ErrUnsupportedOperator,
+			}
+		}
+		group, err := fn(c.args)
+		if err != nil {
+			return parse.Node{}, &SyntaxError{Pos: c.pos, Msg: fmt.Sprintf("%s: %v", c.name, err), Err: err}
+		}
+		return parse.Node{Group: group}, nil
+	}
+
+	if p.Search == nil {
+		return parse.Node{}, &SyntaxError{
+			Pos: c.pos,
+			Msg: fmt.Sprintf("expected comparator after %q", c.name),
+			Err: filter.ErrUnsupportedOperator,
+		}
+	}
+	group, err := p.Search(c.name)
+	if err != nil {
+		return parse.Node{}, &SyntaxError{Pos: c.pos, Msg: err.Error(), Err: err}
+	}
+	return parse.Node{Group: group}, nil
+}
+
+// comparison builds the filter for field comparator value.
+func comparison(field, comparator, value string) parse.Node {
+	if comparator == ":" {
+		if value == "*" {
+			return parse.FilterNode(field, filter.OpNnull, nil)
+		}
+		return parse.FilterNode(field, filter.OpHas, value)
+	}
+
+	op := comparators[comparator]
+	if op != filter.OpEq && op != filter.OpNe || value == "*" || !strings.ContainsRune(value, '*') {
+		return parse.FilterNode(field, op, value)
+	}
+
+	prefix := strings.HasSuffix(value, "*")
+	suffix := strings.HasPrefix(value, "*")
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(value, "*"), "*")
+
+	var n parse.Node
+	switch {
+	case prefix && suffix:
+		n = parse.FilterNode(field, filter.OpContainsCS, trimmed)
+	case prefix:
+		n = parse.FilterNode(field, filter.OpStartsWithCS, trimmed)
+	case suffix:
+		n = parse.FilterNode(field, filter.OpEndsWithCS, trimmed)
+	default:
+		// a "*" in the middle of a value is literal
+		return parse.FilterNode(field, op, value)
+	}
+	if op == filter.OpNe {
+		n = n.Negate()
+	}
+	return n
+}
+
+// valueList parses the composite right side of a comparison, e.g. region = (eu OR us).
+// Values combined with OR become "in" for = and an OR of comparisons otherwise; values
+// combined with AND become an AND of comparisons.
+func (p *parser) valueList(field string, comparator token) (parse.Node, error) {
+	open := p.tok.pos
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	var values []string
+	logic := ""
+	for {
+		c, err := p.operand()
+		if err != nil {
+			return parse.Node{}, err
+		}
+		if c.function {
+			return parse.Node{}, syntaxError(c.pos, "functions are not supported as comparison values")
+		}
+		values = append(values, c.name)
+
+		if p.tok.kind == tokenRParen {
+			break
+		}
+		next := ""
+		switch {
+		case p.tok.is("OR"):
+			next = "or"
+		case p.tok.is("AND"):
+			next = "and"
+		default:
+			if p.tok.kind == tokenEOF {
+				return parse.Node{}, syntaxError(open, "unclosed parenthesis")
+			}
+			return parse.Node{}, syntaxError(p.tok.pos, "expected OR, AND or \")\", got %s", p.tok.describe())
+		}
+		if logic != "" && logic != next {
+			return parse.Node{}, syntaxError(p.tok.pos, "mixing AND and OR in a value list needs parentheses")
+		}
+		logic = next
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	if logic != "and" && comparator.text == "=" && !hasWildcard(values) {
+		list := make([]any, len(values))
+		for i, v := range values {
+			list[i] = v
+		}
+		return parse.FilterNode(field, filter.OpIn, list), nil
+	}
+
+	if logic == "" {
+		logic = "and"
+	}
+	nodes := make([]parse.Node, len(values))
+	for i, v := range values {
+		nodes[i] = comparison(field, comparator.text, v)
+	}
+	return parse.Combine(logic, nodes...), nil
+}
+
+func hasWildcard(values []string) bool {
+	for _, v := range values {
+		if strings.ContainsRune(v, '*') {
+			return true
+		}
+	}
+	return false
+}
+
+// operand parses a comparable: member | function, where member is value { "." field } and function is
+// name { "." name } "(" [ arg { "," arg } ] ")".
+func (p *parser) operand() (operand, error) {
+	c := operand{pos: p.tok.pos}
+
+	switch p.tok.kind {
+	case tokenString:
+		c.name, c.quoted = p.tok.text, true
+		return c, p.advance()
+	case tokenText:
+		if p.tok.is("AND") || p.tok.is("OR") || p.tok.is("NOT") {
+			return c, p.unexpected()
+		}
+	default:
+		return c, p.unexpected()
+	}
+
+	parts := []string{p.tok.text}
+	if err := p.advance(); err != nil {
+		return c, err
+	}
+	for p.tok.kind == tokenDot && !p.tok.spaceBefore {
+		if err := p.advance(); err != nil {
+			return c, err
+		}
+		if p.tok.spaceBefore || p.tok.kind != tokenText && p.tok.kind != tokenString {
+			return c, syntaxError(p.tok.pos, "expected field after \".\", got %s", p.tok.describe())
+		}
+		parts = append(parts, p.tok.text)
+		if err := p.advance(); err != nil {
+			return c, err
+		}
+	}
+	c.name = strings.Join(parts, ".")
+
+	if p.tok.kind != tokenLParen || p.tok.spaceBefore {
+		return c, nil
+	}
+
+	// function call
+	c.function = true
+	if err := p.advance(); err != nil {
+		return c, err
+	}
+	if p.tok.kind == tokenRParen {
+		return c, p.advance()
+	}
+	for {
+		arg, err := p.operand()
+		if err != nil {
+			return c, err
+		}
+		if arg.function {
+			return c, syntaxError(arg.pos, "nested function calls are not supported")
+		}
+		c.args = append(c.args, Arg{Text: arg.name, Quoted: arg.quoted})
+
+		if p.tok.kind == tokenRParen {
+			return c, p.advance()
+		}
+		if err := p.expect(tokenComma, `"," or ")"`); err != nil {
+			return c, err
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package aip
+
+import (
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF        tokenKind = iota
+	tokenLParen               // (
+	tokenRParen               // )
+	tokenComma                // ,
+	tokenDot                  // .
+	tokenMinus                // - used as negation, e.g. -deleted
+	tokenComparator           // = != < <= > >= :
+	tokenText                 // unquoted text, including the keywords AND, OR and NOT
+	tokenString               // quoted string
+)
+
+type token struct {
+	kind tokenKind
+	pos  int    // byte offset of the token in the input
+	text string // the token as written, or the unescaped contents of a quoted string
+
+	// spaceBefore reports whether whitespace precedes the token. Traversal and function
+	// calls must not contain whitespace ("a.b", "f(x)").
+	spaceBefore bool
+}
+
+// is reports whether the token is the given keyword. Keywords are case-sensitive.
+func (t token) is(keyword string) bool {
+	return t.kind == tokenText && t.text == keyword
+}
+
+func (t token) describe() string {
+	if t.kind == tokenEOF {
+		return "end of input"
+	}
+	return "\"" + t.text + "\""
+}
+
+// textEnd reports whether c ends an unquoted text token.
+func textEnd(c byte) bool {
+	return strings.IndexByte(" \t\n\r().,'\"<>=!:", c) >= 0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func (l *lexer) next() (token, error) {
+	start := l.pos
+	for l.pos < len(l.input) && strings.IndexByte(" \t\n\r", l.input[l.pos]) >= 0 {
+		l.pos++
+	}
+	space := l.pos > start
+	tok, err := l.lex()
+	tok.spaceBefore = space
+	return tok, err
+}
+
+func (l *lexer) lex() (token, error) {
+	if l.pos == len(l.input) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.input[l.pos]
+	single := func(kind tokenKind) (token, error) {
+		l.pos++
+		return token{kind: kind, pos: start, text: string(c)}, nil
+	}
+
+	switch {
+	case c == '(':
+		return single(tokenLParen)
+	case c == ')':
+		return single(tokenRParen)
+	case c == ',':
+		return single(tokenComma)
+	case c == '.':
+		return single(tokenDot)
+	case c == ':' || c == '=':
+		return single(tokenComparator)
+	case c == '<' || c == '>' || c == '!':
+		l.pos++
+		if l.pos < len(l.input) && l.input[l.pos] == '=' {
+			l.pos++
+		} else if c == '!' {
+			return token{}, syntaxError(start, `expected "=" after "!"`)
+		}
+		return token{kind: tokenComparator, pos: start, text: l.input[start:l.pos]}, nil
+	case c == '"' || c == '\'':
+		return l.quoted()
+	case c == '-' && (l.pos+1 == len(l.input) || !isDigit(l.input[l.pos+1])):
+		return single(tokenMinus)
+	}
+
+	// Numbers keep their decimal point: 2.5 is a single value, not the traversal 2 . 5
+	number := isDigit(c) || c == '-'
+	l.pos++
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if c == '.' && number && l.pos+1 < len(l.input) && isDigit(l.input[l.pos+1]) {
+			l.pos++
+			continue
+		}
+		if textEnd(c) {
+			break
+		}
+		l.pos++
+	}
+	return token{kind: tokenText, pos: start, text: l.input[start:l.pos]}, nil
+}
+
+// quoted lexes a single- or double-quoted string. A backslash escapes the next character.
+func (l *lexer) quoted() (token, error) {
+	start := l.pos
+	quote := l.input[l.pos]
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		l.pos++
+		switch c {
+		case quote:
+			return token{kind: tokenString, pos: start, text: b.String()}, nil
+		case '\\':
+			if l.pos == len(l.input) {
+				return token{}, syntaxError(l.pos-1, "unterminated escape sequence")
+			}
+			b.WriteByte(l.input[l.pos])
+			l.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return token{}, syntaxError(start, "unterminated string")
+}
@@ -0,0 +1,274 @@
+package filter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a search term produced by an Analyzer.
+type Token struct {
+	// Text is the term to match
+	Text string
+
+	// Synonyms are alternatives that match in place of Text
+	Synonyms []string
+
+	// Position is the index of the input text the token came from. Filters that add tokens
+	// should copy it from the token they expand.
+	Position int
+}
+
+// Tokenizer splits text into tokens.
+type Tokenizer func(text string) []string
+
+// TokenFilter is one stage of an Analyzer. It may rewrite, drop or add tokens.
+type TokenFilter func(tokens []Token) []Token
+
+// Analyzer turns search text into tokens: Tokenizer splits the text and Filters run in order
+// over the resulting tokens.
+//
+// Example:
+//
+//	analyzer := filter.Analyzer{
+//	    Tokenizer: filter.CJKTokenizer,
+//	    Filters: []filter.TokenFilter{
+//	        filter.Lowercase,
+//	        filter.FoldAccents,
+//	        filter.Stopwords(filter.EnglishStopwords...),
+//	        filter.Synonyms(map[string][]string{"nyc": {"new york"}}),
+//	        filter.MinLength(2),
+//	        filter.MaxTokens(8),
+//	    },
+//	}
+type Analyzer struct {
+	// Tokenizer splits text into tokens (WhitespaceTokenizer if nil)
+	Tokenizer Tokenizer
+
+	// Filters run in order over the tokens
+	Filters []TokenFilter
+}
+
+// Analyze tokenizes text and runs the filters over the tokens.
+func (a Analyzer) Analyze(text string) []Token {
+	return a.analyze([]string{text}, nil)
+}
+
+// analyze tokenizes each text, tagging its tokens with the text's index, and runs the filters
+// over all tokens at once so MaxTokens limits the total. Texts marked as phrases are kept as a
+// single token.
+func (a Analyzer) analyze(texts []string, phrase []bool) []Token {
+	tokenize := a.Tokenizer
+	if tokenize == nil {
+		tokenize = WhitespaceTokenizer
+	}
+
+	var tokens []Token
+	for i, text := range texts {
+		if i < len(phrase) && phrase[i] {
+			tokens = append(tokens, Token{Text: text, Position: i})
+			continue
+		}
+		for _, t := range tokenize(text) {
+			tokens = append(tokens, Token{Text: t, Position: i})
+		}
+	}
+
+	for _, filter := range a.Filters {
+		tokens = filter(tokens)
+	}
+	return tokens
+}
+
+// WhitespaceTokenizer splits text on whitespace, like strings.Fields.
+func WhitespaceTokenizer(text string) []string {
+	return strings.Fields(text)
+}
+
+// CJKTokenizer splits text on whitespace and splits runs of Chinese, Japanese and Korean
+// characters, which aren't separated by spaces, into overlapping bigrams: "東京タワー" becomes
+// "東京", "京タ", "タワ", "ワー". A single CJK character is kept as is. Other text is kept
+// whole, and non-ASCII punctuation such as "。" separates tokens.
+func CJKTokenizer(text string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(text) {
+		var run []rune
+		cjk := false
+		flush := func() {
+			switch {
+			case len(run) == 0:
+			case !cjk || len(run) == 1:
+				tokens = append(tokens, string(run))
+			default:
+				for i := 0; i+1 < len(run); i++ {
+					tokens = append(tokens, string(run[i:i+2]))
+				}
+			}
+			run = run[:0]
+		}
+
+		for _, r := range field {
+			if r > unicode.MaxASCII && unicode.IsPunct(r) {
+				flush()
+				continue
+			}
+			if isCJK(r) != cjk {
+				flush()
+				cjk = !cjk
+			}
+			run = append(run, r)
+		}
+		flush()
+	}
+	return tokens
+}
+
+// isCJK reports whether r belongs to a script written without spaces. The prolonged sound
+// mark and the iteration mark belong to the Common script, so they are listed explicitly.
+func isCJK(r rune) bool {
+	return r == 'ー' || r == '々' || unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Lowercase lower-cases tokens and their synonyms.
+func Lowercase(tokens []Token) []Token {
+	return mapTokens(tokens, strings.ToLower)
+}
+
+// FoldAccents replaces accented Latin letters with their unaccented form ("Zoë Ørsted" becomes
+// "Zoe Orsted", "ß" becomes "ss"), so searches match whether or not users type the accents.
+// Pair it with a column that is folded the same way, e.g. Postgres unaccent().
+func FoldAccents(tokens []Token) []Token {
+	return mapTokens(tokens, FoldAccentsString)
+}
+
+// FoldAccentsString applies the FoldAccents table to a string.
+func FoldAccentsString(s string) string {
+	if !strings.ContainsFunc(s, func(r rune) bool { _, ok := accentFolds[r]; return ok }) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if fold, ok := accentFolds[r]; ok {
+			b.WriteString(fold)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// accentFolds maps accented Latin letters to their base letters.
+var accentFolds = func() map[rune]string {
+	table := map[string]string{
+		"a": "àáâãäåāăą", "A": "ÀÁÂÃÄÅĀĂĄ",
+		"c": "çćĉċč", "C": "ÇĆĈĊČ",
+		"d": "ďđð", "D": "ĎĐÐ",
+		"e": "èéêëēĕėęě", "E": "ÈÉÊËĒĔĖĘĚ",
+		"g": "ĝğġģ", "G": "ĜĞĠĢ",
+		"h": "ĥħ", "H": "ĤĦ",
+		"i": "ìíîïĩīĭįı", "I": "ÌÍÎÏĨĪĬĮİ",
+		"j": "ĵ", "J": "Ĵ",
+		"k": "ķ", "K": "Ķ",
+		"l": "ĺļľŀł", "L": "ĹĻĽĿŁ",
+		"n": "ñńņňŉ", "N": "ÑŃŅŇ",
+		"o": "òóôõöøōŏő", "O": "ÒÓÔÕÖØŌŎŐ",
+		"r": "ŕŗř", "R": "ŔŖŘ",
+		"s": "śŝşšș", "S": "ŚŜŞŠȘ",
+		"t": "ţťŧț", "T": "ŢŤŦȚ",
+		"u": "ùúûüũūŭůűų", "U": "ÙÚÛÜŨŪŬŮŰŲ",
+		"w": "ŵ", "W": "Ŵ",
+		"y": "ýÿŷ", "Y": "ÝŸŶ",
+		"z": "źżž", "Z": "ŹŻŽ",
+		"ss": "ß", "ae": "æ", "AE": "Æ", "oe": "œ", "OE": "Œ", "th": "þ", "TH": "Þ",
+	}
+	folds := make(map[rune]string)
+	for base, accented := range table {
+		for _, r := range accented {
+			folds[r] = base
+		}
+	}
+	return folds
+}()
+
+// MinLength drops tokens shorter than n characters. Tokens with synonyms are kept, so short
+// abbreviations like "ny" can still expand.
+func MinLength(n int) TokenFilter {
+	return func(tokens []Token) []Token {
+		kept := tokens[:0:0]
+		for _, t := range tokens {
+			if utf8.RuneCountInString(t.Text) >= n || len(t.Synonyms) > 0 {
+				kept = append(kept, t)
+			}
+		}
+		return kept
+	}
+}
+
+// MaxTokens keeps the first n tokens, bounding the size of the generated predicate when
+// users paste long text into the search box.
+func MaxTokens(n int) TokenFilter {
+	return func(tokens []Token) []Token {
+		if len(tokens) > n {
+			tokens = tokens[:n]
+		}
+		return tokens
+	}
+}
+
+// Stopwords drops tokens that match one of words exactly. Run it after Lowercase to match
+// regardless of case.
+func Stopwords(words ...string) TokenFilter {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return func(tokens []Token) []Token {
+		kept := tokens[:0:0]
+		for _, t := range tokens {
+			if !set[t.Text] {
+				kept = append(kept, t)
+			}
+		}
+		return kept
+	}
+}
+
+// EnglishStopwords lists common English words that match nearly every row.
+var EnglishStopwords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in", "into", "is",
+	"it", "no", "not", "of", "on", "or", "such", "that", "the", "their", "then", "there",
+	"these", "they", "this", "to", "was", "will", "with",
+}
+
+// Synonyms adds the alternatives listed for a token's text to its Synonyms, e.g.
+// {"nyc": {"new york"}} lets "nyc" also match "new york". Keys are matched against the token
+// text as it is at this stage, so run it after Lowercase to match regardless of case.
+func Synonyms(synonyms map[string][]string) TokenFilter {
+	return func(tokens []Token) []Token {
+		expanded := make([]Token, len(tokens))
+		for i, t := range tokens {
+			if alternatives, ok := synonyms[t.Text]; ok {
+				t.Synonyms = append(t.Synonyms[:len(t.Synonyms):len(t.Synonyms)], alternatives...)
+			}
+			expanded[i] = t
+		}
+		return expanded
+	}
+}
+
+// mapTokens applies fn to the text and synonyms of each token.
+func mapTokens(tokens []Token, fn func(string) string) []Token {
+	mapped := make([]Token, len(tokens))
+	for i, t := range tokens {
+		t.Text = fn(t.Text)
+		if len(t.Synonyms) > 0 {
+			synonyms := make([]string, len(t.Synonyms))
+			for j, s := range t.Synonyms {
+				synonyms[j] = fn(s)
+			}
+			t.Synonyms = synonyms
+		}
+		mapped[i] = t
+	}
+	return mapped
+}
@@ -0,0 +1,143 @@
+package filter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+)
+
+func TestTokenizers(t *testing.T) {
+	tests := []struct {
+		name      string
+		tokenizer filter.Tokenizer
+		text      string
+		expected  []string
+	}{
+		{
+			name:      "whitespace",
+			tokenizer: filter.WhitespaceTokenizer,
+			text:      "  john\tdoe@acme.com ",
+			expected:  []string{"john", "doe@acme.com"},
+		},
+		{
+			name:      "cjk bigrams",
+			tokenizer: filter.CJKTokenizer,
+			text:      "東京タワー",
+			expected:  []string{"東京", "京タ", "タワ", "ワー"},
+		},
+		{
+			name:      "cjk mixed with latin text and punctuation",
+			tokenizer: filter.CJKTokenizer,
+			text:      "iPhone充電器。猫 hello",
+			expected:  []string{"iPhone", "充電", "電器", "猫", "hello"},
+		},
+		{
+			name:      "hangul",
+			tokenizer: filter.CJKTokenizer,
+			text:      "서울시",
+			expected:  []string{"서울", "울시"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := tt.tokenizer(tt.text)
+			if !reflect.DeepEqual(tokens, tt.expected) {
+				t.Errorf("\nexpected: %q\ngot:      %q", tt.expected, tokens)
+			}
+		})
+	}
+}
+
+func TestTokenFilters(t *testing.T) {
+	tokens := func(texts ...string) []filter.Token {
+		result := make([]filter.Token, len(texts))
+		for i, text := range texts {
+			result[i] = filter.Token{Text: text, Position: i}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		filter   filter.TokenFilter
+		tokens   []filter.Token
+		expected []filter.Token
+	}{
+		{
+			name:     "lowercase",
+			filter:   filter.Lowercase,
+			tokens:   []filter.Token{{Text: "ÉCOLE", Synonyms: []string{"NYC"}}},
+			expected: []filter.Token{{Text: "école", Synonyms: []string{"nyc"}}},
+		},
+		{
+			name:     "fold accents",
+			filter:   filter.FoldAccents,
+			tokens:   tokens("Zoë", "Ørsted", "straße", "Łódź", "plain"),
+			expected: tokens("Zoe", "Orsted", "strasse", "Lodz", "plain"),
+		},
+		{
+			name:     "min length",
+			filter:   filter.MinLength(3),
+			tokens:   []filter.Token{{Text: "ab"}, {Text: "abc"}, {Text: "日本"}, {Text: "ny", Synonyms: []string{"new york"}}},
+			expected: []filter.Token{{Text: "abc"}, {Text: "ny", Synonyms: []string{"new york"}}},
+		},
+		{
+			name:     "max tokens",
+			filter:   filter.MaxTokens(2),
+			tokens:   tokens("a", "b", "c"),
+			expected: tokens("a", "b"),
+		},
+		{
+			name:     "stopwords",
+			filter:   filter.Stopwords(filter.EnglishStopwords...),
+			tokens:   tokens("the", "lord", "of", "the", "rings"),
+			expected: []filter.Token{{Text: "lord", Position: 1}, {Text: "rings", Position: 4}},
+		},
+		{
+			name:   "synonyms",
+			filter: filter.Synonyms(map[string][]string{"nyc": {"new york", "manhattan"}}),
+			tokens: tokens("nyc", "pizza"),
+			expected: []filter.Token{
+				{Text: "nyc", Synonyms: []string{"new york", "manhattan"}},
+				{Text: "pizza", Position: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.filter(tt.tokens)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("\nexpected: %+v\ngot:      %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	analyzer := filter.Analyzer{
+		Filters: []filter.TokenFilter{
+			filter.Lowercase,
+			filter.FoldAccents,
+			filter.Stopwords("the"),
+			filter.Synonyms(map[string][]string{"nyc": {"new york"}}),
+			filter.MaxTokens(3),
+		},
+	}
+
+	tokens := analyzer.Analyze("The Café in NYC near the station")
+	expected := []filter.Token{
+		{Text: "cafe"},
+		{Text: "in"},
+		{Text: "nyc", Synonyms: []string{"new york"}},
+	}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("\nexpected: %+v\ngot:      %+v", expected, tokens)
+	}
+
+	if tokens := (filter.Analyzer{}).Analyze("a b"); len(tokens) != 2 {
+		t.Errorf("zero analyzer should split on whitespace, got %+v", tokens)
+	}
+}
@@ -1,7 +1,12 @@
 package filter
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,6 +30,25 @@ type StringPredicates[P any] struct {
 	StartsWith func(string) P
 	EndsWith   func(string) P
 
+	// Negated string operators (optional). When nil, structured filters wrap the positive
+	// function above in PredicateBuilder.Not if one is configured.
+	NotContains   func(string) P
+	NotStartsWith func(string) P
+	NotEndsWith   func(string) P
+
+	// Case-sensitive string operators (optional)
+	ContainsCaseSensitive   func(string) P
+	StartsWithCaseSensitive func(string) P
+	EndsWithCaseSensitive   func(string) P
+
+	// Pattern operators (optional). Patterns are validated before these are called:
+	// Regex/NotRegex receive RE2 syntax, Like/ILike receive a pattern using % and _
+	// wildcards with backslash escapes. ILike matches case-insensitively.
+	Regex    func(string) P
+	NotRegex func(string) P
+	Like     func(string) P
+	ILike    func(string) P
+
 	// Null operators (optional - only needed for nullable fields)
 	IsNil    func() P
 	IsNotNil func() P
@@ -56,33 +80,33 @@ func newStringFilterWithCombinators[P any](
 }
 
 func (b *StringFilterBuilder[P]) Eq(value any) P {
-	return b.predicates.Eq(value.(string))
+	return b.predicates.Eq(legacyString(value))
 }
 
 func (b *StringFilterBuilder[P]) Ne(value any) P {
-	return b.predicates.Ne(value.(string))
+	return b.predicates.Ne(legacyString(value))
 }
 
 func (b *StringFilterBuilder[P]) Gt(value any) P {
-	return b.predicates.Gt(value.(string))
+	return b.predicates.Gt(legacyString(value))
 }
 
 func (b *StringFilterBuilder[P]) Gte(value any) P {
-	return b.predicates.Gte(value.(string))
+	return b.predicates.Gte(legacyString(value))
 }
 
 func (b *StringFilterBuilder[P]) Lt(value any) P {
-	return b.predicates.Lt(value.(string))
+	return b.predicates.Lt(legacyString(value))
 }
 
 func (b *StringFilterBuilder[P]) Lte(value any) P {
-	return b.predicates.Lte(value.(string))
+	return b.predicates.Lte(legacyString(value))
 }
 
 func (b *StringFilterBuilder[P]) In(values []any) P {
 	strs := make([]string, len(values))
 	for i, v := range values {
-		strs[i] = v.(string)
+		strs[i] = legacyString(v)
 	}
 	return b.predicates.In(strs...)
 }
@@ -90,7 +114,7 @@ func (b *StringFilterBuilder[P]) In(values []any) P {
 func (b *StringFilterBuilder[P]) Nin(values []any) P {
 	strs := make([]string, len(values))
 	for i, v := range values {
-		strs[i] = v.(string)
+		strs[i] = legacyString(v)
 	}
 	return b.predicates.Nin(strs...)
 }
@@ -125,6 +149,116 @@ func (b *StringFilterBuilder[P]) IsNotNull() P {
 	return b.combinators.Or(b.predicates.Eq(""), b.predicates.Ne(""))
 }
 
+// Build implements OperatorBuilder, validating the value type and reporting operators
+// whose predicate functions were not configured as unsupported.
+func (b *StringFilterBuilder[P]) Build(op Operator, value any) (P, error) {
+	var zero P
+
+	switch op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpContains, OpStartsWith, OpEndsWith,
+		OpNcontains, OpNstartsWith, OpNendsWith, OpContainsCS, OpStartsWithCS, OpEndsWithCS:
+		fn := b.scalarPredicate(op)
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		s, err := stringValue(value)
+		if err != nil {
+			return zero, err
+		}
+		return fn(s), nil
+	case OpRegex, OpNregex, OpLike, OpIlike:
+		fn, validate := b.patternPredicate(op)
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		s, err := stringValue(value)
+		if err != nil {
+			return zero, err
+		}
+		if err := validate(s); err != nil {
+			return zero, err
+		}
+		return fn(s), nil
+	case OpIn, OpNin:
+		fn := b.predicates.In
+		if op == OpNin {
+			fn = b.predicates.Nin
+		}
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		strs, err := stringValues(value)
+		if err != nil {
+			return zero, err
+		}
+		return fn(strs...), nil
+	case OpNull, OpNnull:
+		if b.nullable && (b.predicates.IsNil == nil || b.predicates.IsNotNil == nil) ||
+			!b.nullable && (b.predicates.Eq == nil || b.predicates.Ne == nil) {
+			return zero, ErrUnsupportedOperator
+		}
+		if op == OpNull {
+			return b.IsNull(), nil
+		}
+		return b.IsNotNull(), nil
+	default:
+		return zero, ErrUnsupportedOperator
+	}
+}
+
+// patternPredicate returns the configured predicate function for a pattern operator
+// together with the validator its pattern must pass.
+func (b *StringFilterBuilder[P]) patternPredicate(op Operator) (func(string) P, func(string) error) {
+	switch op {
+	case OpRegex:
+		return b.predicates.Regex, ValidateRegex
+	case OpNregex:
+		return b.predicates.NotRegex, ValidateRegex
+	case OpIlike:
+		return b.predicates.ILike, ValidateLike
+	default:
+		return b.predicates.Like, ValidateLike
+	}
+}
+
+// scalarPredicate returns the configured predicate function for a single-value operator.
+func (b *StringFilterBuilder[P]) scalarPredicate(op Operator) func(string) P {
+	switch op {
+	case OpEq:
+		return b.predicates.Eq
+	case OpNe:
+		return b.predicates.Ne
+	case OpGt:
+		return b.predicates.Gt
+	case OpGte:
+		return b.predicates.Gte
+	case OpLt:
+		return b.predicates.Lt
+	case OpLte:
+		return b.predicates.Lte
+	case OpContains:
+		return b.predicates.Contains
+	case OpStartsWith:
+		return b.predicates.StartsWith
+	case OpEndsWith:
+		return b.predicates.EndsWith
+	case OpNcontains:
+		return b.predicates.NotContains
+	case OpNstartsWith:
+		return b.predicates.NotStartsWith
+	case OpNendsWith:
+		return b.predicates.NotEndsWith
+	case OpContainsCS:
+		return b.predicates.ContainsCaseSensitive
+	case OpStartsWithCS:
+		return b.predicates.StartsWithCaseSensitive
+	case OpEndsWithCS:
+		return b.predicates.EndsWithCaseSensitive
+	default:
+		return nil
+	}
+}
+
 // BoolPredicates contains all the predicate functions needed for boolean field filtering.
 type BoolPredicates[P any] struct {
 	Eq func(bool) P
@@ -252,6 +386,44 @@ func (b *BoolFilterBuilder[P]) IsNotNull() P {
 	return b.combinators.Or(b.predicates.Eq(true), b.predicates.Eq(false))
 }
 
+// Build implements OperatorBuilder. Besides bool values it accepts "true"/"false" strings
+// (as sent in query parameters). Comparison and string operators are reported as unsupported.
+func (b *BoolFilterBuilder[P]) Build(op Operator, value any) (P, error) {
+	var zero P
+
+	switch op {
+	case OpEq, OpNe:
+		v, err := boolValue(value)
+		if err != nil {
+			return zero, err
+		}
+		if op == OpEq {
+			return b.predicates.Eq(v), nil
+		}
+		return b.predicates.Ne(v), nil
+	case OpIn, OpNin:
+		values := anySlice(value)
+		bools := make([]any, len(values))
+		for i, v := range values {
+			parsed, err := boolValue(v)
+			if err != nil {
+				return zero, err
+			}
+			bools[i] = parsed
+		}
+		if op == OpIn {
+			return b.In(bools), nil
+		}
+		return b.Nin(bools), nil
+	case OpNull:
+		return b.IsNull(), nil
+	case OpNnull:
+		return b.IsNotNull(), nil
+	default:
+		return zero, ErrUnsupportedOperator
+	}
+}
+
 // TimePredicates contains all the predicate functions needed for time/timestamp field filtering.
 type TimePredicates[P any] struct {
 	Eq  func(time.Time) P
@@ -269,11 +441,13 @@ type TimePredicates[P any] struct {
 }
 
 // TimeFilterBuilder implements FieldFilterBuilder for time/timestamp fields.
-// It handles parsing time values from strings and time.Time objects.
+// It handles parsing time values from strings and time.Time objects, including
+// relative expressions such as "now-7d" that are resolved each time a filter is built.
 type TimeFilterBuilder[P any] struct {
 	predicates  TimePredicates[P]
 	combinators Combinators[P]
 	nullable    bool
+	options     timeOptions
 }
 
 // newTimeFilterWithCombinators creates a filter builder for a time field with combinators injected.
@@ -285,17 +459,20 @@ func newTimeFilterWithCombinators[P any](
 	predicates TimePredicates[P],
 	combinators Combinators[P],
 	nullable bool,
+	options timeOptions,
 ) FieldFilterBuilder[P] {
 	return &TimeFilterBuilder[P]{
 		predicates:  predicates,
 		combinators: combinators,
 		nullable:    nullable,
+		options:     options,
 	}
 }
 
 // parseTimeValue parses a time value from various formats (string, time.Time).
-// Supports both ISO date strings (YYYY-MM-DD) and RFC3339 timestamps.
-func parseTimeValue(value any) (time.Time, error) {
+// Supports RFC3339 timestamps, ISO date strings (YYYY-MM-DD, interpreted in loc) and
+// relative expressions (see parseRelativeTime). now is only called for relative expressions.
+func parseTimeValue(value any, now func() time.Time, loc *time.Location) (time.Time, error) {
 	switch v := value.(type) {
 	case time.Time:
 		return v, nil
@@ -305,7 +482,14 @@ func parseTimeValue(value any) (time.Time, error) {
 			return t, nil
 		}
 		// Try ISO date format (YYYY-MM-DD)
-		if t, err := time.Parse("2006-01-02", v); err == nil {
+		if t, err := time.ParseInLocation("2006-01-02", v, loc); err == nil {
+			return t, nil
+		}
+		t, ok, err := parseRelativeTime(strings.TrimSpace(v), now().In(loc))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if ok {
 			return t, nil
 		}
 		return time.Time{}, fmt.Errorf("unable to parse time value: %s", v)
@@ -314,49 +498,71 @@ func parseTimeValue(value any) (time.Time, error) {
 	}
 }
 
+// parser returns a parse function for the values of a single filter. The clock is read at most
+// once, so both bounds of a range such as ["now-1h", "now"] resolve against the same instant.
+func (b *TimeFilterBuilder[P]) parser() func(any) (time.Time, error) {
+	var now time.Time
+	var resolved bool
+	clock := func() time.Time {
+		if !resolved {
+			now, resolved = b.options.now(), true
+		}
+		return now
+	}
+	return func(value any) (time.Time, error) {
+		t, err := parseTimeValue(value, clock, b.options.location)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidValue, err)
+		}
+		return t, nil
+	}
+}
+
 func (b *TimeFilterBuilder[P]) Eq(value any) P {
-	t, _ := parseTimeValue(value)
+	t, _ := b.parser()(value)
 	return b.predicates.Eq(t)
 }
 
 func (b *TimeFilterBuilder[P]) Ne(value any) P {
-	t, _ := parseTimeValue(value)
+	t, _ := b.parser()(value)
 	return b.predicates.Ne(t)
 }
 
 func (b *TimeFilterBuilder[P]) Gt(value any) P {
-	t, _ := parseTimeValue(value)
+	t, _ := b.parser()(value)
 	return b.predicates.Gt(t)
 }
 
 func (b *TimeFilterBuilder[P]) Gte(value any) P {
-	t, _ := parseTimeValue(value)
+	t, _ := b.parser()(value)
 	return b.predicates.Gte(t)
 }
 
 func (b *TimeFilterBuilder[P]) Lt(value any) P {
-	t, _ := parseTimeValue(value)
+	t, _ := b.parser()(value)
 	return b.predicates.Lt(t)
 }
 
 func (b *TimeFilterBuilder[P]) Lte(value any) P {
-	t, _ := parseTimeValue(value)
+	t, _ := b.parser()(value)
 	return b.predicates.Lte(t)
 }
 
 func (b *TimeFilterBuilder[P]) In(values []any) P {
+	parse := b.parser()
 	times := make([]time.Time, len(values))
 	for i, v := range values {
-		t, _ := parseTimeValue(v)
+		t, _ := parse(v)
 		times[i] = t
 	}
 	return b.predicates.In(times...)
 }
 
 func (b *TimeFilterBuilder[P]) Nin(values []any) P {
+	parse := b.parser()
 	times := make([]time.Time, len(values))
 	for i, v := range values {
-		t, _ := parseTimeValue(v)
+		t, _ := parse(v)
 		times[i] = t
 	}
 	return b.predicates.Nin(times...)
@@ -394,3 +600,1633 @@ func (b *TimeFilterBuilder[P]) IsNotNull() P {
 	zeroTime := time.Time{}
 	return b.combinators.Or(b.predicates.Eq(zeroTime), b.predicates.Ne(zeroTime))
 }
+
+// Build implements OperatorBuilder. Unparsable time values are reported as ErrInvalidValue
+// instead of filtering on the zero time, and string operators are reported as unsupported.
+func (b *TimeFilterBuilder[P]) Build(op Operator, value any) (P, error) {
+	var zero P
+	parse := b.parser()
+
+	switch op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte:
+		fn := b.scalarPredicate(op)
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		t, err := parse(value)
+		if err != nil {
+			return zero, err
+		}
+		return fn(t), nil
+	case OpIn, OpNin:
+		fn := b.predicates.In
+		if op == OpNin {
+			fn = b.predicates.Nin
+		}
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		values := anySlice(value)
+		times := make([]time.Time, len(values))
+		for i, v := range values {
+			t, err := parse(v)
+			if err != nil {
+				return zero, err
+			}
+			times[i] = t
+		}
+		return fn(times...), nil
+	case OpBetween, OpNbetween:
+		return buildRange(value, parse, rangePredicates[P, time.Time]{
+			Gt:  b.predicates.Gt,
+			Gte: b.predicates.Gte,
+			Lt:  b.predicates.Lt,
+			Lte: b.predicates.Lte,
+		}, b.combinators, op == OpNbetween)
+	case OpNull, OpNnull:
+		if b.nullable && (b.predicates.IsNil == nil || b.predicates.IsNotNil == nil) ||
+			!b.nullable && (b.predicates.Eq == nil || b.predicates.Ne == nil) {
+			return zero, ErrUnsupportedOperator
+		}
+		if op == OpNull {
+			return b.IsNull(), nil
+		}
+		return b.IsNotNull(), nil
+	default:
+		return zero, ErrUnsupportedOperator
+	}
+}
+
+// scalarPredicate returns the configured predicate function for a single-value operator.
+func (b *TimeFilterBuilder[P]) scalarPredicate(op Operator) func(time.Time) P {
+	switch op {
+	case OpEq:
+		return b.predicates.Eq
+	case OpNe:
+		return b.predicates.Ne
+	case OpGt:
+		return b.predicates.Gt
+	case OpGte:
+		return b.predicates.Gte
+	case OpLt:
+		return b.predicates.Lt
+	case OpLte:
+		return b.predicates.Lte
+	default:
+		return nil
+	}
+}
+
+// anySlice normalizes a filter value for the In/Nin operators.
+// Slices are converted to []any and a single value is wrapped in a one-element slice.
+func anySlice(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		return v
+	case []string:
+		values := make([]any, len(v))
+		for i, s := range v {
+			values[i] = s
+		}
+		return values
+	default:
+		return []any{value}
+	}
+}
+
+// buildOr builds a predicate through ob.Build, returning fallback() if Build rejects the
+// operator or value. The FieldFilterBuilder methods of validating builders use it so that
+// a malformed value gets the same always-true predicate as an unsupported operator, while
+// ApplyStructuredFiltersE still reports both through Build.
+func buildOr[P any](ob OperatorBuilder[P], op Operator, value any, fallback func() P) P {
+	p, err := ob.Build(op, value)
+	if err != nil {
+		return fallback()
+	}
+	return p
+}
+
+// legacyString converts a filter value for the StringFilterBuilder methods, which can't
+// report errors: non-string values are formatted with fmt.Sprint and nil becomes "".
+func legacyString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// stringValue converts a filter value to a string, rejecting other types.
+func stringValue(value any) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", invalidValue(value, "string")
+	}
+	return s, nil
+}
+
+// stringValues converts an In/Nin filter value to a slice of strings.
+func stringValues(value any) ([]string, error) {
+	values := anySlice(value)
+	strs := make([]string, len(values))
+	for i, v := range values {
+		s, err := stringValue(v)
+		if err != nil {
+			return nil, err
+		}
+		strs[i] = s
+	}
+	return strs, nil
+}
+
+// boolValue converts a filter value to a bool, accepting bool values and "true"/"false" strings.
+func boolValue(value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, invalidValue(value, "bool")
+		}
+		return parsed, nil
+	default:
+		return false, invalidValue(value, "bool")
+	}
+}
+
+// Integer is the set of integer column types supported by IntField.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Float is the set of floating-point column types supported by FloatField.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is the set of numeric column types supported by NumberFilterBuilder.
+type Number interface {
+	Integer | Float
+}
+
+// IntPredicates contains all the predicate functions needed for integer field filtering.
+// T is the column's Go type (int, int64, uint32, ...), e.g. Ent's user.AgeEQ is func(int) predicate.User.
+type IntPredicates[P any, T Integer] struct {
+	// Comparison operators
+	Eq  func(T) P
+	Ne  func(T) P
+	Gt  func(T) P
+	Gte func(T) P
+	Lt  func(T) P
+	Lte func(T) P
+
+	// Array operators
+	In  func(...T) P
+	Nin func(...T) P
+
+	// Null operators (optional - only needed for nullable fields)
+	IsNil    func() P
+	IsNotNil func() P
+}
+
+// FloatPredicates contains all the predicate functions needed for floating-point field filtering.
+type FloatPredicates[P any, T Float] struct {
+	// Comparison operators
+	Eq  func(T) P
+	Ne  func(T) P
+	Gt  func(T) P
+	Gte func(T) P
+	Lt  func(T) P
+	Lte func(T) P
+
+	// Array operators
+	In  func(...T) P
+	Nin func(...T) P
+
+	// Null operators (optional - only needed for nullable fields)
+	IsNil    func() P
+	IsNotNil func() P
+}
+
+// numberPredicates is the shared representation of IntPredicates and FloatPredicates.
+type numberPredicates[P any, T Number] struct {
+	Eq       func(T) P
+	Ne       func(T) P
+	Gt       func(T) P
+	Gte      func(T) P
+	Lt       func(T) P
+	Lte      func(T) P
+	In       func(...T) P
+	Nin      func(...T) P
+	IsNil    func() P
+	IsNotNil func() P
+}
+
+// NumberFilterBuilder implements FieldFilterBuilder for integer and floating-point fields.
+// It converts values decoded from JSON (float64, json.Number), query parameters (numeric
+// strings) and native Go numbers to the column type, rejecting values that would overflow it.
+// Build reports rejected values; the FieldFilterBuilder methods match no rows for them in Eq
+// and In, and every row otherwise.
+type NumberFilterBuilder[P any, T Number] struct {
+	predicates  numberPredicates[P, T]
+	combinators Combinators[P]
+	nullable    bool
+	convert     func(any) (T, error)
+}
+
+// newNumberFilterWithCombinators creates a filter builder for a numeric field with combinators injected.
+// This is called by BuildFilterMap after receiving the Combinators.
+func newNumberFilterWithCombinators[P any, T Number](
+	predicates numberPredicates[P, T],
+	combinators Combinators[P],
+	nullable bool,
+	convert func(any) (T, error),
+) FieldFilterBuilder[P] {
+	return &NumberFilterBuilder[P, T]{
+		predicates:  predicates,
+		combinators: combinators,
+		nullable:    nullable,
+		convert:     convert,
+	}
+}
+
+// always returns a tautology (always true) predicate, including NULL rows on nullable fields.
+func (b *NumberFilterBuilder[P, T]) always() P {
+	if b.nullable {
+		return b.combinators.Or(b.predicates.Eq(0), b.predicates.Ne(0), b.predicates.IsNil())
+	}
+	return b.combinators.Or(b.predicates.Eq(0), b.predicates.Ne(0))
+}
+
+// never returns a contradiction (always false) predicate.
+func (b *NumberFilterBuilder[P, T]) never() P {
+	return b.combinators.And(b.predicates.Eq(0), b.predicates.Ne(0))
+}
+
+func (b *NumberFilterBuilder[P, T]) Eq(value any) P {
+	return buildOr[P](b, OpEq, value, b.never)
+}
+
+func (b *NumberFilterBuilder[P, T]) Ne(value any) P {
+	return buildOr[P](b, OpNe, value, b.always)
+}
+
+func (b *NumberFilterBuilder[P, T]) Gt(value any) P {
+	return buildOr[P](b, OpGt, value, b.always)
+}
+
+func (b *NumberFilterBuilder[P, T]) Gte(value any) P {
+	return buildOr[P](b, OpGte, value, b.always)
+}
+
+func (b *NumberFilterBuilder[P, T]) Lt(value any) P {
+	return buildOr[P](b, OpLt, value, b.always)
+}
+
+func (b *NumberFilterBuilder[P, T]) Lte(value any) P {
+	return buildOr[P](b, OpLte, value, b.always)
+}
+
+func (b *NumberFilterBuilder[P, T]) In(values []any) P {
+	return buildOr[P](b, OpIn, values, b.never)
+}
+
+func (b *NumberFilterBuilder[P, T]) Nin(values []any) P {
+	return buildOr[P](b, OpNin, values, b.always)
+}
+
+// String operations aren't applicable to numeric fields - return always-true predicate
+func (b *NumberFilterBuilder[P, T]) Contains(value string) P {
+	return b.always()
+}
+
+func (b *NumberFilterBuilder[P, T]) StartsWith(value string) P {
+	return b.always()
+}
+
+func (b *NumberFilterBuilder[P, T]) EndsWith(value string) P {
+	return b.always()
+}
+
+func (b *NumberFilterBuilder[P, T]) IsNull() P {
+	if b.nullable {
+		return b.predicates.IsNil()
+	}
+	// Non-nullable field - return mathematically impossible predicate
+	return b.never()
+}
+
+func (b *NumberFilterBuilder[P, T]) IsNotNull() P {
+	if b.nullable {
+		return b.predicates.IsNotNil()
+	}
+	// Non-nullable field - return tautology (always true)
+	return b.always()
+}
+
+// Build implements OperatorBuilder. Values that are not numbers, not integral (for integer
+// fields) or out of range for the column type are reported as ErrInvalidValue.
+func (b *NumberFilterBuilder[P, T]) Build(op Operator, value any) (P, error) {
+	var zero P
+
+	switch op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte:
+		fn := b.scalarPredicate(op)
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		n, err := b.convert(value)
+		if err != nil {
+			return zero, err
+		}
+		return fn(n), nil
+	case OpIn, OpNin:
+		fn := b.predicates.In
+		if op == OpNin {
+			fn = b.predicates.Nin
+		}
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		values := anySlice(value)
+		nums := make([]T, len(values))
+		for i, v := range values {
+			n, err := b.convert(v)
+			if err != nil {
+				return zero, err
+			}
+			nums[i] = n
+		}
+		return fn(nums...), nil
+	case OpBetween, OpNbetween:
+		return buildRange(value, b.convert, rangePredicates[P, T]{
+			Gt:  b.predicates.Gt,
+			Gte: b.predicates.Gte,
+			Lt:  b.predicates.Lt,
+			Lte: b.predicates.Lte,
+		}, b.combinators, op == OpNbetween)
+	case OpNull, OpNnull:
+		if b.nullable && (b.predicates.IsNil == nil || b.predicates.IsNotNil == nil) ||
+			!b.nullable && (b.predicates.Eq == nil || b.predicates.Ne == nil) {
+			return zero, ErrUnsupportedOperator
+		}
+		if op == OpNull {
+			return b.IsNull(), nil
+		}
+		return b.IsNotNull(), nil
+	default:
+		return zero, ErrUnsupportedOperator
+	}
+}
+
+// scalarPredicate returns the configured predicate function for a single-value operator.
+func (b *NumberFilterBuilder[P, T]) scalarPredicate(op Operator) func(T) P {
+	switch op {
+	case OpEq:
+		return b.predicates.Eq
+	case OpNe:
+		return b.predicates.Ne
+	case OpGt:
+		return b.predicates.Gt
+	case OpGte:
+		return b.predicates.Gte
+	case OpLt:
+		return b.predicates.Lt
+	case OpLte:
+		return b.predicates.Lte
+	default:
+		return nil
+	}
+}
+
+// intValue converts a filter value to the integer type T.
+// Accepts native integers, integral float64/float32 values (as decoded by encoding/json),
+// json.Number and base-10 numeric strings, and rejects values that overflow T.
+func intValue[T Integer](value any) (T, error) {
+	var (
+		zero T
+		i    int64  // signed representation
+		u    uint64 // representation of values above math.MaxInt64
+		big  bool   // whether the value is held in u
+	)
+
+	switch v := value.(type) {
+	case int:
+		i = int64(v)
+	case int8:
+		i = int64(v)
+	case int16:
+		i = int64(v)
+	case int32:
+		i = int64(v)
+	case int64:
+		i = v
+	case uint:
+		u, big = uint64(v), true
+	case uint8:
+		i = int64(v)
+	case uint16:
+		i = int64(v)
+	case uint32:
+		i = int64(v)
+	case uint64:
+		u, big = v, true
+	case float32:
+		return intValue[T](float64(v))
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return zero, invalidValue(value, "integer")
+		}
+		switch {
+		case v >= -(1<<63) && v < 1<<63:
+			i = int64(v)
+		case v >= 0 && v < 1<<64:
+			u, big = uint64(v), true
+		default:
+			return zero, overflow[T](value)
+		}
+	case json.Number:
+		return intValue[T](string(v))
+	case string:
+		s := strings.TrimSpace(v)
+		if parsed, err := strconv.ParseInt(s, 10, 64); err == nil {
+			i = parsed
+		} else if parsed, err := strconv.ParseUint(s, 10, 64); err == nil {
+			u, big = parsed, true
+		} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+			// Accept integral floats like "18.0" or "1e3"
+			return intValue[T](f)
+		} else {
+			return zero, invalidValue(value, "integer")
+		}
+	default:
+		return zero, invalidValue(value, "integer")
+	}
+
+	if big {
+		if u <= math.MaxInt64 {
+			i, big = int64(u), false
+		} else {
+			t := T(u)
+			if t < 0 || uint64(t) != u {
+				return zero, overflow[T](value)
+			}
+			return t, nil
+		}
+	}
+
+	t := T(i)
+	if int64(t) != i || (t < 0) != (i < 0) {
+		return zero, overflow[T](value)
+	}
+	return t, nil
+}
+
+// floatValue converts a filter value to the floating-point type T.
+// Accepts native numbers, json.Number and numeric strings, and rejects NaN and values that
+// overflow T.
+func floatValue[T Float](value any) (T, error) {
+	var (
+		zero T
+		f    float64
+	)
+
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	case int8:
+		f = float64(v)
+	case int16:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case uint:
+		f = float64(v)
+	case uint8:
+		f = float64(v)
+	case uint16:
+		f = float64(v)
+	case uint32:
+		f = float64(v)
+	case uint64:
+		f = float64(v)
+	case json.Number:
+		return floatValue[T](string(v))
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return zero, invalidValue(value, "number")
+		}
+		f = parsed
+	default:
+		return zero, invalidValue(value, "number")
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return zero, invalidValue(value, "finite number")
+	}
+
+	t := T(f)
+	if math.IsInf(float64(t), 0) {
+		return zero, overflow[T](value)
+	}
+	return t, nil
+}
+
+// overflow builds an error for a value outside the range of the column type T.
+func overflow[T Number](value any) error {
+	var zero T
+	return fmt.Errorf("%w: %v overflows %T", ErrInvalidValue, value, zero)
+}
+
+// UUIDPredicates contains all the predicate functions needed for UUID field filtering.
+// U is the UUID type used by your ORM (e.g. github.com/google/uuid.UUID); dewey itself
+// never imports a UUID package and relies on the parse function passed to UUIDField.
+type UUIDPredicates[P any, U any] struct {
+	// Equality operators
+	Eq func(U) P
+	Ne func(U) P
+
+	// Array operators
+	In  func(...U) P
+	Nin func(...U) P
+
+	// Null operators (optional - only needed for nullable fields)
+	IsNil    func() P
+	IsNotNil func() P
+}
+
+// UUIDFilterBuilder implements FieldFilterBuilder for UUID fields.
+// String values are parsed (and thereby canonicalized) with the configured parse function,
+// so malformed IDs are rejected before they reach the database. Ordering and string
+// operators are not meaningful for UUIDs and are reported as unsupported by Build. The
+// FieldFilterBuilder methods match every row for those operators; a malformed ID matches no
+// rows for Eq and In and every row for Ne and Nin.
+type UUIDFilterBuilder[P any, U any] struct {
+	predicates  UUIDPredicates[P, U]
+	combinators Combinators[P]
+	nullable    bool
+	parse       func(string) (U, error)
+}
+
+// newUUIDFilterWithCombinators creates a filter builder for a UUID field with combinators injected.
+// This is called by BuildFilterMap after receiving the Combinators.
+func newUUIDFilterWithCombinators[P any, U any](
+	predicates UUIDPredicates[P, U],
+	combinators Combinators[P],
+	nullable bool,
+	parse func(string) (U, error),
+) FieldFilterBuilder[P] {
+	return &UUIDFilterBuilder[P, U]{
+		predicates:  predicates,
+		combinators: combinators,
+		nullable:    nullable,
+		parse:       parse,
+	}
+}
+
+// value converts a filter value to the UUID type, accepting parsable strings and U values.
+// Strings are checked first so that string-typed UUID columns are still canonicalized.
+func (b *UUIDFilterBuilder[P, U]) value(value any) (U, error) {
+	switch v := value.(type) {
+	case string:
+		id, err := b.parse(strings.TrimSpace(v))
+		if err != nil {
+			var zero U
+			return zero, fmt.Errorf("%w: invalid UUID %q", ErrInvalidValue, v)
+		}
+		return id, nil
+	case U:
+		return v, nil
+	default:
+		var zero U
+		return zero, invalidValue(value, "UUID string")
+	}
+}
+
+// always returns a tautology (always true) predicate, including NULL rows on nullable fields.
+func (b *UUIDFilterBuilder[P, U]) always() P {
+	var zero U
+	if b.nullable {
+		return b.combinators.Or(b.predicates.Eq(zero), b.predicates.Ne(zero), b.predicates.IsNil())
+	}
+	return b.combinators.Or(b.predicates.Eq(zero), b.predicates.Ne(zero))
+}
+
+// never returns a contradiction (always false) predicate.
+func (b *UUIDFilterBuilder[P, U]) never() P {
+	var zero U
+	return b.combinators.And(b.predicates.Eq(zero), b.predicates.Ne(zero))
+}
+
+func (b *UUIDFilterBuilder[P, U]) Eq(value any) P {
+	return buildOr[P](b, OpEq, value, b.never)
+}
+
+func (b *UUIDFilterBuilder[P, U]) Ne(value any) P {
+	return buildOr[P](b, OpNe, value, b.always)
+}
+
+// Comparison operators aren't meaningful for UUIDs - return always-true predicate
+func (b *UUIDFilterBuilder[P, U]) Gt(value any) P {
+	return b.always()
+}
+
+func (b *UUIDFilterBuilder[P, U]) Gte(value any) P {
+	return b.always()
+}
+
+func (b *UUIDFilterBuilder[P, U]) Lt(value any) P {
+	return b.always()
+}
+
+func (b *UUIDFilterBuilder[P, U]) Lte(value any) P {
+	return b.always()
+}
+
+func (b *UUIDFilterBuilder[P, U]) In(values []any) P {
+	return buildOr[P](b, OpIn, values, b.never)
+}
+
+func (b *UUIDFilterBuilder[P, U]) Nin(values []any) P {
+	return buildOr[P](b, OpNin, values, b.always)
+}
+
+// String operations aren't applicable to UUIDs - return always-true predicate
+func (b *UUIDFilterBuilder[P, U]) Contains(value string) P {
+	return b.always()
+}
+
+func (b *UUIDFilterBuilder[P, U]) StartsWith(value string) P {
+	return b.always()
+}
+
+func (b *UUIDFilterBuilder[P, U]) EndsWith(value string) P {
+	return b.always()
+}
+
+func (b *UUIDFilterBuilder[P, U]) IsNull() P {
+	if b.nullable {
+		return b.predicates.IsNil()
+	}
+	// Non-nullable field - return mathematically impossible predicate
+	return b.never()
+}
+
+func (b *UUIDFilterBuilder[P, U]) IsNotNull() P {
+	if b.nullable {
+		return b.predicates.IsNotNil()
+	}
+	// Non-nullable field - return tautology (always true)
+	return b.always()
+}
+
+// Build implements OperatorBuilder. Supports Eq, Ne, In, Nin, Null and Nnull; unparsable
+// IDs are reported as ErrInvalidValue and all other operators as ErrUnsupportedOperator.
+func (b *UUIDFilterBuilder[P, U]) Build(op Operator, value any) (P, error) {
+	var zero P
+
+	switch op {
+	case OpEq, OpNe:
+		fn := b.predicates.Eq
+		if op == OpNe {
+			fn = b.predicates.Ne
+		}
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		id, err := b.value(value)
+		if err != nil {
+			return zero, err
+		}
+		return fn(id), nil
+	case OpIn, OpNin:
+		fn := b.predicates.In
+		if op == OpNin {
+			fn = b.predicates.Nin
+		}
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		values := anySlice(value)
+		ids := make([]U, len(values))
+		for i, v := range values {
+			id, err := b.value(v)
+			if err != nil {
+				return zero, err
+			}
+			ids[i] = id
+		}
+		return fn(ids...), nil
+	case OpNull, OpNnull:
+		if b.nullable && (b.predicates.IsNil == nil || b.predicates.IsNotNil == nil) ||
+			!b.nullable && (b.predicates.Eq == nil || b.predicates.Ne == nil) {
+			return zero, ErrUnsupportedOperator
+		}
+		if op == OpNull {
+			return b.IsNull(), nil
+		}
+		return b.IsNotNull(), nil
+	default:
+		return zero, ErrUnsupportedOperator
+	}
+}
+
+// ParseUUIDString validates a UUID string and returns it in canonical form
+// (lowercase, hyphenated 8-4-4-4-12). It accepts upper-case digits, the 32-digit form
+// without hyphens, and the "{...}" and "urn:uuid:" wrappings.
+//
+// It can be passed to UUIDField for columns that store UUIDs as strings:
+//
+//	UUIDField("org_id", filter.ParseUUIDString, UUIDPredicates[predicate.User, string]{...})
+func ParseUUIDString(s string) (string, error) {
+	switch {
+	case len(s) == 45 && strings.EqualFold(s[:9], "urn:uuid:"):
+		s = s[9:]
+	case len(s) == 38 && s[0] == '{' && s[37] == '}':
+		s = s[1:37]
+	}
+
+	var hex string
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return "", fmt.Errorf("invalid UUID format: %q", s)
+		}
+		hex = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	case 32:
+		hex = s
+	default:
+		return "", fmt.Errorf("invalid UUID length: %q", s)
+	}
+
+	for i := 0; i < len(hex); i++ {
+		c := hex[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return "", fmt.Errorf("invalid UUID character %q in %q", c, s)
+		}
+	}
+
+	hex = strings.ToLower(hex)
+	return hex[:8] + "-" + hex[8:12] + "-" + hex[12:16] + "-" + hex[16:20] + "-" + hex[20:], nil
+}
+
+// Enum describes the closed set of values accepted by an enumerated field.
+type Enum[E comparable] struct {
+	// Values lists the allowed database values, in the order they should be presented
+	Values []E
+
+	// Aliases optionally maps API names to database values (e.g. "active" -> 1).
+	// Alias targets are always allowed, even if they are not listed in Values.
+	Aliases map[string]E
+}
+
+// EnumPredicates contains all the predicate functions needed for enum field filtering.
+// E is the database representation of the enum (a string type, an int code, ...).
+type EnumPredicates[P any, E comparable] struct {
+	// Equality operators
+	Eq func(E) P
+	Ne func(E) P
+
+	// Array operators
+	In  func(...E) P
+	Nin func(...E) P
+
+	// Null operators (optional - only needed for nullable fields)
+	IsNil    func() P
+	IsNotNil func() P
+}
+
+// ValueLister is implemented by field builders that accept a closed set of values,
+// such as EnumFilterBuilder, so the values can be listed in API schema output.
+//
+// Example:
+//
+//	if lister, ok := userFilterBuilders["status"].(filter.ValueLister); ok {
+//	    schema.Enum = lister.Values()
+//	}
+type ValueLister interface {
+	// Values returns the accepted API values
+	Values() []string
+}
+
+// EnumFilterBuilder implements FieldFilterBuilder for enumerated fields.
+// Values for Eq, Ne, In and Nin are checked against the allowed set (after resolving
+// aliases), so a typo like "actve" is rejected by Build instead of silently matching nothing.
+// The FieldFilterBuilder methods match no rows for such values in Eq and In, and every row
+// for them in Ne and Nin, as they do for the operators enums don't support.
+type EnumFilterBuilder[P any, E comparable] struct {
+	predicates  EnumPredicates[P, E]
+	combinators Combinators[P]
+	nullable    bool
+	allowed     map[E]bool
+	lookup      map[string]E // alias names and string forms of allowed values
+	names       []string     // API values in presentation order
+}
+
+// newEnumFilterWithCombinators creates a filter builder for an enum field with combinators injected.
+// This is called by BuildFilterMap after receiving the Combinators.
+func newEnumFilterWithCombinators[P any, E comparable](
+	enum Enum[E],
+	predicates EnumPredicates[P, E],
+	combinators Combinators[P],
+	nullable bool,
+) FieldFilterBuilder[P] {
+	b := &EnumFilterBuilder[P, E]{
+		predicates:  predicates,
+		combinators: combinators,
+		nullable:    nullable,
+		allowed:     make(map[E]bool, len(enum.Values)+len(enum.Aliases)),
+		lookup:      make(map[string]E, len(enum.Values)+len(enum.Aliases)),
+	}
+
+	// Aliases are listed in sorted order so the schema output is stable
+	aliasNames := make([]string, 0, len(enum.Aliases))
+	for name := range enum.Aliases {
+		aliasNames = append(aliasNames, name)
+	}
+	sort.Strings(aliasNames)
+
+	aliased := make(map[E]bool, len(enum.Aliases))
+	for _, name := range aliasNames {
+		value := enum.Aliases[name]
+		b.allowed[value] = true
+		b.lookup[name] = value
+		b.names = append(b.names, name)
+		aliased[value] = true
+	}
+
+	// Raw values stay accepted for aliased entries, but only unaliased ones are listed
+	for _, value := range enum.Values {
+		b.allowed[value] = true
+		name := fmt.Sprint(value)
+		if _, exists := b.lookup[name]; exists {
+			continue
+		}
+		b.lookup[name] = value
+		if !aliased[value] {
+			b.names = append(b.names, name)
+		}
+	}
+
+	return b
+}
+
+// Values implements ValueLister. It returns the alias names followed by the string form
+// of every allowed value that has no alias.
+func (b *EnumFilterBuilder[P, E]) Values() []string {
+	return append([]string(nil), b.names...)
+}
+
+// value resolves a filter value to an allowed enum value.
+// Aliases take precedence, then native E values, then the string form of the value
+// (so "1" and the JSON number 1 both match an int enum value of 1).
+func (b *EnumFilterBuilder[P, E]) value(value any) (E, error) {
+	if s, ok := value.(string); ok {
+		if v, ok := b.lookup[s]; ok {
+			return v, nil
+		}
+	}
+	if v, ok := value.(E); ok && b.allowed[v] {
+		return v, nil
+	}
+	if v, ok := b.lookup[fmt.Sprint(value)]; ok && value != nil {
+		return v, nil
+	}
+
+	var zero E
+	return zero, fmt.Errorf("%w: %v is not one of [%s]", ErrInvalidValue, value, strings.Join(b.names, ", "))
+}
+
+// always returns a tautology (always true) predicate, including NULL rows on nullable fields.
+func (b *EnumFilterBuilder[P, E]) always() P {
+	var zero E
+	if b.nullable {
+		return b.combinators.Or(b.predicates.Eq(zero), b.predicates.Ne(zero), b.predicates.IsNil())
+	}
+	return b.combinators.Or(b.predicates.Eq(zero), b.predicates.Ne(zero))
+}
+
+// never returns a contradiction (always false) predicate.
+func (b *EnumFilterBuilder[P, E]) never() P {
+	var zero E
+	return b.combinators.And(b.predicates.Eq(zero), b.predicates.Ne(zero))
+}
+
+func (b *EnumFilterBuilder[P, E]) Eq(value any) P {
+	return buildOr[P](b, OpEq, value, b.never)
+}
+
+func (b *EnumFilterBuilder[P, E]) Ne(value any) P {
+	return buildOr[P](b, OpNe, value, b.always)
+}
+
+// Comparison operators aren't meaningful for enums - return always-true predicate
+func (b *EnumFilterBuilder[P, E]) Gt(value any) P {
+	return b.always()
+}
+
+func (b *EnumFilterBuilder[P, E]) Gte(value any) P {
+	return b.always()
+}
+
+func (b *EnumFilterBuilder[P, E]) Lt(value any) P {
+	return b.always()
+}
+
+func (b *EnumFilterBuilder[P, E]) Lte(value any) P {
+	return b.always()
+}
+
+func (b *EnumFilterBuilder[P, E]) In(values []any) P {
+	return buildOr[P](b, OpIn, values, b.never)
+}
+
+func (b *EnumFilterBuilder[P, E]) Nin(values []any) P {
+	return buildOr[P](b, OpNin, values, b.always)
+}
+
+// String operations aren't applicable to enums - return always-true predicate
+func (b *EnumFilterBuilder[P, E]) Contains(value string) P {
+	return b.always()
+}
+
+func (b *EnumFilterBuilder[P, E]) StartsWith(value string) P {
+	return b.always()
+}
+
+func (b *EnumFilterBuilder[P, E]) EndsWith(value string) P {
+	return b.always()
+}
+
+func (b *EnumFilterBuilder[P, E]) IsNull() P {
+	if b.nullable {
+		return b.predicates.IsNil()
+	}
+	// Non-nullable field - return mathematically impossible predicate
+	return b.never()
+}
+
+func (b *EnumFilterBuilder[P, E]) IsNotNull() P {
+	if b.nullable {
+		return b.predicates.IsNotNil()
+	}
+	// Non-nullable field - return tautology (always true)
+	return b.always()
+}
+
+// Build implements OperatorBuilder. Supports Eq, Ne, In, Nin, Null and Nnull; values outside
+// the allowed set are reported as ErrInvalidValue and all other operators as ErrUnsupportedOperator.
+func (b *EnumFilterBuilder[P, E]) Build(op Operator, value any) (P, error) {
+	var zero P
+
+	switch op {
+	case OpEq, OpNe:
+		fn := b.predicates.Eq
+		if op == OpNe {
+			fn = b.predicates.Ne
+		}
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		v, err := b.value(value)
+		if err != nil {
+			return zero, err
+		}
+		return fn(v), nil
+	case OpIn, OpNin:
+		fn := b.predicates.In
+		if op == OpNin {
+			fn = b.predicates.Nin
+		}
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		values := anySlice(value)
+		enums := make([]E, len(values))
+		for i, raw := range values {
+			v, err := b.value(raw)
+			if err != nil {
+				return zero, err
+			}
+			enums[i] = v
+		}
+		return fn(enums...), nil
+	case OpNull, OpNnull:
+		if b.nullable && (b.predicates.IsNil == nil || b.predicates.IsNotNil == nil) ||
+			!b.nullable && (b.predicates.Eq == nil || b.predicates.Ne == nil) {
+			return zero, ErrUnsupportedOperator
+		}
+		if op == OpNull {
+			return b.IsNull(), nil
+		}
+		return b.IsNotNull(), nil
+	default:
+		return zero, ErrUnsupportedOperator
+	}
+}
+
+// JSONPredicates contains the predicate functions needed for filtering inside JSON/JSONB
+// document columns. Each function receives the path below the column (e.g. ["plan", "tier"]
+// for "metadata.plan.tier") and the filter value as decoded from the request (string,
+// float64, bool, nil, []any or map[string]any). Every function is optional; operators
+// without a function are reported as unsupported.
+//
+// Example for Postgres with Ent:
+//
+//	filter.JSONPredicates[predicate.User]{
+//	    Eq: func(path []string, v any) predicate.User {
+//	        return predicate.User(sqljson.ValueEQ(user.FieldMetadata, v, sqljson.Path(path...)))
+//	    },
+//	    HasKey: func(path []string) predicate.User {
+//	        return predicate.User(sqljson.HasKey(user.FieldMetadata, sqljson.Path(path...)))
+//	    },
+//	}
+type JSONPredicates[P any] struct {
+	// Equality operators
+	Eq func(path []string, value any) P
+	Ne func(path []string, value any) P
+
+	// Comparison operators
+	Gt  func(path []string, value any) P
+	Gte func(path []string, value any) P
+	Lt  func(path []string, value any) P
+	Lte func(path []string, value any) P
+
+	// Array operators
+	In  func(path []string, values ...any) P
+	Nin func(path []string, values ...any) P
+
+	// Existence (OpHasKey) and containment (OpContains, e.g. Postgres @>)
+	HasKey   func(path []string) P
+	Contains func(path []string, value any) P
+
+	// Null operators (JSON null or missing key)
+	IsNull    func(path []string) P
+	IsNotNull func(path []string) P
+}
+
+// JSONFilterBuilder implements FieldFilterBuilder and PathBuilder for JSON/JSONB columns.
+// Filters address values inside the document with a dotted or bracketed path in
+// Filter.Field ("metadata.plan.tier", "metadata[plan][tier]"), which must match one of
+// the builder's allow-listed patterns before it reaches the predicate functions.
+//
+// ApplyStructuredFilters builds every filter on a JSON field through Build, so containment
+// filters receive the decoded document value and rejected filters are skipped. The
+// FieldFilterBuilder methods are only used by direct callers: they filter the whole document
+// and match every row for operators without a configured function, which needs IsNull and
+// IsNotNull.
+type JSONFilterBuilder[P any] struct {
+	predicates  JSONPredicates[P]
+	combinators Combinators[P]
+	patterns    [][]string
+}
+
+// newJSONFilter creates a filter builder for a JSON field.
+func newJSONFilter[P any](predicates JSONPredicates[P], combinators Combinators[P], patterns []string) FieldFilterBuilder[P] {
+	b := &JSONFilterBuilder[P]{predicates: predicates, combinators: combinators}
+	for _, pattern := range patterns {
+		b.patterns = append(b.patterns, strings.Split(pattern, "."))
+	}
+	return b
+}
+
+// allowed reports whether a path matches one of the allow-listed patterns.
+// A "*" pattern segment matches any single segment and a trailing "**" matches
+// one or more remaining segments. The empty path (the whole document) is always allowed.
+func (b *JSONFilterBuilder[P]) allowed(path []string) bool {
+	if len(path) == 0 {
+		return true
+	}
+
+	for _, pattern := range b.patterns {
+		if matchPathPattern(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathPattern matches path segments against a split allow-list pattern.
+func matchPathPattern(pattern, path []string) bool {
+	for i, segment := range pattern {
+		if segment == "**" && i == len(pattern)-1 {
+			return len(path) > i
+		}
+		if i >= len(path) || segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(path)
+}
+
+// validating marks JSONFilterBuilder as a validatingBuilder.
+func (b *JSONFilterBuilder[P]) validating() {}
+
+// always returns a tautology (always true) predicate: the document is null or not. Builders
+// without both null predicates return the zero predicate; ApplyStructuredFilters never uses it.
+func (b *JSONFilterBuilder[P]) always() P {
+	if b.predicates.IsNull == nil || b.predicates.IsNotNull == nil {
+		var zero P
+		return zero
+	}
+	return b.combinators.Or(b.predicates.IsNull(nil), b.predicates.IsNotNull(nil))
+}
+
+func (b *JSONFilterBuilder[P]) Eq(value any) P {
+	return buildOr[P](b, OpEq, value, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) Ne(value any) P {
+	return buildOr[P](b, OpNe, value, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) Gt(value any) P {
+	return buildOr[P](b, OpGt, value, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) Gte(value any) P {
+	return buildOr[P](b, OpGte, value, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) Lt(value any) P {
+	return buildOr[P](b, OpLt, value, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) Lte(value any) P {
+	return buildOr[P](b, OpLte, value, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) In(values []any) P {
+	return buildOr[P](b, OpIn, values, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) Nin(values []any) P {
+	return buildOr[P](b, OpNin, values, b.always)
+}
+
+// Contains checks document containment rather than substring matching
+func (b *JSONFilterBuilder[P]) Contains(value string) P {
+	return buildOr[P](b, OpContains, value, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) StartsWith(value string) P {
+	return buildOr[P](b, OpStartsWith, value, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) EndsWith(value string) P {
+	return buildOr[P](b, OpEndsWith, value, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) IsNull() P {
+	return buildOr[P](b, OpNull, nil, b.always)
+}
+
+func (b *JSONFilterBuilder[P]) IsNotNull() P {
+	return buildOr[P](b, OpNnull, nil, b.always)
+}
+
+// Build implements OperatorBuilder for filters on the whole document.
+func (b *JSONFilterBuilder[P]) Build(op Operator, value any) (P, error) {
+	return b.BuildPath(nil, op, value)
+}
+
+// BuildPath implements PathBuilder. Paths outside the allow-list are reported as
+// ErrUnknownField and operators without a configured function as ErrUnsupportedOperator.
+func (b *JSONFilterBuilder[P]) BuildPath(path []string, op Operator, value any) (P, error) {
+	var zero P
+
+	if !b.allowed(path) {
+		return zero, fmt.Errorf("%w: path %q is not filterable", ErrUnknownField, strings.Join(path, "."))
+	}
+
+	var fn func([]string, any) P
+	switch op {
+	case OpEq:
+		fn = b.predicates.Eq
+	case OpNe:
+		fn = b.predicates.Ne
+	case OpGt:
+		fn = b.predicates.Gt
+	case OpGte:
+		fn = b.predicates.Gte
+	case OpLt:
+		fn = b.predicates.Lt
+	case OpLte:
+		fn = b.predicates.Lte
+	case OpContains:
+		fn = b.predicates.Contains
+	case OpIn, OpNin:
+		list := b.predicates.In
+		if op == OpNin {
+			list = b.predicates.Nin
+		}
+		if list == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		return list(path, anySlice(value)...), nil
+	case OpHasKey, OpNull, OpNnull:
+		check := b.predicates.HasKey
+		if op == OpNull {
+			check = b.predicates.IsNull
+		} else if op == OpNnull {
+			check = b.predicates.IsNotNull
+		}
+		if check == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		return check(path), nil
+	}
+
+	if fn == nil {
+		return zero, ErrUnsupportedOperator
+	}
+	if n, ok := value.(json.Number); ok {
+		// Hand numbers to the predicate functions as float64, like encoding/json does by default
+		f, err := n.Float64()
+		if err != nil {
+			return zero, invalidValue(value, "number")
+		}
+		value = f
+	}
+	return fn(path, value), nil
+}
+
+// ArrayPredicates contains the predicate functions needed for array/tag column filtering
+// (e.g. Postgres text[] columns). T is the element type. Every function is optional;
+// operators without a function are reported as unsupported. The always-true predicate the
+// FieldFilterBuilder methods return for scalar operators is built from IsNil and IsNotNil, or LenGte.
+type ArrayPredicates[P any, T any] struct {
+	// Element operators. Has falls back to HasAny (or HasAll) with a single element.
+	Has    func(T) P    // e.g. value = ANY(tags)
+	HasAll func(...T) P // e.g. tags @> ARRAY[...]
+	HasAny func(...T) P // e.g. tags && ARRAY[...]
+
+	// Length operators
+	LenEq  func(int) P
+	LenGt  func(int) P
+	LenGte func(int) P
+	LenLt  func(int) P
+	LenLte func(int) P
+
+	// Null operators (optional - only needed for nullable columns)
+	IsNil    func() P
+	IsNotNil func() P
+}
+
+// ArrayFilterBuilder implements FieldFilterBuilder for array/tag columns.
+// It only supports the array operators (has, hasall, hasany, len*) and null checks;
+// Build reports the scalar operators, including In and Contains, as unsupported.
+//
+// ApplyStructuredFilters builds every filter on an array field through Build and skips the
+// rejected ones. The FieldFilterBuilder methods are only used by direct callers and match
+// every row for the scalar operators.
+type ArrayFilterBuilder[P any, T any] struct {
+	predicates  ArrayPredicates[P, T]
+	combinators Combinators[P]
+}
+
+// newArrayFilter creates a filter builder for an array field.
+func newArrayFilter[P any, T any](predicates ArrayPredicates[P, T], combinators Combinators[P]) FieldFilterBuilder[P] {
+	return &ArrayFilterBuilder[P, T]{predicates: predicates, combinators: combinators}
+}
+
+// validating marks ArrayFilterBuilder as a validatingBuilder.
+func (b *ArrayFilterBuilder[P, T]) validating() {}
+
+// always returns a tautology (always true) predicate: the column is null or not, or for
+// columns without null predicates, which are assumed not null, has a length of at least 0.
+// Builders with neither return the zero predicate; ApplyStructuredFilters never uses it.
+func (b *ArrayFilterBuilder[P, T]) always() P {
+	switch {
+	case b.predicates.IsNil != nil && b.predicates.IsNotNil != nil:
+		return b.combinators.Or(b.predicates.IsNil(), b.predicates.IsNotNil())
+	case b.predicates.LenGte != nil:
+		return b.predicates.LenGte(0)
+	default:
+		var zero P
+		return zero
+	}
+}
+
+// Scalar operators aren't meaningful for array columns - return always-true predicate
+func (b *ArrayFilterBuilder[P, T]) Eq(value any) P {
+	return buildOr[P](b, OpEq, value, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) Ne(value any) P {
+	return buildOr[P](b, OpNe, value, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) Gt(value any) P {
+	return buildOr[P](b, OpGt, value, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) Gte(value any) P {
+	return buildOr[P](b, OpGte, value, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) Lt(value any) P {
+	return buildOr[P](b, OpLt, value, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) Lte(value any) P {
+	return buildOr[P](b, OpLte, value, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) In(values []any) P {
+	return buildOr[P](b, OpIn, values, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) Nin(values []any) P {
+	return buildOr[P](b, OpNin, values, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) Contains(value string) P {
+	return buildOr[P](b, OpContains, value, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) StartsWith(value string) P {
+	return buildOr[P](b, OpStartsWith, value, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) EndsWith(value string) P {
+	return buildOr[P](b, OpEndsWith, value, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) IsNull() P {
+	return buildOr[P](b, OpNull, nil, b.always)
+}
+
+func (b *ArrayFilterBuilder[P, T]) IsNotNull() P {
+	return buildOr[P](b, OpNnull, nil, b.always)
+}
+
+// Build implements OperatorBuilder. Element values are converted to T (numbers from JSON
+// and query strings are accepted for numeric element types), and length values must be
+// non-negative integers.
+func (b *ArrayFilterBuilder[P, T]) Build(op Operator, value any) (P, error) {
+	var zero P
+
+	switch op {
+	case OpHas:
+		elem, err := elementValue[T](value)
+		if err != nil {
+			return zero, err
+		}
+		switch {
+		case b.predicates.Has != nil:
+			return b.predicates.Has(elem), nil
+		case b.predicates.HasAny != nil:
+			return b.predicates.HasAny(elem), nil
+		case b.predicates.HasAll != nil:
+			return b.predicates.HasAll(elem), nil
+		}
+		return zero, ErrUnsupportedOperator
+	case OpHasAll, OpHasAny:
+		fn := b.predicates.HasAll
+		if op == OpHasAny {
+			fn = b.predicates.HasAny
+		}
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		values := anySlice(value)
+		elems := make([]T, len(values))
+		for i, v := range values {
+			elem, err := elementValue[T](v)
+			if err != nil {
+				return zero, err
+			}
+			elems[i] = elem
+		}
+		return fn(elems...), nil
+	case OpLen, OpLenGt, OpLenGte, OpLenLt, OpLenLte:
+		fn := b.lengthPredicate(op)
+		if fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		n, err := intValue[int](value)
+		if err != nil {
+			return zero, err
+		}
+		if n < 0 {
+			return zero, invalidValue(value, "non-negative length")
+		}
+		return fn(n), nil
+	case OpNull:
+		if b.predicates.IsNil == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		return b.predicates.IsNil(), nil
+	case OpNnull:
+		if b.predicates.IsNotNil == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		return b.predicates.IsNotNil(), nil
+	default:
+		return zero, ErrUnsupportedOperator
+	}
+}
+
+// lengthPredicate returns the configured predicate function for a length operator.
+func (b *ArrayFilterBuilder[P, T]) lengthPredicate(op Operator) func(int) P {
+	switch op {
+	case OpLen:
+		return b.predicates.LenEq
+	case OpLenGt:
+		return b.predicates.LenGt
+	case OpLenGte:
+		return b.predicates.LenGte
+	case OpLenLt:
+		return b.predicates.LenLt
+	case OpLenLte:
+		return b.predicates.LenLte
+	default:
+		return nil
+	}
+}
+
+// elementValue converts a filter value to the element type T. Strings, bools and the
+// numeric types go through the same conversions as the scalar builders; any other element
+// type must be passed as a T value.
+func elementValue[T any](value any) (T, error) {
+	var zero T
+
+	var (
+		converted any
+		err       error
+	)
+	switch any(zero).(type) {
+	case string:
+		converted, err = stringValue(value)
+	case bool:
+		converted, err = boolValue(value)
+	case int:
+		converted, err = intValue[int](value)
+	case int8:
+		converted, err = intValue[int8](value)
+	case int16:
+		converted, err = intValue[int16](value)
+	case int32:
+		converted, err = intValue[int32](value)
+	case int64:
+		converted, err = intValue[int64](value)
+	case uint:
+		converted, err = intValue[uint](value)
+	case uint8:
+		converted, err = intValue[uint8](value)
+	case uint16:
+		converted, err = intValue[uint16](value)
+	case uint32:
+		converted, err = intValue[uint32](value)
+	case uint64:
+		converted, err = intValue[uint64](value)
+	case float32:
+		converted, err = floatValue[float32](value)
+	case float64:
+		converted, err = floatValue[float64](value)
+	default:
+		converted = value
+	}
+	if err != nil {
+		return zero, err
+	}
+
+	elem, ok := converted.(T)
+	if !ok {
+		return zero, invalidValue(value, fmt.Sprintf("%T", zero))
+	}
+	return elem, nil
+}
+
+// rangeValue converts a between/nbetween filter value to a Range.
+// Accepts Range values, two-element slices and {"from", "to", "exclusive_from", "exclusive_to"} objects.
+func rangeValue(value any) (Range, error) {
+	var r Range
+
+	switch v := value.(type) {
+	case Range:
+		r = v
+	case *Range:
+		if v == nil {
+			return r, invalidValue(value, "range")
+		}
+		r = *v
+	case []any, []string:
+		values := anySlice(v)
+		if len(values) != 2 {
+			return r, fmt.Errorf("%w: expected [from, to], got %d values", ErrInvalidValue, len(values))
+		}
+		r = Range{From: values[0], To: values[1]}
+	case map[string]any:
+		for key := range v {
+			switch key {
+			case "from", "to", "exclusive_from", "exclusive_to":
+			default:
+				return r, fmt.Errorf("%w: unknown range key %q", ErrInvalidValue, key)
+			}
+		}
+		r = Range{From: v["from"], To: v["to"]}
+		var err error
+		if raw, ok := v["exclusive_from"]; ok {
+			if r.ExclusiveFrom, err = boolValue(raw); err != nil {
+				return r, err
+			}
+		}
+		if raw, ok := v["exclusive_to"]; ok {
+			if r.ExclusiveTo, err = boolValue(raw); err != nil {
+				return r, err
+			}
+		}
+	default:
+		return r, invalidValue(value, "[from, to] range")
+	}
+
+	if r.From == nil && r.To == nil {
+		return r, fmt.Errorf("%w: range needs at least one bound", ErrInvalidValue)
+	}
+	return r, nil
+}
+
+// rangePredicates holds the comparison functions a range is built from.
+type rangePredicates[P any, T any] struct {
+	Gt  func(T) P
+	Gte func(T) P
+	Lt  func(T) P
+	Lte func(T) P
+}
+
+// buildRange builds a between (negate=false) or nbetween (negate=true) predicate from the
+// field's comparison functions, so no dedicated ORM function is needed:
+//
+//	between:  from <= x AND x <= to   (Gt/Lt for exclusive bounds)
+//	nbetween: x < from OR x > to      (Lte/Gte for exclusive bounds)
+func buildRange[P any, T any](
+	value any,
+	convert func(any) (T, error),
+	predicates rangePredicates[P, T],
+	combinators Combinators[P],
+	negate bool,
+) (P, error) {
+	var zero P
+
+	r, err := rangeValue(value)
+	if err != nil {
+		return zero, err
+	}
+
+	// Pick the comparison for each bound
+	lower, upper := predicates.Gte, predicates.Lte
+	if r.ExclusiveFrom {
+		lower = predicates.Gt
+	}
+	if r.ExclusiveTo {
+		upper = predicates.Lt
+	}
+	if negate {
+		lower, upper = predicates.Lt, predicates.Gt
+		if r.ExclusiveFrom {
+			lower = predicates.Lte
+		}
+		if r.ExclusiveTo {
+			upper = predicates.Gte
+		}
+	}
+
+	bounds := make([]P, 0, 2)
+	for _, bound := range []struct {
+		value any
+		fn    func(T) P
+	}{{r.From, lower}, {r.To, upper}} {
+		if bound.value == nil {
+			continue
+		}
+		if bound.fn == nil {
+			return zero, ErrUnsupportedOperator
+		}
+		v, err := convert(bound.value)
+		if err != nil {
+			return zero, err
+		}
+		bounds = append(bounds, bound.fn(v))
+	}
+
+	if len(bounds) == 1 {
+		return bounds[0], nil
+	}
+	if negate {
+		return combinators.Or(bounds...), nil
+	}
+	return combinators.And(bounds...), nil
+}
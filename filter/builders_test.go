@@ -0,0 +1,1570 @@
+package filter_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tone-labs/dewey/filter"
+)
+
+var mockCombinators = filter.Combinators[MockPredicate]{
+	Or:  mockOr,
+	And: mockAnd,
+}
+
+func mockOp(field, op string) func(string) MockPredicate {
+	return func(value string) MockPredicate {
+		return MockPredicate(fmt.Sprintf("%s %s %s", field, op, value))
+	}
+}
+
+func mockList(field, op string) func(...string) MockPredicate {
+	return func(values ...string) MockPredicate {
+		return MockPredicate(fmt.Sprintf("%s %s (%s)", field, op, strings.Join(values, ",")))
+	}
+}
+
+func mockStringPredicates(field string) filter.StringPredicates[MockPredicate] {
+	return filter.StringPredicates[MockPredicate]{
+		Eq:         mockOp(field, "="),
+		Ne:         mockOp(field, "!="),
+		Gt:         mockOp(field, ">"),
+		Gte:        mockOp(field, ">="),
+		Lt:         mockOp(field, "<"),
+		Lte:        mockOp(field, "<="),
+		In:         mockList(field, "IN"),
+		Nin:        mockList(field, "NOT IN"),
+		Contains:   mockOp(field, "CONTAINS"),
+		StartsWith: mockOp(field, "STARTSWITH"),
+		EndsWith:   mockOp(field, "ENDSWITH"),
+		IsNil:      func() MockPredicate { return MockPredicate(field + " IS NULL") },
+		IsNotNil:   func() MockPredicate { return MockPredicate(field + " IS NOT NULL") },
+	}
+}
+
+func mockTimeOp(field, op string) func(time.Time) MockPredicate {
+	return func(t time.Time) MockPredicate {
+		return MockPredicate(fmt.Sprintf("%s %s %s", field, op, t.Format(time.RFC3339)))
+	}
+}
+
+func mockTimePredicates(field string) filter.TimePredicates[MockPredicate] {
+	return filter.TimePredicates[MockPredicate]{
+		Eq:  mockTimeOp(field, "="),
+		Ne:  mockTimeOp(field, "!="),
+		Gt:  mockTimeOp(field, ">"),
+		Gte: mockTimeOp(field, ">="),
+		Lt:  mockTimeOp(field, "<"),
+		Lte: mockTimeOp(field, "<="),
+	}
+}
+
+func mockBoolPredicates(field string) filter.BoolPredicates[MockPredicate] {
+	return filter.BoolPredicates[MockPredicate]{
+		Eq: func(v bool) MockPredicate { return MockPredicate(fmt.Sprintf("%s = %t", field, v)) },
+		Ne: func(v bool) MockPredicate { return MockPredicate(fmt.Sprintf("%s != %t", field, v)) },
+	}
+}
+
+// buildOne builds a single filter through the OperatorBuilder of a registered field
+func buildOne(t *testing.T, builders map[string]filter.FieldFilterBuilder[MockPredicate], f filter.Filter) (MockPredicate, error) {
+	t.Helper()
+	ob, ok := builders[f.Field].(filter.OperatorBuilder[MockPredicate])
+	if !ok {
+		t.Fatalf("builder for %q does not implement OperatorBuilder", f.Field)
+	}
+	return ob.Build(f.Operator, f.Value)
+}
+
+// applyLenient applies a single filter through ApplyStructuredFilters and returns the
+// predicates passed to Where. PredicateBuilder.Not is not configured.
+func applyLenient(builders map[string]filter.FieldFilterBuilder[MockPredicate], f filter.Filter) []string {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+	predicates := filter.PredicateBuilder[MockPredicate]{IDIn: mockIDIn, Or: mockOr, And: mockAnd}
+	group := filter.FilterGroup{Filters: []filter.Filter{f}}
+	return filter.ApplyStructuredFilters(&MockQuery{}, cfg, group, builders, predicates).predicates
+}
+
+func TestBuilders_Build(t *testing.T) {
+	partial := mockStringPredicates("code")
+	partial.Gt = nil
+	partial.Contains = nil
+
+	builders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.StringField("email", mockStringPredicates("email")),
+		filter.NullableStringField("name", mockStringPredicates("name")),
+		filter.StringField("code", partial),
+		filter.BoolField("active", mockBoolPredicates("active")),
+		filter.TimeField("created_at", mockTimePredicates("created_at")),
+	)
+
+	tests := []struct {
+		name     string
+		filter   filter.Filter
+		expected string
+		err      error
+	}{
+		{
+			name:     "string eq",
+			filter:   filter.Filter{Field: "email", Operator: filter.OpEq, Value: "a@b.com"},
+			expected: "email = a@b.com",
+		},
+		{
+			name:     "string in",
+			filter:   filter.Filter{Field: "email", Operator: filter.OpIn, Value: []any{"a", "b"}},
+			expected: "email IN (a,b)",
+		},
+		{
+			name:     "string in with single value",
+			filter:   filter.Filter{Field: "email", Operator: filter.OpIn, Value: "a"},
+			expected: "email IN (a)",
+		},
+		{
+			name:   "string eq with number",
+			filter: filter.Filter{Field: "email", Operator: filter.OpEq, Value: float64(42)},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "string in with number",
+			filter: filter.Filter{Field: "email", Operator: filter.OpIn, Value: []any{"a", float64(1)}},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "unconfigured predicate is unsupported",
+			filter: filter.Filter{Field: "code", Operator: filter.OpContains, Value: "x"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "unknown operator",
+			filter: filter.Filter{Field: "email", Operator: "like", Value: "x"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:     "nullable string is null",
+			filter:   filter.Filter{Field: "name", Operator: filter.OpNull},
+			expected: "name IS NULL",
+		},
+		{
+			name:     "non-nullable string is null",
+			filter:   filter.Filter{Field: "email", Operator: filter.OpNull},
+			expected: "(email =  AND email != )",
+		},
+		{
+			name:     "bool eq",
+			filter:   filter.Filter{Field: "active", Operator: filter.OpEq, Value: true},
+			expected: "active = true",
+		},
+		{
+			name:     "bool eq from query string",
+			filter:   filter.Filter{Field: "active", Operator: filter.OpEq, Value: "false"},
+			expected: "active = false",
+		},
+		{
+			name:   "bool eq with invalid string",
+			filter: filter.Filter{Field: "active", Operator: filter.OpEq, Value: "maybe"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "bool comparison is unsupported",
+			filter: filter.Filter{Field: "active", Operator: filter.OpGt, Value: true},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:     "time gte",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "2024-01-01"},
+			expected: "created_at >= 2024-01-01T00:00:00Z",
+		},
+		{
+			name:   "time with unparsable value",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "last tuesday"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "time string operator is unsupported",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpContains, Value: "2024"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "time in without predicate is unsupported",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpIn, Value: []any{"2024-01-01"}},
+			err:    filter.ErrUnsupportedOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildOne(t, builders, tt.filter)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func mockNumberOp[T filter.Number](field, op string) func(T) MockPredicate {
+	return func(v T) MockPredicate {
+		return MockPredicate(fmt.Sprintf("%s %s %v", field, op, v))
+	}
+}
+
+func mockNumberList[T filter.Number](field, op string) func(...T) MockPredicate {
+	return func(values ...T) MockPredicate {
+		return MockPredicate(fmt.Sprintf("%s %s %v", field, op, values))
+	}
+}
+
+func TestNumberBuilders_Build(t *testing.T) {
+	builders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.IntField("age", filter.IntPredicates[MockPredicate, int]{
+			Eq:  mockNumberOp[int]("age", "="),
+			Gte: mockNumberOp[int]("age", ">="),
+			In:  mockNumberList[int]("age", "IN"),
+		}),
+		filter.IntField("qty", filter.IntPredicates[MockPredicate, uint8]{
+			Eq: mockNumberOp[uint8]("qty", "="),
+		}),
+		filter.NullableIntField("rank", filter.IntPredicates[MockPredicate, int64]{
+			Eq:       mockNumberOp[int64]("rank", "="),
+			IsNil:    func() MockPredicate { return "rank IS NULL" },
+			IsNotNil: func() MockPredicate { return "rank IS NOT NULL" },
+		}),
+		filter.FloatField("price", filter.FloatPredicates[MockPredicate, float64]{
+			Lt:  mockNumberOp[float64]("price", "<"),
+			Nin: mockNumberList[float64]("price", "NOT IN"),
+		}),
+		filter.FloatField("ratio", filter.FloatPredicates[MockPredicate, float32]{
+			Eq: mockNumberOp[float32]("ratio", "="),
+		}),
+	)
+
+	tests := []struct {
+		name     string
+		filter   filter.Filter
+		expected string
+		err      error
+	}{
+		{
+			name:     "int from json float64",
+			filter:   filter.Filter{Field: "age", Operator: filter.OpGte, Value: float64(18)},
+			expected: "age >= 18",
+		},
+		{
+			name:     "int from json.Number",
+			filter:   filter.Filter{Field: "age", Operator: filter.OpEq, Value: json.Number("42")},
+			expected: "age = 42",
+		},
+		{
+			name:     "int from query string",
+			filter:   filter.Filter{Field: "age", Operator: filter.OpEq, Value: " 21 "},
+			expected: "age = 21",
+		},
+		{
+			name:     "int from native int64",
+			filter:   filter.Filter{Field: "age", Operator: filter.OpEq, Value: int64(7)},
+			expected: "age = 7",
+		},
+		{
+			name:     "int in with mixed representations",
+			filter:   filter.Filter{Field: "age", Operator: filter.OpIn, Value: []any{float64(1), "2", 3}},
+			expected: "age IN [1 2 3]",
+		},
+		{
+			name:   "int rejects fractions",
+			filter: filter.Filter{Field: "age", Operator: filter.OpEq, Value: 18.5},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "int rejects non-numeric strings",
+			filter: filter.Filter{Field: "age", Operator: filter.OpEq, Value: "eighteen"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "int rejects bools",
+			filter: filter.Filter{Field: "age", Operator: filter.OpEq, Value: true},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:     "uint8 in range",
+			filter:   filter.Filter{Field: "qty", Operator: filter.OpEq, Value: float64(255)},
+			expected: "qty = 255",
+		},
+		{
+			name:   "uint8 overflow",
+			filter: filter.Filter{Field: "qty", Operator: filter.OpEq, Value: float64(256)},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "uint8 negative",
+			filter: filter.Filter{Field: "qty", Operator: filter.OpEq, Value: -1},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "int64 overflow from string",
+			filter: filter.Filter{Field: "rank", Operator: filter.OpEq, Value: "18446744073709551615"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:     "nullable int is null",
+			filter:   filter.Filter{Field: "rank", Operator: filter.OpNull},
+			expected: "rank IS NULL",
+		},
+		{
+			name:   "unconfigured predicate is unsupported",
+			filter: filter.Filter{Field: "age", Operator: filter.OpLt, Value: 1},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "string operators are unsupported",
+			filter: filter.Filter{Field: "age", Operator: filter.OpContains, Value: "1"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:     "float from string",
+			filter:   filter.Filter{Field: "price", Operator: filter.OpLt, Value: "9.99"},
+			expected: "price < 9.99",
+		},
+		{
+			name:     "float nin",
+			filter:   filter.Filter{Field: "price", Operator: filter.OpNin, Value: []any{1, json.Number("2.5")}},
+			expected: "price NOT IN [1 2.5]",
+		},
+		{
+			name:   "float rejects NaN",
+			filter: filter.Filter{Field: "price", Operator: filter.OpLt, Value: "NaN"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "float32 overflow",
+			filter: filter.Filter{Field: "ratio", Operator: filter.OpEq, Value: 1e300},
+			err:    filter.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildOne(t, builders, tt.filter)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("methods match no rows for rejected eq and in values", func(t *testing.T) {
+		age := filter.BuildFilterMap(mockCombinators, filter.IntField("age", filter.IntPredicates[MockPredicate, int]{
+			Eq: mockNumberOp[int]("age", "="),
+			Ne: mockNumberOp[int]("age", "!="),
+		}))["age"]
+
+		expected := MockPredicate("(age = 0 AND age != 0)")
+		for _, got := range []MockPredicate{age.Eq("eighteen"), age.In([]any{1, "x"})} {
+			if got != expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", expected, got)
+			}
+		}
+		if got := age.Eq(float64(18)); got != "age = 18" {
+			t.Errorf("expected age = 18, got %s", got)
+		}
+	})
+
+	t.Run("methods match every row for other rejected input", func(t *testing.T) {
+		fields := filter.BuildFilterMap(mockCombinators,
+			filter.IntField("age", filter.IntPredicates[MockPredicate, int]{
+				Eq: mockNumberOp[int]("age", "="),
+				Ne: mockNumberOp[int]("age", "!="),
+			}),
+			filter.NullableIntField("score", filter.IntPredicates[MockPredicate, int]{
+				Eq:    mockNumberOp[int]("score", "="),
+				Ne:    mockNumberOp[int]("score", "!="),
+				IsNil: func() MockPredicate { return "score IS NULL" },
+			}),
+		)
+
+		tests := []struct {
+			got      MockPredicate
+			expected MockPredicate
+		}{
+			{got: fields["age"].Ne("eighteen"), expected: "(age = 0 OR age != 0)"},
+			{got: fields["age"].Gt(1), expected: "(age = 0 OR age != 0)"},
+			{got: fields["score"].Contains("1"), expected: "(score = 0 OR score != 0 OR score IS NULL)"},
+		}
+		for _, tt := range tests {
+			if tt.got != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", tt.expected, tt.got)
+			}
+		}
+	})
+}
+
+// testUUID stands in for a third-party UUID type such as uuid.UUID
+type testUUID [16]byte
+
+func parseTestUUID(s string) (testUUID, error) {
+	var id testUUID
+	canonical, err := filter.ParseUUIDString(s)
+	if err != nil {
+		return id, err
+	}
+	copy(id[:], strings.ReplaceAll(canonical, "-", ""))
+	return id, nil
+}
+
+func TestUUIDBuilder_Build(t *testing.T) {
+	const canonical = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	builders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.UUIDField("id", filter.ParseUUIDString, filter.UUIDPredicates[MockPredicate, string]{
+			Eq: mockOp("id", "="),
+			Ne: mockOp("id", "!="),
+			In: mockList("id", "IN"),
+		}),
+		filter.NullableUUIDField("org_id", parseTestUUID, filter.UUIDPredicates[MockPredicate, testUUID]{
+			Eq:       func(id testUUID) MockPredicate { return MockPredicate("org_id = " + string(id[:8])) },
+			IsNil:    func() MockPredicate { return "org_id IS NULL" },
+			IsNotNil: func() MockPredicate { return "org_id IS NOT NULL" },
+		}),
+	)
+
+	tests := []struct {
+		name     string
+		filter   filter.Filter
+		expected string
+		err      error
+	}{
+		{
+			name:     "canonical string",
+			filter:   filter.Filter{Field: "id", Operator: filter.OpEq, Value: canonical},
+			expected: "id = " + canonical,
+		},
+		{
+			name:     "upper case is canonicalized",
+			filter:   filter.Filter{Field: "id", Operator: filter.OpNe, Value: "6BA7B810-9DAD-11D1-80B4-00C04FD430C8"},
+			expected: "id != " + canonical,
+		},
+		{
+			name:     "braced, urn and unhyphenated forms",
+			filter:   filter.Filter{Field: "id", Operator: filter.OpIn, Value: []any{"{" + canonical + "}", "urn:uuid:" + canonical, "6ba7b8109dad11d180b400c04fd430c8"}},
+			expected: "id IN (" + canonical + "," + canonical + "," + canonical + ")",
+		},
+		{
+			name:   "malformed id",
+			filter: filter.Filter{Field: "id", Operator: filter.OpEq, Value: "6ba7b810-9dad-11d1-80b4"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "non-hex id",
+			filter: filter.Filter{Field: "id", Operator: filter.OpEq, Value: "zba7b810-9dad-11d1-80b4-00c04fd430c8"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "non-string id",
+			filter: filter.Filter{Field: "id", Operator: filter.OpEq, Value: float64(1)},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "ordering is unsupported",
+			filter: filter.Filter{Field: "id", Operator: filter.OpGt, Value: canonical},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "string operators are unsupported",
+			filter: filter.Filter{Field: "id", Operator: filter.OpContains, Value: "6ba7"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "unconfigured nin is unsupported",
+			filter: filter.Filter{Field: "id", Operator: filter.OpNin, Value: []any{canonical}},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:     "custom uuid type from string",
+			filter:   filter.Filter{Field: "org_id", Operator: filter.OpEq, Value: canonical},
+			expected: "org_id = 6ba7b810",
+		},
+		{
+			name:     "custom uuid type passed natively",
+			filter:   filter.Filter{Field: "org_id", Operator: filter.OpEq, Value: testUUID{'a', 'b', 'c', 'd', 'e', 'f', '0', '1'}},
+			expected: "org_id = abcdef01",
+		},
+		{
+			name:     "nullable uuid is null",
+			filter:   filter.Filter{Field: "org_id", Operator: filter.OpNull},
+			expected: "org_id IS NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildOne(t, builders, tt.filter)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("methods match no rows for rejected eq and in values", func(t *testing.T) {
+		id := builders["id"]
+
+		expected := MockPredicate("(id =  AND id != )")
+		for _, got := range []MockPredicate{id.Eq("not-a-uuid"), id.In([]any{canonical, 1})} {
+			if got != expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", expected, got)
+			}
+		}
+	})
+
+	t.Run("methods match every row for rejected ne values and operators", func(t *testing.T) {
+		id := builders["id"]
+
+		expected := MockPredicate("(id =  OR id != )")
+		for _, got := range []MockPredicate{id.Ne("not-a-uuid"), id.Gt(canonical), id.Contains("6ba7")} {
+			if got != expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", expected, got)
+			}
+		}
+	})
+}
+
+func TestEnumBuilder_Build(t *testing.T) {
+	builders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.EnumField("role",
+			filter.Enum[string]{Values: []string{"admin", "member", "guest"}},
+			filter.EnumPredicates[MockPredicate, string]{
+				Eq:  mockOp("role", "="),
+				Ne:  mockOp("role", "!="),
+				In:  mockList("role", "IN"),
+				Nin: mockList("role", "NOT IN"),
+			},
+		),
+		filter.NullableEnumField("status",
+			filter.Enum[int]{
+				Values:  []int{1, 2, 3, 9},
+				Aliases: map[string]int{"active": 1, "suspended": 2, "deleted": 3},
+			},
+			filter.EnumPredicates[MockPredicate, int]{
+				Eq:       mockNumberOp[int]("status", "="),
+				In:       mockNumberList[int]("status", "IN"),
+				IsNil:    func() MockPredicate { return "status IS NULL" },
+				IsNotNil: func() MockPredicate { return "status IS NOT NULL" },
+			},
+		),
+	)
+
+	tests := []struct {
+		name     string
+		filter   filter.Filter
+		expected string
+		err      error
+	}{
+		{
+			name:     "string enum eq",
+			filter:   filter.Filter{Field: "role", Operator: filter.OpEq, Value: "admin"},
+			expected: "role = admin",
+		},
+		{
+			name:     "string enum nin",
+			filter:   filter.Filter{Field: "role", Operator: filter.OpNin, Value: []any{"guest", "member"}},
+			expected: "role NOT IN (guest,member)",
+		},
+		{
+			name:   "typo is rejected",
+			filter: filter.Filter{Field: "role", Operator: filter.OpEq, Value: "admn"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "typo is rejected for negated operators",
+			filter: filter.Filter{Field: "role", Operator: filter.OpNe, Value: "Admin"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "typo in list is rejected",
+			filter: filter.Filter{Field: "role", Operator: filter.OpIn, Value: []any{"admin", "owner"}},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:     "alias resolves to database value",
+			filter:   filter.Filter{Field: "status", Operator: filter.OpEq, Value: "active"},
+			expected: "status = 1",
+		},
+		{
+			name:     "aliases and raw values in list",
+			filter:   filter.Filter{Field: "status", Operator: filter.OpIn, Value: []any{"suspended", float64(9), "3"}},
+			expected: "status IN [2 9 3]",
+		},
+		{
+			name:     "native database value",
+			filter:   filter.Filter{Field: "status", Operator: filter.OpEq, Value: 2},
+			expected: "status = 2",
+		},
+		{
+			name:   "unknown database value",
+			filter: filter.Filter{Field: "status", Operator: filter.OpEq, Value: float64(4)},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:     "nullable enum is null",
+			filter:   filter.Filter{Field: "status", Operator: filter.OpNull},
+			expected: "status IS NULL",
+		},
+		{
+			name:   "comparison is unsupported",
+			filter: filter.Filter{Field: "status", Operator: filter.OpGt, Value: "active"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "unconfigured ne is unsupported",
+			filter: filter.Filter{Field: "status", Operator: filter.OpNe, Value: "active"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildOne(t, builders, tt.filter)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("methods match no rows for rejected eq and in values", func(t *testing.T) {
+		role := builders["role"]
+
+		expected := MockPredicate("(role =  AND role != )")
+		for _, got := range []MockPredicate{role.Eq("admn"), role.In([]any{"admin", "owner"})} {
+			if got != expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", expected, got)
+			}
+		}
+	})
+
+	t.Run("methods match every row for rejected ne values and operators", func(t *testing.T) {
+		role := builders["role"]
+
+		expected := MockPredicate("(role =  OR role != )")
+		for _, got := range []MockPredicate{role.Ne("admn"), role.Nin([]any{"owner"}), role.Lt("admin"), role.StartsWith("ad")} {
+			if got != expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", expected, got)
+			}
+		}
+	})
+
+	t.Run("values are listed for schema output", func(t *testing.T) {
+		tests := map[string][]string{
+			"role":   {"admin", "member", "guest"},
+			"status": {"active", "deleted", "suspended", "9"},
+		}
+		for field, expected := range tests {
+			lister, ok := builders[field].(filter.ValueLister)
+			if !ok {
+				t.Fatalf("builder for %q does not implement ValueLister", field)
+			}
+			if got := lister.Values(); strings.Join(got, ",") != strings.Join(expected, ",") {
+				t.Errorf("%s: expected %v, got %v", field, expected, got)
+			}
+		}
+	})
+}
+
+func mockJSONOp(op string) func([]string, any) MockPredicate {
+	return func(path []string, value any) MockPredicate {
+		return MockPredicate(fmt.Sprintf("metadata#>'{%s}' %s %v", strings.Join(path, ","), op, value))
+	}
+}
+
+func TestJSONBuilder(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+
+	builder := filter.PredicateBuilder[MockPredicate]{
+		IDIn: mockIDIn,
+		Or:   mockOr,
+		And:  mockAnd,
+	}
+
+	fieldBuilders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.StringField("email", mockStringPredicates("email")),
+		filter.JSONField("metadata", filter.JSONPredicates[MockPredicate]{
+			Eq:       mockJSONOp("="),
+			Gte:      mockJSONOp(">="),
+			Contains: mockJSONOp("@>"),
+			In: func(path []string, values ...any) MockPredicate {
+				return MockPredicate(fmt.Sprintf("metadata#>'{%s}' IN %v", strings.Join(path, ","), values))
+			},
+			HasKey: func(path []string) MockPredicate {
+				return MockPredicate(fmt.Sprintf("metadata ? '{%s}'", strings.Join(path, ",")))
+			},
+		}, "plan.tier", "limits.*", "settings.**"),
+	)
+
+	tests := []struct {
+		name     string
+		filter   filter.Filter
+		expected string
+		err      error
+	}{
+		{
+			name:     "dotted path",
+			filter:   filter.Filter{Field: "metadata.plan.tier", Operator: filter.OpEq, Value: "pro"},
+			expected: "metadata#>'{plan,tier}' = pro",
+		},
+		{
+			name:     "bracketed path",
+			filter:   filter.Filter{Field: `metadata["plan"][tier]`, Operator: filter.OpEq, Value: "pro"},
+			expected: "metadata#>'{plan,tier}' = pro",
+		},
+		{
+			name:     "wildcard segment with typed value",
+			filter:   filter.Filter{Field: "metadata.limits.seats", Operator: filter.OpGte, Value: json.Number("10")},
+			expected: "metadata#>'{limits,seats}' >= 10",
+		},
+		{
+			name:     "recursive wildcard",
+			filter:   filter.Filter{Field: "metadata.settings.ui.theme", Operator: filter.OpIn, Value: []any{"dark", "light"}},
+			expected: "metadata#>'{settings,ui,theme}' IN [dark light]",
+		},
+		{
+			name:     "has key",
+			filter:   filter.Filter{Field: "metadata.settings.beta", Operator: filter.OpHasKey},
+			expected: "metadata ? '{settings,beta}'",
+		},
+		{
+			name:     "containment on the whole document",
+			filter:   filter.Filter{Field: "metadata", Operator: filter.OpContains, Value: map[string]any{"plan": "pro"}},
+			expected: "metadata#>'{}' @> map[plan:pro]",
+		},
+		{
+			name:   "unconfigured operator on the whole document",
+			filter: filter.Filter{Field: "metadata", Operator: filter.OpGt, Value: 1},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "path outside the allow-list",
+			filter: filter.Filter{Field: "metadata.billing.card", Operator: filter.OpEq, Value: "x"},
+			err:    filter.ErrUnknownField,
+		},
+		{
+			name:   "wildcard matches a single segment only",
+			filter: filter.Filter{Field: "metadata.limits.seats.max", Operator: filter.OpEq, Value: 1},
+			err:    filter.ErrUnknownField,
+		},
+		{
+			name:   "recursive wildcard needs a segment",
+			filter: filter.Filter{Field: "metadata.settings", Operator: filter.OpEq, Value: 1},
+			err:    filter.ErrUnknownField,
+		},
+		{
+			name:   "unconfigured operator",
+			filter: filter.Filter{Field: "metadata.plan.tier", Operator: filter.OpLt, Value: "pro"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "paths on scalar fields are unknown",
+			filter: filter.Filter{Field: "email.domain", Operator: filter.OpEq, Value: "x"},
+			err:    filter.ErrUnknownField,
+		},
+		{
+			name:   "has key on scalar fields is unsupported",
+			filter: filter.Filter{Field: "email", Operator: filter.OpHasKey},
+			err:    filter.ErrUnsupportedOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := filter.FilterGroup{Filters: []filter.Filter{tt.filter}}
+			result, err := filter.ApplyStructuredFiltersE(&MockQuery{}, cfg, group, fieldBuilders, builder)
+			lenient := filter.ApplyStructuredFilters(&MockQuery{}, cfg, group, fieldBuilders, builder)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				if len(lenient.predicates) != 0 {
+					t.Errorf("expected lenient mode to skip the filter, got %v", lenient.predicates)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.predicates) != 1 || result.predicates[0] != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %v", tt.expected, result.predicates)
+			}
+			if len(lenient.predicates) != 1 || lenient.predicates[0] != tt.expected {
+				t.Errorf("\nexpected lenient: %s\ngot:              %v", tt.expected, lenient.predicates)
+			}
+		})
+	}
+
+	t.Run("methods match every row for unconfigured operators", func(t *testing.T) {
+		metadata := filter.BuildFilterMap(mockCombinators, filter.JSONField("metadata", filter.JSONPredicates[MockPredicate]{
+			Eq:        mockJSONOp("="),
+			IsNull:    func(path []string) MockPredicate { return "metadata IS NULL" },
+			IsNotNull: func(path []string) MockPredicate { return "metadata IS NOT NULL" },
+		}))["metadata"]
+
+		if got := metadata.Eq("x"); got != "metadata#>'{}' = x" {
+			t.Errorf("unexpected predicate %s", got)
+		}
+		expected := MockPredicate("(metadata IS NULL OR metadata IS NOT NULL)")
+		for _, got := range []MockPredicate{metadata.Gt(1), metadata.StartsWith("x")} {
+			if got != expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", expected, got)
+			}
+		}
+	})
+}
+
+func TestArrayBuilder_Build(t *testing.T) {
+	builders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.StringField("email", mockStringPredicates("email")),
+		filter.ArrayField("tags", filter.ArrayPredicates[MockPredicate, string]{
+			Has:    mockOp("tags", "HAS"),
+			HasAll: mockList("tags", "@>"),
+			HasAny: mockList("tags", "&&"),
+			LenGte: mockNumberOp[int]("cardinality(tags)", ">="),
+			IsNil:  func() MockPredicate { return "tags IS NULL" },
+		}),
+		filter.ArrayField("scores", filter.ArrayPredicates[MockPredicate, int]{
+			HasAny: mockNumberList[int]("scores", "&&"),
+			LenEq:  mockNumberOp[int]("cardinality(scores)", "="),
+		}),
+	)
+
+	tests := []struct {
+		name     string
+		filter   filter.Filter
+		expected string
+		err      error
+	}{
+		{
+			name:     "has",
+			filter:   filter.Filter{Field: "tags", Operator: filter.OpHas, Value: "go"},
+			expected: "tags HAS go",
+		},
+		{
+			name:     "has all",
+			filter:   filter.Filter{Field: "tags", Operator: filter.OpHasAll, Value: []any{"go", "sql"}},
+			expected: "tags @> (go,sql)",
+		},
+		{
+			name:     "has any",
+			filter:   filter.Filter{Field: "tags", Operator: filter.OpHasAny, Value: []string{"go", "rust"}},
+			expected: "tags && (go,rust)",
+		},
+		{
+			name:     "length comparison",
+			filter:   filter.Filter{Field: "tags", Operator: filter.OpLenGte, Value: float64(2)},
+			expected: "cardinality(tags) >= 2",
+		},
+		{
+			name:     "null check",
+			filter:   filter.Filter{Field: "tags", Operator: filter.OpNull},
+			expected: "tags IS NULL",
+		},
+		{
+			name:     "has falls back to has any",
+			filter:   filter.Filter{Field: "scores", Operator: filter.OpHas, Value: "7"},
+			expected: "scores && [7]",
+		},
+		{
+			name:     "numeric elements from json",
+			filter:   filter.Filter{Field: "scores", Operator: filter.OpHasAny, Value: []any{float64(1), float64(2)}},
+			expected: "scores && [1 2]",
+		},
+		{
+			name:     "exact length",
+			filter:   filter.Filter{Field: "scores", Operator: filter.OpLen, Value: "3"},
+			expected: "cardinality(scores) = 3",
+		},
+		{
+			name:   "invalid element",
+			filter: filter.Filter{Field: "scores", Operator: filter.OpHasAny, Value: []any{"seven"}},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "negative length",
+			filter: filter.Filter{Field: "scores", Operator: filter.OpLen, Value: -1},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "unconfigured length operator",
+			filter: filter.Filter{Field: "tags", Operator: filter.OpLenLt, Value: 1},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "scalar in is unsupported",
+			filter: filter.Filter{Field: "tags", Operator: filter.OpIn, Value: []any{"go"}},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "scalar contains is unsupported",
+			filter: filter.Filter{Field: "tags", Operator: filter.OpContains, Value: "go"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "scalar eq without null or length predicates",
+			filter: filter.Filter{Field: "scores", Operator: filter.OpEq, Value: 1},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "array operators on scalar fields are unsupported",
+			filter: filter.Filter{Field: "email", Operator: filter.OpHas, Value: "x"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildOne(t, builders, tt.filter)
+			lenient := applyLenient(builders, tt.filter)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				if len(lenient) != 0 {
+					t.Errorf("expected lenient mode to skip the filter, got %v", lenient)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", tt.expected, got)
+			}
+			if len(lenient) != 1 || lenient[0] != tt.expected {
+				t.Errorf("\nexpected lenient: %s\ngot:              %v", tt.expected, lenient)
+			}
+		})
+	}
+
+	t.Run("scalar methods match every row", func(t *testing.T) {
+		tags := builders["tags"]
+
+		expected := MockPredicate("cardinality(tags) >= 0")
+		for _, got := range []MockPredicate{tags.Eq("go"), tags.In([]any{"go"}), tags.Contains("go")} {
+			if got != expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", expected, got)
+			}
+		}
+		if got := tags.IsNull(); got != "tags IS NULL" {
+			t.Errorf("expected tags IS NULL, got %s", got)
+		}
+	})
+}
+
+func TestBuilders_Between(t *testing.T) {
+	builders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.IntField("age", filter.IntPredicates[MockPredicate, int]{
+			Gt:  mockNumberOp[int]("age", ">"),
+			Gte: mockNumberOp[int]("age", ">="),
+			Lt:  mockNumberOp[int]("age", "<"),
+			Lte: mockNumberOp[int]("age", "<="),
+		}),
+		filter.FloatField("price", filter.FloatPredicates[MockPredicate, float64]{
+			Gte: mockNumberOp[float64]("price", ">="),
+			Lte: mockNumberOp[float64]("price", "<="),
+		}),
+		filter.TimeField("created_at", mockTimePredicates("created_at")),
+		filter.StringField("email", mockStringPredicates("email")),
+	)
+
+	tests := []struct {
+		name     string
+		filter   filter.Filter
+		expected string
+		err      error
+	}{
+		{
+			name:     "numeric between from json array",
+			filter:   filter.Filter{Field: "age", Operator: filter.OpBetween, Value: []any{float64(18), float64(65)}},
+			expected: "(age >= 18 AND age <= 65)",
+		},
+		{
+			name:     "numeric not between",
+			filter:   filter.Filter{Field: "age", Operator: filter.OpNbetween, Value: []any{18, 65}},
+			expected: "(age < 18 OR age > 65)",
+		},
+		{
+			name:     "exclusive bounds",
+			filter:   filter.Filter{Field: "age", Operator: filter.OpBetween, Value: filter.Range{From: 18, To: 65, ExclusiveFrom: true, ExclusiveTo: true}},
+			expected: "(age > 18 AND age < 65)",
+		},
+		{
+			name:     "exclusive bounds negated",
+			filter:   filter.Filter{Field: "age", Operator: filter.OpNbetween, Value: filter.Range{From: 18, To: 65, ExclusiveTo: true}},
+			expected: "(age < 18 OR age >= 65)",
+		},
+		{
+			name:     "open-ended range",
+			filter:   filter.Filter{Field: "age", Operator: filter.OpBetween, Value: []any{nil, "30"}},
+			expected: "age <= 30",
+		},
+		{
+			name:     "string bounds from query parameters",
+			filter:   filter.Filter{Field: "price", Operator: filter.OpBetween, Value: []string{"9.5", "20"}},
+			expected: "(price >= 9.5 AND price <= 20)",
+		},
+		{
+			name: "time range from json object",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpBetween, Value: map[string]any{
+				"from": "2024-01-01", "to": "2024-02-01", "exclusive_to": true,
+			}},
+			expected: "(created_at >= 2024-01-01T00:00:00Z AND created_at < 2024-02-01T00:00:00Z)",
+		},
+		{
+			name:   "missing comparison function",
+			filter: filter.Filter{Field: "price", Operator: filter.OpBetween, Value: filter.Range{From: 1, To: 2, ExclusiveTo: true}},
+			err:    filter.ErrUnsupportedOperator,
+		},
+		{
+			name:   "wrong number of bounds",
+			filter: filter.Filter{Field: "age", Operator: filter.OpBetween, Value: []any{1, 2, 3}},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "no bounds",
+			filter: filter.Filter{Field: "age", Operator: filter.OpBetween, Value: []any{nil, nil}},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "scalar value",
+			filter: filter.Filter{Field: "age", Operator: filter.OpBetween, Value: 18},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "invalid bound",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpBetween, Value: []any{"2024-01-01", "soon"}},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "unknown range key",
+			filter: filter.Filter{Field: "age", Operator: filter.OpBetween, Value: map[string]any{"min": 1}},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "string fields do not support ranges",
+			filter: filter.Filter{Field: "email", Operator: filter.OpBetween, Value: []any{"a", "b"}},
+			err:    filter.ErrUnsupportedOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildOne(t, builders, tt.filter)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTimeBuilder_RelativeDates(t *testing.T) {
+	// Thursday
+	now := time.Date(2024, 3, 14, 15, 30, 45, 0, time.UTC)
+	clock := func() time.Time { return now }
+	monthEnd := time.Date(2024, 3, 31, 10, 0, 0, 0, time.UTC)
+
+	builders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.TimeField("created_at", mockTimePredicates("created_at"), filter.WithClock(clock)),
+		filter.TimeField("due_at", mockTimePredicates("due_at"),
+			filter.WithClock(clock), filter.WithLocation(time.FixedZone("UTC+2", 2*60*60))),
+		filter.TimeField("closed_at", mockTimePredicates("closed_at"),
+			filter.WithClock(func() time.Time { return monthEnd }), filter.WithLocation(nil)),
+	)
+
+	tests := []struct {
+		name     string
+		filter   filter.Filter
+		expected string
+		err      error
+	}{
+		{
+			name:     "now",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpLte, Value: "now"},
+			expected: "created_at <= 2024-03-14T15:30:45Z",
+		},
+		{
+			name:     "offset",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "now-7d"},
+			expected: "created_at >= 2024-03-07T15:30:45Z",
+		},
+		{
+			name:     "multiple offsets",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "now+1h-30m"},
+			expected: "created_at >= 2024-03-14T16:00:45Z",
+		},
+		{
+			name:     "rounded to day",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "now/d"},
+			expected: "created_at >= 2024-03-14T00:00:00Z",
+		},
+		{
+			name:     "offset then rounded",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "now-1M/M"},
+			expected: "created_at >= 2024-02-01T00:00:00Z",
+		},
+		{
+			name:     "month offset clamps to the end of the month",
+			filter:   filter.Filter{Field: "closed_at", Operator: filter.OpGte, Value: "now-1M"},
+			expected: "closed_at >= 2024-02-29T10:00:00Z",
+		},
+		{
+			name:     "year offset clamps to the end of the month",
+			filter:   filter.Filter{Field: "closed_at", Operator: filter.OpGte, Value: "now-1M+1y"},
+			expected: "closed_at >= 2025-02-28T10:00:00Z",
+		},
+		{
+			name:     "nil location defaults to UTC",
+			filter:   filter.Filter{Field: "closed_at", Operator: filter.OpLt, Value: "today"},
+			expected: "closed_at < 2024-03-31T00:00:00Z",
+		},
+		{
+			name:     "yesterday",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "yesterday"},
+			expected: "created_at >= 2024-03-13T00:00:00Z",
+		},
+		{
+			name:     "keywords are case-insensitive",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpLt, Value: "Tomorrow"},
+			expected: "created_at < 2024-03-15T00:00:00Z",
+		},
+		{
+			name:     "start of month",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "startOf:month"},
+			expected: "created_at >= 2024-03-01T00:00:00Z",
+		},
+		{
+			name:     "start of week is monday",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "startOf:week"},
+			expected: "created_at >= 2024-03-11T00:00:00Z",
+		},
+		{
+			name:     "end of month",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpLte, Value: "endOf:month"},
+			expected: "created_at <= 2024-03-31T23:59:59Z",
+		},
+		{
+			name:     "start of previous year",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "startOf:year-1y"},
+			expected: "created_at >= 2023-01-01T00:00:00Z",
+		},
+		{
+			name:     "relative range",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpBetween, Value: []any{"now-1h", "now"}},
+			expected: "(created_at >= 2024-03-14T14:30:45Z AND created_at <= 2024-03-14T15:30:45Z)",
+		},
+		{
+			name:     "calendar boundaries use the configured location",
+			filter:   filter.Filter{Field: "due_at", Operator: filter.OpLt, Value: "today"},
+			expected: "due_at < 2024-03-14T00:00:00+02:00",
+		},
+		{
+			name:     "date-only values use the configured location",
+			filter:   filter.Filter{Field: "due_at", Operator: filter.OpGte, Value: "2024-01-01"},
+			expected: "due_at >= 2024-01-01T00:00:00+02:00",
+		},
+		{
+			name:   "unknown unit",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "now-7x"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "offset without amount",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "now-d"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "offset overflows a duration",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "now-99999999999999s"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "offset overflows in hours",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "now+2562048h"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "unknown period",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "startOf:fortnight"},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "unknown keyword",
+			filter: filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "last week"},
+			err:    filter.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildOne(t, builders, tt.filter)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTimeBuilder_RelativeDatesResolveWhenApplied(t *testing.T) {
+	now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+	builders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.TimeField("created_at", mockTimePredicates("created_at"),
+			filter.WithClock(func() time.Time { return now })),
+	)
+	saved := filter.Filter{Field: "created_at", Operator: filter.OpGte, Value: "now-7d/d"}
+
+	first, err := buildOne(t, builders, saved)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now = now.AddDate(0, 1, 0)
+	second, err := buildOne(t, builders, saved)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first != "created_at >= 2024-03-07T00:00:00Z" {
+		t.Errorf("unexpected first predicate: %s", first)
+	}
+	if second != "created_at >= 2024-04-07T00:00:00Z" {
+		t.Errorf("unexpected second predicate: %s", second)
+	}
+}
+
+func TestStringBuilder_Patterns(t *testing.T) {
+	invoices := mockStringPredicates("number")
+	invoices.Regex = mockOp("number", "~")
+	invoices.NotRegex = mockOp("number", "!~")
+	invoices.Like = mockOp("number", "LIKE")
+	invoices.ILike = mockOp("number", "ILIKE")
+
+	builders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.StringField("number", invoices),
+		filter.StringField("email", mockStringPredicates("email")),
+	)
+
+	tests := []struct {
+		name     string
+		filter   filter.Filter
+		expected string
+		err      error
+	}{
+		{
+			name:     "regex",
+			filter:   filter.Filter{Field: "number", Operator: filter.OpRegex, Value: `^INV-2024-\d+$`},
+			expected: `number ~ ^INV-2024-\d+$`,
+		},
+		{
+			name:     "negated regex",
+			filter:   filter.Filter{Field: "number", Operator: filter.OpNregex, Value: `^DRAFT-`},
+			expected: `number !~ ^DRAFT-`,
+		},
+		{
+			name:     "like with wildcards",
+			filter:   filter.Filter{Field: "number", Operator: filter.OpLike, Value: "INV-2024-%"},
+			expected: "number LIKE INV-2024-%",
+		},
+		{
+			name:     "like with escaped wildcard",
+			filter:   filter.Filter{Field: "number", Operator: filter.OpLike, Value: `100\%_`},
+			expected: `number LIKE 100\%_`,
+		},
+		{
+			name:     "ilike",
+			filter:   filter.Filter{Field: "number", Operator: filter.OpIlike, Value: "inv-%"},
+			expected: "number ILIKE inv-%",
+		},
+		{
+			name:   "regex with backreference",
+			filter: filter.Filter{Field: "number", Operator: filter.OpRegex, Value: `(a)\1`},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "regex with lookahead",
+			filter: filter.Filter{Field: "number", Operator: filter.OpRegex, Value: `INV(?=-)`},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "regex with nested repetition",
+			filter: filter.Filter{Field: "number", Operator: filter.OpNregex, Value: `^(a+)+$`},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "regex too long",
+			filter: filter.Filter{Field: "number", Operator: filter.OpRegex, Value: strings.Repeat("a", filter.MaxPatternLength+1)},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "regex must be a string",
+			filter: filter.Filter{Field: "number", Operator: filter.OpRegex, Value: 42},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "like with trailing escape",
+			filter: filter.Filter{Field: "number", Operator: filter.OpLike, Value: `INV\`},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "like with invalid escape",
+			filter: filter.Filter{Field: "number", Operator: filter.OpLike, Value: `INV\d`},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "ilike is validated",
+			filter: filter.Filter{Field: "number", Operator: filter.OpIlike, Value: `INV\d`},
+			err:    filter.ErrInvalidValue,
+		},
+		{
+			name:   "pattern predicates not configured",
+			filter: filter.Filter{Field: "email", Operator: filter.OpRegex, Value: "^a"},
+			err:    filter.ErrUnsupportedOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildOne(t, builders, tt.filter)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				// Rejected patterns are skipped in lenient mode, never built as Eq
+				if lenient := applyLenient(builders, tt.filter); len(lenient) != 0 {
+					t.Errorf("expected lenient mode to skip the filter, got %v", lenient)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateRegex(t *testing.T) {
+	valid := []string{`^INV-2024-\d+$`, `(ab)+c*`, `a{2,5}`, `(a{1,3})+`, `[a-z]+@example\.com`}
+	for _, pattern := range valid {
+		if err := filter.ValidateRegex(pattern); err != nil {
+			t.Errorf("ValidateRegex(%q) = %v, expected nil", pattern, err)
+		}
+	}
+
+	invalid := []string{`(`, `(a*)*`, `(?:a|b+)+`, `(x+x+)+y`, `\1`, `(?<=a)b`}
+	for _, pattern := range invalid {
+		if err := filter.ValidateRegex(pattern); !errors.Is(err, filter.ErrInvalidValue) {
+			t.Errorf("ValidateRegex(%q) = %v, expected ErrInvalidValue", pattern, err)
+		}
+	}
+}
+
+func TestStringBuilder_NegatedAndCaseSensitive(t *testing.T) {
+	code := mockStringPredicates("code")
+	code.NotContains = mockOp("code", "NOT CONTAINS")
+	code.ContainsCaseSensitive = mockOp("code", "CONTAINS_CS")
+	code.StartsWithCaseSensitive = mockOp("code", "STARTSWITH_CS")
+	code.EndsWithCaseSensitive = mockOp("code", "ENDSWITH_CS")
+
+	builders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.StringField("code", code),
+		filter.StringField("email", mockStringPredicates("email")),
+	)
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+	withNot := filter.PredicateBuilder[MockPredicate]{Or: mockOr, And: mockAnd, Not: mockNot}
+	withoutNot := filter.PredicateBuilder[MockPredicate]{Or: mockOr, And: mockAnd}
+
+	tests := []struct {
+		name     string
+		builder  filter.PredicateBuilder[MockPredicate]
+		filter   filter.Filter
+		expected string
+		err      error
+	}{
+		{
+			name:     "dedicated negated predicate",
+			builder:  withNot,
+			filter:   filter.Filter{Field: "code", Operator: filter.OpNcontains, Value: "tmp"},
+			expected: "code NOT CONTAINS tmp",
+		},
+		{
+			name:     "ncontains falls back to Not(Contains)",
+			builder:  withNot,
+			filter:   filter.Filter{Field: "email", Operator: filter.OpNcontains, Value: "@test"},
+			expected: "NOT email CONTAINS @test",
+		},
+		{
+			name:     "nstartswith falls back to Not(StartsWith)",
+			builder:  withNot,
+			filter:   filter.Filter{Field: "email", Operator: filter.OpNstartsWith, Value: "admin"},
+			expected: "NOT email STARTSWITH admin",
+		},
+		{
+			name:     "nendswith falls back to Not(EndsWith)",
+			builder:  withNot,
+			filter:   filter.Filter{Field: "email", Operator: filter.OpNendsWith, Value: ".org"},
+			expected: "NOT email ENDSWITH .org",
+		},
+		{
+			name:     "containss",
+			builder:  withNot,
+			filter:   filter.Filter{Field: "code", Operator: filter.OpContainsCS, Value: "AB"},
+			expected: "code CONTAINS_CS AB",
+		},
+		{
+			name:     "startswiths",
+			builder:  withNot,
+			filter:   filter.Filter{Field: "code", Operator: filter.OpStartsWithCS, Value: "INV"},
+			expected: "code STARTSWITH_CS INV",
+		},
+		{
+			name:     "endswiths",
+			builder:  withNot,
+			filter:   filter.Filter{Field: "code", Operator: filter.OpEndsWithCS, Value: "X"},
+			expected: "code ENDSWITH_CS X",
+		},
+		{
+			name:    "no negation without PredicateBuilder.Not",
+			builder: withoutNot,
+			filter:  filter.Filter{Field: "email", Operator: filter.OpNcontains, Value: "@test"},
+			err:     filter.ErrUnsupportedOperator,
+		},
+		{
+			name:    "case-sensitive predicates not configured",
+			builder: withNot,
+			filter:  filter.Filter{Field: "email", Operator: filter.OpContainsCS, Value: "A"},
+			err:     filter.ErrUnsupportedOperator,
+		},
+		{
+			name:    "non-string value",
+			builder: withNot,
+			filter:  filter.Filter{Field: "email", Operator: filter.OpNcontains, Value: 1},
+			err:     filter.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := filter.FilterGroup{Filters: []filter.Filter{tt.filter}, Logic: "and"}
+			result, err := filter.ApplyStructuredFiltersE(&MockQuery{}, cfg, group, builders, tt.builder)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.predicates) != 1 || result.predicates[0] != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %v", tt.expected, result.predicates)
+			}
+		})
+	}
+}
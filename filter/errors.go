@@ -0,0 +1,92 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors reported by ApplyStructuredFiltersE and OperatorBuilder implementations.
+// Use errors.Is to classify a failure, e.g. to map it to a 400 Bad Request response.
+var (
+	// ErrUnknownField is reported when a filter references a field with no registered builder
+	ErrUnknownField = errors.New("unknown field")
+
+	// ErrUnsupportedOperator is reported when a field does not support the requested operator
+	ErrUnsupportedOperator = errors.New("unsupported operator")
+
+	// ErrInvalidValue is reported when a filter value cannot be converted to the field's type
+	ErrInvalidValue = errors.New("invalid value")
+
+	// ErrInvalidGroup is reported for malformed filter groups (unknown logic, missing negation support)
+	ErrInvalidGroup = errors.New("invalid filter group")
+
+	// ErrInvalidSearcher is reported by Searcher.Validate for a configuration that is missing a
+	// function it needs. Unlike the errors above it points at the code, not the request.
+	ErrInvalidSearcher = errors.New("invalid searcher")
+)
+
+// FilterError describes a single filter or group that could not be turned into a predicate.
+type FilterError struct {
+	// Path locates the filter within the group tree, e.g. "filters[2]" or "groups[0].filters[1]".
+	// Group-level errors use the group's path ("" for the root group).
+	Path string
+
+	// Index is the position of the filter within its group, or -1 for group-level errors
+	Index int
+
+	// Field and Operator are copied from the failing filter (empty for group-level errors)
+	Field    string
+	Operator Operator
+
+	// Err is the underlying error, typically wrapping one of the sentinel errors above
+	Err error
+}
+
+func (e *FilterError) Error() string {
+	if e.Index < 0 {
+		path := e.Path
+		if path == "" {
+			path = "root group"
+		}
+		return fmt.Sprintf("%s: %v", path, e.Err)
+	}
+	return fmt.Sprintf("%s (field %q, operator %q): %v", e.Path, e.Field, e.Operator, e.Err)
+}
+
+func (e *FilterError) Unwrap() error {
+	return e.Err
+}
+
+// Errors aggregates every FilterError found while building a filter group.
+//
+// Example:
+//
+//	query, err := filter.ApplyStructuredFiltersE(query, cfg, group, fieldBuilders, predicates)
+//	var filterErrs filter.Errors
+//	if errors.As(err, &filterErrs) {
+//	    return http.StatusBadRequest, filterErrs
+//	}
+type Errors []*FilterError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap allows errors.Is and errors.As to match any of the aggregated errors.
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// invalidValue builds an error wrapping ErrInvalidValue with a description of what was expected.
+func invalidValue(value any, expected string) error {
+	return fmt.Errorf("%w: expected %s, got %T", ErrInvalidValue, expected, value)
+}
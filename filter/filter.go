@@ -4,7 +4,10 @@
 // functions that know how to build predicates and apply WHERE clauses for your specific query type.
 package filter
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // Config contains the query-building functions needed for filtering.
 // The Predicate type parameter represents whatever your ORM uses for WHERE conditions.
@@ -39,6 +42,7 @@ type Combinators[P any] struct {
 //	    IDIn: user.IDIn,
 //	    Or:   user.Or,
 //	    And:  user.And,
+//	    Not:  user.Not,
 //	}
 type PredicateBuilder[P any] struct {
 	// IDIn creates a predicate that matches any of the given IDs
@@ -49,6 +53,10 @@ type PredicateBuilder[P any] struct {
 
 	// And combines predicates with AND logic
 	And func(predicates ...P) P
+
+	// Not negates a predicate (only required for negated filter groups, and for negated
+	// string operators on fields without a dedicated predicate function)
+	Not func(predicate P) P
 }
 
 // SearchFields maps database field names to functions that create case-insensitive
@@ -119,6 +127,10 @@ func ApplyIDs[Q any, P any](
 //	    user.FieldFirstName: user.FirstNameContainsFold,
 //	}
 //	query = filter.ApplySearch(query, cfg, searchFields, builder, "john doe")
+//
+// ApplySearch always splits on whitespace and sends every word to every field. For analyzers,
+// full-text strategies or fuzzy matching, use ApplySearchQuery with a Searcher;
+// Searcher{Fields: fields} matches plain words the same way.
 func ApplySearch[Q any, P any](
 	query Q,
 	cfg Config[Q, P],
@@ -136,12 +148,19 @@ func ApplySearch[Q any, P any](
 		return query
 	}
 
+	// Visit fields in a stable order so the generated predicate is deterministic
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	// For each token, create OR predicates across all searchable fields
 	tokenPredicates := make([]P, 0, len(tokens))
 	for _, token := range tokens {
 		fieldPredicates := make([]P, 0, len(fields))
-		for _, containsFoldFunc := range fields {
-			fieldPredicates = append(fieldPredicates, containsFoldFunc(token))
+		for _, name := range names {
+			fieldPredicates = append(fieldPredicates, fields[name](token))
 		}
 		// Combine field predicates with OR for this token
 		tokenPredicates = append(tokenPredicates, builder.Or(fieldPredicates...))
@@ -41,6 +41,10 @@ func mockAnd(predicates ...MockPredicate) MockPredicate {
 	return MockPredicate(fmt.Sprintf("(%s)", strings.Join(strs, " AND ")))
 }
 
+func mockNot(predicate MockPredicate) MockPredicate {
+	return MockPredicate(fmt.Sprintf("NOT %s", predicate))
+}
+
 func mockContainsFold(field string) func(string) MockPredicate {
 	return func(value string) MockPredicate {
 		return MockPredicate(fmt.Sprintf("%s ILIKE '%%%s%%'", field, value))
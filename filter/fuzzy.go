@@ -0,0 +1,93 @@
+package filter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TrigramSimilar returns a SearchField.Similar function for PostgreSQL's pg_trgm extension.
+// It compares the term with the most similar part of the column, so "jonh" matches
+// "John Smith":
+//
+//	(? <% column AND word_similarity(?, column) >= ?)
+//
+// The <% operator lets a GIN or GiST trigram index on the column find candidate rows; the
+// word_similarity comparison then applies the field's Threshold. <% uses the server's
+// pg_trgm.word_similarity_threshold (0.6 by default), so set it no higher than the lowest
+// Threshold, e.g. SET pg_trgm.word_similarity_threshold = 0.3, or rows between the two are
+// never matched. SearchScore ranks rows through SQLScoreBuilder.Similarity separately.
+//
+// Example:
+//
+//	"name": {
+//	    Mode:      filter.MatchFuzzy,
+//	    Threshold: 0.4,
+//	    Similar:   filter.TrigramSimilar("name", raw), // raw builds a predicate from SQL
+//	},
+func TrigramSimilar[P any](column string, where func(sql string, args ...any) P) func(term string, threshold float64) P {
+	return func(term string, threshold float64) P {
+		return where("(? <% "+column+" AND word_similarity(?, "+column+") >= ?)", term, term, threshold)
+	}
+}
+
+// Levenshtein returns the number of single-character insertions, deletions and substitutions
+// needed to turn a into b.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+
+	// Keep a single row of the distance matrix, sized by the shorter string
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		diagonal := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			next := min(row[j]+1, row[j-1]+1, diagonal+cost)
+			diagonal, row[j] = row[j], next
+		}
+	}
+	return row[len(rb)]
+}
+
+// Similarity returns how similar a and b are, from 0 (nothing in common) to 1 (equal ignoring
+// case): one minus their Levenshtein distance divided by the length of the longer string.
+func Similarity(a, b string) float64 {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	longest := max(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(a, b))/float64(longest)
+}
+
+// WordSimilarity returns the highest Similarity between term and a run of as many
+// consecutive words of text as term has, or text as a whole. It is the in-memory counterpart
+// of TrigramSimilar: WordSimilarity("jonh", "John Smith") is 0.5.
+//
+// Use it to evaluate MatchFuzzy fields in memory and to order results by similarity:
+//
+//	"name": {
+//	    Mode: filter.MatchFuzzy,
+//	    Similar: func(term string, threshold float64) func(User) bool {
+//	        return func(u User) bool { return filter.WordSimilarity(term, u.Name) >= threshold }
+//	    },
+//	},
+func WordSimilarity(term, text string) float64 {
+	best := Similarity(term, text)
+
+	n := len(strings.Fields(term))
+	words := strings.Fields(text)
+	for i := 0; n > 0 && i+n <= len(words); i++ {
+		best = max(best, Similarity(term, strings.Join(words[i:i+n], " ")))
+	}
+	return best
+}
@@ -0,0 +1,143 @@
+package filter_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "", b: "", expected: 0},
+		{a: "", b: "abc", expected: 3},
+		{a: "kitten", b: "sitting", expected: 3},
+		{a: "jonh", b: "john", expected: 2},
+		{a: "smiht", b: "smith", expected: 2},
+		{a: "café", b: "cafe", expected: 1},
+		{a: "flaw", b: "lawn", expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if d := filter.Levenshtein(tt.a, tt.b); d != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, d)
+			}
+			if d := filter.Levenshtein(tt.b, tt.a); d != tt.expected {
+				t.Errorf("reversed: expected %d, got %d", tt.expected, d)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		score    float64
+		expected float64
+	}{
+		{name: "equal ignoring case", score: filter.Similarity("John", "jOHN"), expected: 1},
+		{name: "empty", score: filter.Similarity("", ""), expected: 1},
+		{name: "transposed letters", score: filter.Similarity("jonh", "john"), expected: 0.5},
+		{name: "one typo", score: filter.Similarity("smiht", "smith"), expected: 0.6},
+		{name: "word in text", score: filter.WordSimilarity("jonh", "John Smith"), expected: 0.5},
+		{name: "words in text", score: filter.WordSimilarity("jonh smiht", "Dr. John Smith"), expected: 0.6},
+		{name: "whole text", score: filter.WordSimilarity("johnsmith", "john smith"), expected: 0.9},
+		{name: "unrelated", score: filter.WordSimilarity("xyz", "John Smith"), expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if math.Abs(tt.score-tt.expected) > 1e-9 {
+				t.Errorf("expected %v, got %v", tt.expected, tt.score)
+			}
+		})
+	}
+}
+
+func TestSearcher_Fuzzy(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+	builder := filter.PredicateBuilder[MockPredicate]{Or: mockOr, And: mockAnd, Not: mockNot}
+
+	searcher := filter.Searcher[MockPredicate]{
+		Columns: map[string]filter.SearchField[MockPredicate]{
+			"name":  {Mode: filter.MatchFuzzy, Weight: 2, Similar: filter.TrigramSimilar("name", mockWhere)},
+			"email": {Mode: filter.MatchFuzzy, Threshold: 0.6, Similar: filter.TrigramSimilar("email", mockWhere)},
+		},
+	}
+
+	t.Run("predicate", func(t *testing.T) {
+		query, err := filter.ApplySearchQuery(&MockQuery{}, cfg, searcher, builder, "jonh")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []string{"(('jonh' <% email AND word_similarity('jonh', email) >= '0.6') OR ('jonh' <% name AND word_similarity('jonh', name) >= '0.3'))"}
+		if !reflect.DeepEqual(query.predicates, expected) {
+			t.Errorf("\nexpected: %v\ngot:      %v", expected, query.predicates)
+		}
+	})
+
+	t.Run("score", func(t *testing.T) {
+		score, ok := filter.SearchScore(searcher, "jonh", filter.SQLScoreBuilder{})
+		if !ok {
+			t.Fatal("expected a score")
+		}
+		expected := filter.SQLExpr{
+			SQL:  "word_similarity(?, email) + word_similarity(?, name) * 2",
+			Args: []any{"jonh", "jonh"},
+		}
+		if !reflect.DeepEqual(score, expected) {
+			t.Errorf("\nexpected: %#v\ngot:      %#v", expected, score)
+		}
+	})
+
+	t.Run("in memory", func(t *testing.T) {
+		type user struct{ Name string }
+		users := []user{{Name: "John Smith"}, {Name: "Jane Doe"}, {Name: "Jon Smithers"}}
+
+		memory := filter.Searcher[func(user) bool]{
+			Columns: map[string]filter.SearchField[func(user) bool]{
+				"name": {
+					Mode:      filter.MatchFuzzy,
+					Threshold: 0.5,
+					Similar: func(term string, threshold float64) func(user) bool {
+						return func(u user) bool { return filter.WordSimilarity(term, u.Name) >= threshold }
+					},
+				},
+			},
+		}
+		and := func(ps ...func(user) bool) func(user) bool {
+			return func(u user) bool {
+				for _, p := range ps {
+					if !p(u) {
+						return false
+					}
+				}
+				return true
+			}
+		}
+		match, ok, err := memory.Predicate("jonh smiht", filter.PredicateBuilder[func(user) bool]{And: and})
+		if err != nil || !ok {
+			t.Fatalf("expected a predicate, got %v", err)
+		}
+
+		var names []string
+		for _, u := range users {
+			if match(u) {
+				names = append(names, u.Name)
+			}
+		}
+		if expected := []string{"John Smith", "Jon Smithers"}; !reflect.DeepEqual(names, expected) {
+			t.Errorf("expected %v, got %v", expected, names)
+		}
+	})
+}
@@ -0,0 +1,245 @@
+// Package hasura converts Hasura-style GraphQL `where` boolean expressions into dewey filter
+// groups, so the field builders registered with filter.BuildFilterMap serve GraphQL and REST
+// endpoints alike.
+//
+// A where argument is an object whose keys are fields, relationships or the _and/_or/_not
+// combinators:
+//
+//	{
+//	  "_and": [{"email": {"_ilike": "%@acme.com"}}, {"_or": [{"role": {"_eq": "admin"}}, {"age": {"_gte": 18}}]}],
+//	  "author": {"name": {"_eq": "Ada"}},
+//	  "deleted_at": {"_is_null": true}
+//	}
+//
+// Parse accepts the map[string]any produced by gqlgen or encoding/json:
+//
+//	group, err := hasura.Parse(where)
+//	if err != nil {
+//	    return nil, gqlerror.Errorf("invalid where: %v", err)
+//	}
+//	query, err = filter.ApplyStructuredFiltersE(query, cfg, group, userFilterBuilders, predicates)
+//
+// Relationship keys nest: author: {name: {_eq: "Ada"}} becomes the dotted field "author.name".
+package hasura
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/internal/parse"
+)
+
+// operator describes how a Hasura comparison operator maps onto dewey.
+type operator struct {
+	op     filter.Operator
+	negate bool // wrap the filter in a negated group
+}
+
+// operators maps the Hasura comparison operators. _is_null and _has_key take a boolean and a
+// key respectively and are handled specially.
+var operators = map[string]operator{
+	"_eq":      {op: filter.OpEq},
+	"_neq":     {op: filter.OpNe},
+	"_gt":      {op: filter.OpGt},
+	"_gte":     {op: filter.OpGte},
+	"_lt":      {op: filter.OpLt},
+	"_lte":     {op: filter.OpLte},
+	"_in":      {op: filter.OpIn},
+	"_nin":     {op: filter.OpNin},
+	"_like":    {op: filter.OpLike},
+	"_nlike":   {op: filter.OpLike, negate: true},
+	"_ilike":   {op: filter.OpIlike},
+	"_nilike":  {op: filter.OpIlike, negate: true},
+	"_regex":   {op: filter.OpRegex},
+	"_nregex":  {op: filter.OpNregex},
+	"_is_null": {op: filter.OpNull},
+	"_has_key": {op: filter.OpHasKey},
+}
+
+// Parse converts a where object into a filter group. The keys of an object are combined with
+// AND, as Hasura does, and an empty object matches everything.
+//
+// Problems are reported as a filter.Errors whose paths, such as "_and[1].email._ilike", locate
+// them in the input. Unknown operators wrap filter.ErrUnsupportedOperator, malformed
+// combinators wrap filter.ErrInvalidGroup and bad operator values wrap filter.ErrInvalidValue.
+func Parse(where map[string]any) (filter.FilterGroup, error) {
+	var c converter
+	group := c.object(where, "", "", 0)
+	if len(c.errs) > 0 {
+		return filter.FilterGroup{}, c.errs
+	}
+	return group, nil
+}
+
+// ParseJSON decodes a JSON where object and converts it with Parse. Numbers are decoded as
+// json.Number so large integers keep their precision.
+func ParseJSON(data []byte) (filter.FilterGroup, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var where map[string]any
+	if err := dec.Decode(&where); err != nil {
+		return filter.FilterGroup{}, fmt.Errorf("hasura: decoding where: %w", err)
+	}
+	return Parse(where)
+}
+
+type converter struct {
+	errs filter.Errors
+}
+
+func (c *converter) groupError(path string, err error) {
+	c.errs = append(c.errs, &filter.FilterError{Path: path, Index: -1, Err: err})
+}
+
+// object converts a boolean expression object to an AND group. field is the dotted path of
+// the enclosing field or relationship ("" at the root).
+func (c *converter) object(exp map[string]any, field, path string, depth int) filter.FilterGroup {
+	group := filter.FilterGroup{Logic: "and"}
+	if depth > parse.MaxDepth {
+		c.groupError(path, fmt.Errorf("%w: nested deeper than %d levels", filter.ErrInvalidGroup, parse.MaxDepth))
+		return group
+	}
+
+	keys := make([]string, 0, len(exp))
+	for key := range exp {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		value := exp[key]
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+
+		switch {
+		case key == "_and" || key == "_or":
+			group.Groups = append(group.Groups, c.list(strings.TrimPrefix(key, "_"), value, field, keyPath, depth+1))
+		case key == "_not":
+			sub, ok := value.(map[string]any)
+			if !ok {
+				c.groupError(keyPath, fmt.Errorf("%w: _not expects an object, got %T", filter.ErrInvalidGroup, value))
+				continue
+			}
+			negated := c.object(sub, field, keyPath, depth+1)
+			negated.Not = true
+			group.Groups = append(group.Groups, negated)
+		case strings.HasPrefix(key, "_"):
+			c.comparison(&group, key, value, field, keyPath)
+		default:
+			sub, ok := value.(map[string]any)
+			if !ok {
+				c.groupError(keyPath, fmt.Errorf("%w: expected an object of comparisons, got %T", filter.ErrInvalidGroup, value))
+				continue
+			}
+			nested := key
+			if field != "" {
+				nested = field + "." + key
+			}
+			g := c.object(sub, nested, keyPath, depth+1)
+			group.Filters = append(group.Filters, g.Filters...)
+			group.Groups = append(group.Groups, g.Groups...)
+		}
+	}
+
+	return group
+}
+
+// list converts the expressions of _and/_or into a group with the given logic. Hasura also
+// accepts a single object in place of a list. Expressions consisting of a single filter are
+// inlined into the group's filters.
+func (c *converter) list(logic string, value any, field, path string, depth int) filter.FilterGroup {
+	group := filter.FilterGroup{Logic: logic}
+
+	var exps []any
+	switch v := value.(type) {
+	case []any:
+		exps = v
+	case map[string]any:
+		exps = []any{v}
+	default:
+		c.groupError(path, fmt.Errorf("%w: _%s expects a list of objects, got %T", filter.ErrInvalidGroup, logic, value))
+		return group
+	}
+
+	for i, exp := range exps {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		obj, ok := exp.(map[string]any)
+		if !ok {
+			c.groupError(itemPath, fmt.Errorf("%w: expected an object, got %T", filter.ErrInvalidGroup, exp))
+			continue
+		}
+		sub := c.object(obj, field, itemPath, depth)
+		if len(sub.Filters) == 1 && len(sub.Groups) == 0 {
+			group.Filters = append(group.Filters, sub.Filters[0])
+			continue
+		}
+		group.Groups = append(group.Groups, sub)
+	}
+
+	return group
+}
+
+// comparison adds the filter for a comparison operator on field to group.
+func (c *converter) comparison(group *filter.FilterGroup, key string, value any, field, path string) {
+	fail := func(err error) {
+		c.errs = append(c.errs, &filter.FilterError{
+			Path:     path,
+			Index:    len(group.Filters),
+			Field:    field,
+			Operator: filter.Operator(key),
+			Err:      err,
+		})
+	}
+
+	m, ok := operators[key]
+	switch {
+	case !ok:
+		fail(fmt.Errorf("%w: hasura operator %q has no equivalent", filter.ErrUnsupportedOperator, key))
+		return
+	case field == "":
+		c.groupError(path, fmt.Errorf("%w: operator %s must be nested under a field", filter.ErrInvalidGroup, key))
+		return
+	case value == nil && key != "_is_null":
+		fail(fmt.Errorf("%w: null is not a comparison value, use _is_null", filter.ErrInvalidValue))
+		return
+	}
+
+	f := filter.Filter{Field: field, Operator: m.op, Value: value}
+	switch key {
+	case "_is_null":
+		isNull, ok := value.(bool)
+		if !ok {
+			fail(fmt.Errorf("%w: _is_null expects true or false, got %T", filter.ErrInvalidValue, value))
+			return
+		}
+		if !isNull {
+			f.Operator = filter.OpNnull
+		}
+		f.Value = nil
+	case "_has_key":
+		k, ok := value.(string)
+		if !ok || k == "" {
+			fail(fmt.Errorf("%w: _has_key expects a key name, got %v", filter.ErrInvalidValue, value))
+			return
+		}
+		f.Field = field + "." + k
+		f.Value = nil
+	}
+
+	if m.negate {
+		group.Groups = append(group.Groups, filter.FilterGroup{
+			Filters: []filter.Filter{f},
+			Logic:   "and",
+			Not:     true,
+		})
+		return
+	}
+	group.Filters = append(group.Filters, f)
+}
@@ -0,0 +1,229 @@
+package hasura_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/filter/hasura"
+)
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected filter.FilterGroup
+	}{
+		{
+			name:  "fields are combined with and",
+			input: `{"age": {"_gte": 18, "_lt": 65}, "email": {"_ilike": "%@acme.com"}}`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "age", Operator: filter.OpGte, Value: json.Number("18")},
+					{Field: "age", Operator: filter.OpLt, Value: json.Number("65")},
+					{Field: "email", Operator: filter.OpIlike, Value: "%@acme.com"},
+				},
+			},
+		},
+		{
+			name: "combinators",
+			input: `{
+				"_and": [
+					{"email": {"_ilike": "%x%"}},
+					{"_or": [{"role": {"_eq": "admin"}}, {"role": {"_in": ["owner", "editor"]}}]}
+				],
+				"_not": {"status": {"_eq": "banned"}}
+			}`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Groups: []filter.FilterGroup{
+					{
+						Logic:   "and",
+						Filters: []filter.Filter{{Field: "email", Operator: filter.OpIlike, Value: "%x%"}},
+						Groups: []filter.FilterGroup{
+							{
+								Logic: "and",
+								Groups: []filter.FilterGroup{
+									{
+										Logic: "or",
+										Filters: []filter.Filter{
+											{Field: "role", Operator: filter.OpEq, Value: "admin"},
+											{Field: "role", Operator: filter.OpIn, Value: []any{"owner", "editor"}},
+										},
+									},
+								},
+							},
+						},
+					},
+					{
+						Logic:   "and",
+						Not:     true,
+						Filters: []filter.Filter{{Field: "status", Operator: filter.OpEq, Value: "banned"}},
+					},
+				},
+			},
+		},
+		{
+			name:  "relationships become dotted fields",
+			input: `{"author": {"name": {"_eq": "Ada"}, "_or": [{"age": {"_gt": 30}}, {"verified": {"_eq": true}}]}}`,
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "author.name", Operator: filter.OpEq, Value: "Ada"}},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "or",
+						Filters: []filter.Filter{
+							{Field: "author.age", Operator: filter.OpGt, Value: json.Number("30")},
+							{Field: "author.verified", Operator: filter.OpEq, Value: true},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "null checks, negated patterns and keys",
+			input: `{"deleted_at": {"_is_null": true}, "manager_id": {"_is_null": false}, "name": {"_nilike": "test%"}, "metadata": {"_has_key": "plan"}}`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "deleted_at", Operator: filter.OpNull},
+					{Field: "manager_id", Operator: filter.OpNnull},
+					{Field: "metadata.plan", Operator: filter.OpHasKey},
+				},
+				Groups: []filter.FilterGroup{
+					{Logic: "and", Not: true, Filters: []filter.Filter{{Field: "name", Operator: filter.OpIlike, Value: "test%"}}},
+				},
+			},
+		},
+		{
+			name:     "empty object matches everything",
+			input:    `{}`,
+			expected: filter.FilterGroup{Logic: "and"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := hasura.ParseJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(group, tt.expected) {
+				t.Errorf("\nexpected: %#v\ngot:      %#v", tt.expected, group)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Run("problems are reported with their location", func(t *testing.T) {
+		_, err := hasura.Parse(map[string]any{
+			"_and": []any{
+				map[string]any{"email": map[string]any{"_similar": "%x%"}},
+				"oops",
+			},
+			"age":        map[string]any{"_eq": nil},
+			"deleted_at": map[string]any{"_is_null": "yes"},
+			"_eq":        1,
+			"name":       "Ada",
+		})
+
+		var filterErrs filter.Errors
+		if !errors.As(err, &filterErrs) {
+			t.Fatalf("expected filter.Errors, got %T: %v", err, err)
+		}
+
+		got := make(map[string]error, len(filterErrs))
+		for _, e := range filterErrs {
+			got[e.Path] = e.Err
+		}
+		expected := map[string]error{
+			"_and[0].email._similar": filter.ErrUnsupportedOperator,
+			"_and[1]":                filter.ErrInvalidGroup,
+			"age._eq":                filter.ErrInvalidValue,
+			"deleted_at._is_null":    filter.ErrInvalidValue,
+			"_eq":                    filter.ErrInvalidGroup,
+			"name":                   filter.ErrInvalidGroup,
+		}
+		if len(got) != len(expected) {
+			t.Fatalf("expected %d errors, got %d: %v", len(expected), len(got), err)
+		}
+		for path, sentinel := range expected {
+			if !errors.Is(got[path], sentinel) {
+				t.Errorf("%s: expected %v, got %v", path, sentinel, got[path])
+			}
+		}
+	})
+
+	t.Run("nesting is limited", func(t *testing.T) {
+		where := map[string]any{"id": map[string]any{"_eq": 1}}
+		for range 100 {
+			where = map[string]any{"_not": where}
+		}
+		if _, err := hasura.Parse(where); !errors.Is(err, filter.ErrInvalidGroup) {
+			t.Fatalf("expected ErrInvalidGroup, got %v", err)
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := hasura.ParseJSON([]byte(`[{"email": {}}]`))
+		if err == nil || !strings.HasPrefix(err.Error(), "hasura: decoding where") {
+			t.Fatalf("expected decoding error, got %v", err)
+		}
+	})
+}
+
+// MockPredicate represents a WHERE condition
+type MockPredicate string
+
+func TestParse_AppliesWithStructuredFilters(t *testing.T) {
+	op := func(field, op string) func(string) MockPredicate {
+		return func(v string) MockPredicate { return MockPredicate(fmt.Sprintf("%s %s %s", field, op, v)) }
+	}
+	join := func(sep string) func(...MockPredicate) MockPredicate {
+		return func(ps ...MockPredicate) MockPredicate {
+			strs := make([]string, len(ps))
+			for i, p := range ps {
+				strs[i] = string(p)
+			}
+			return MockPredicate("(" + strings.Join(strs, sep) + ")")
+		}
+	}
+	not := func(p MockPredicate) MockPredicate { return "NOT " + p }
+
+	// The same registry serves the REST endpoints
+	builders := filter.BuildFilterMap(
+		filter.Combinators[MockPredicate]{Or: join(" OR "), And: join(" AND ")},
+		filter.StringField("email", filter.StringPredicates[MockPredicate]{
+			Eq:    op("email", "="),
+			ILike: op("email", "ILIKE"),
+		}),
+		filter.IntField("age", filter.IntPredicates[MockPredicate, int]{
+			Gte: func(v int) MockPredicate { return MockPredicate(fmt.Sprintf("age >= %d", v)) },
+		}),
+	)
+	predicates := filter.PredicateBuilder[MockPredicate]{Or: join(" OR "), And: join(" AND "), Not: not}
+	cfg := filter.Config[[]MockPredicate, MockPredicate]{
+		Where: func(q []MockPredicate, p MockPredicate) []MockPredicate { return append(q, p) },
+	}
+
+	group, err := hasura.ParseJSON([]byte(`{"_or": [{"email": {"_ilike": "%@acme.com"}}, {"age": {"_gte": 18}}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, err := filter.ApplyStructuredFiltersE(nil, cfg, group, builders, predicates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "(email ILIKE %@acme.com OR age >= 18)"
+	if len(query) != 1 || string(query[0]) != expected {
+		t.Errorf("\nexpected: %s\ngot:      %v", expected, query)
+	}
+}
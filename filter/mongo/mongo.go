@@ -0,0 +1,318 @@
+// Package mongo converts MongoDB query documents into dewey filter groups.
+//
+// Clients send the query document Mongo-backed front ends already build:
+//
+//	{"age": {"$gte": 18}, "$or": [{"status": "active"}, {"tags": {"$in": ["beta"]}}]}
+//
+// Parse accepts the document decoded into a map[string]any, and ParseJSON decodes it first:
+//
+//	group, err := mongo.ParseJSON([]byte(r.URL.Query().Get("q")))
+//	if err != nil {
+//	    return http.StatusBadRequest, err
+//	}
+//	query, err = filter.ApplyStructuredFiltersE(query, cfg, group, userFilterBuilders, predicates)
+//
+// Fields are used as written, so dotted paths such as "author.name" reach the field builders
+// unchanged. Embedded-document equality ({"author": {"name": "Ada"}}) is not supported; use
+// the dotted form instead.
+package mongo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/internal/parse"
+)
+
+// operators maps the Mongo field operators that translate directly. $exists, $regex,
+// $options and $not are handled specially.
+var operators = map[string]filter.Operator{
+	"$eq":   filter.OpEq,
+	"$ne":   filter.OpNe,
+	"$gt":   filter.OpGt,
+	"$gte":  filter.OpGte,
+	"$lt":   filter.OpLt,
+	"$lte":  filter.OpLte,
+	"$in":   filter.OpIn,
+	"$nin":  filter.OpNin,
+	"$all":  filter.OpHasAll,
+	"$size": filter.OpLen,
+}
+
+// regexOptions maps the $options flags that RE2 supports onto inline flags.
+var regexOptions = map[rune]string{
+	'i': "i",
+	'm': "m",
+	's': "s",
+}
+
+// Parse converts a query document into a filter group. The keys of a document are combined
+// with AND, and an empty document matches everything.
+//
+// Supported: implicit equality ({"status": "active"}, with null meaning null), $eq, $ne, $gt,
+// $gte, $lt, $lte, $in, $nin, $all (hasall), $size (len), $exists (nnull/null), $regex with
+// the i, m and s $options, $not, and the $and, $or and $nor combinators.
+//
+// Problems are reported as a filter.Errors whose paths, such as "$or[1].tags.$in", locate them
+// in the document. Unsupported operators like $elemMatch, $where or $text wrap
+// filter.ErrUnsupportedOperator, malformed combinators wrap filter.ErrInvalidGroup and bad
+// operator values wrap filter.ErrInvalidValue.
+func Parse(doc map[string]any) (filter.FilterGroup, error) {
+	var c converter
+	group := c.document(doc, "", 0)
+	if len(c.errs) > 0 {
+		return filter.FilterGroup{}, c.errs
+	}
+	return group, nil
+}
+
+// ParseJSON decodes a JSON query document and converts it with Parse. Numbers are decoded as
+// json.Number so large integers keep their precision.
+func ParseJSON(data []byte) (filter.FilterGroup, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return filter.FilterGroup{}, fmt.Errorf("mongo: decoding query: %w", err)
+	}
+	return Parse(doc)
+}
+
+type converter struct {
+	errs filter.Errors
+}
+
+func (c *converter) groupError(path string, err error) {
+	c.errs = append(c.errs, &filter.FilterError{Path: path, Index: -1, Err: err})
+}
+
+func sortedKeys(doc map[string]any) []string {
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// document converts a query document to an AND group.
+func (c *converter) document(doc map[string]any, path string, depth int) filter.FilterGroup {
+	group := filter.FilterGroup{Logic: "and"}
+	if depth > parse.MaxDepth {
+		c.groupError(path, fmt.Errorf("%w: nested deeper than %d levels", filter.ErrInvalidGroup, parse.MaxDepth))
+		return group
+	}
+
+	for _, key := range sortedKeys(doc) {
+		value := doc[key]
+		keyPath := join(path, key)
+
+		switch key {
+		case "$and", "$or", "$nor":
+			group.Groups = append(group.Groups, c.list(key, value, keyPath, depth+1))
+			continue
+		}
+		if strings.HasPrefix(key, "$") {
+			c.groupError(keyPath, fmt.Errorf("%w: %s is not supported at the top level of a query", filter.ErrUnsupportedOperator, key))
+			continue
+		}
+		if key == "" {
+			c.groupError(keyPath, fmt.Errorf("%w: empty field name", filter.ErrInvalidGroup))
+			continue
+		}
+
+		ops, ok := value.(map[string]any)
+		if !ok {
+			// {"status": "active"} is shorthand for {"status": {"$eq": "active"}}
+			c.comparison(&group, key, "$eq", value, keyPath)
+			continue
+		}
+		sub := c.operators(key, ops, keyPath, depth+1)
+		group.Filters = append(group.Filters, sub.Filters...)
+		group.Groups = append(group.Groups, sub.Groups...)
+	}
+
+	return group
+}
+
+// list converts the documents of $and, $or or $nor into a group. $nor becomes a negated OR
+// group. Documents consisting of a single filter are inlined into the group's filters.
+func (c *converter) list(key string, value any, path string, depth int) filter.FilterGroup {
+	group := filter.FilterGroup{Logic: "and"}
+	if key != "$and" {
+		group.Logic = "or"
+	}
+	group.Not = key == "$nor"
+
+	docs, ok := value.([]any)
+	if !ok || len(docs) == 0 {
+		c.groupError(path, fmt.Errorf("%w: %s expects a non-empty array of documents", filter.ErrInvalidGroup, key))
+		return group
+	}
+
+	for i, item := range docs {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		doc, ok := item.(map[string]any)
+		if !ok {
+			c.groupError(itemPath, fmt.Errorf("%w: expected a document, got %T", filter.ErrInvalidGroup, item))
+			continue
+		}
+		sub := c.document(doc, itemPath, depth)
+		if len(sub.Filters) == 1 && len(sub.Groups) == 0 {
+			group.Filters = append(group.Filters, sub.Filters[0])
+			continue
+		}
+		group.Groups = append(group.Groups, sub)
+	}
+
+	return group
+}
+
+// operators converts an operator document such as {"$gte": 18, "$lt": 65} on field to an AND
+// group.
+func (c *converter) operators(field string, ops map[string]any, path string, depth int) filter.FilterGroup {
+	group := filter.FilterGroup{Logic: "and"}
+	if depth > parse.MaxDepth {
+		c.groupError(path, fmt.Errorf("%w: nested deeper than %d levels", filter.ErrInvalidGroup, parse.MaxDepth))
+		return group
+	}
+	if len(ops) == 0 {
+		c.groupError(path, fmt.Errorf("%w: embedded document equality is not supported", filter.ErrUnsupportedOperator))
+		return group
+	}
+
+	for _, key := range sortedKeys(ops) {
+		value := ops[key]
+		keyPath := join(path, key)
+
+		switch {
+		case !strings.HasPrefix(key, "$"):
+			c.groupError(keyPath, fmt.Errorf("%w: embedded document equality is not supported, use the dotted field %q", filter.ErrUnsupportedOperator, field+"."+key))
+		case key == "$not":
+			sub, ok := value.(map[string]any)
+			if !ok {
+				c.groupError(keyPath, fmt.Errorf("%w: $not expects an operator document, got %T", filter.ErrInvalidGroup, value))
+				continue
+			}
+			negated := c.operators(field, sub, keyPath, depth+1)
+			negated.Not = true
+			group.Groups = append(group.Groups, negated)
+		case key == "$options":
+			if _, ok := ops["$regex"]; !ok {
+				c.groupError(keyPath, fmt.Errorf("%w: $options requires $regex", filter.ErrInvalidGroup))
+			}
+		case key == "$regex":
+			c.regex(&group, field, value, ops["$options"], keyPath)
+		default:
+			c.comparison(&group, field, key, value, keyPath)
+		}
+	}
+
+	return group
+}
+
+// comparison adds the filter for a single operator on field to group.
+func (c *converter) comparison(group *filter.FilterGroup, field, key string, value any, path string) {
+	fail := func(err error) {
+		c.errs = append(c.errs, &filter.FilterError{
+			Path:     path,
+			Index:    len(group.Filters),
+			Field:    field,
+			Operator: filter.Operator(key),
+			Err:      err,
+		})
+	}
+
+	f := filter.Filter{Field: field, Value: value}
+	switch key {
+	case "$exists":
+		exists, ok := value.(bool)
+		if !ok {
+			fail(fmt.Errorf("%w: $exists expects true or false, got %T", filter.ErrInvalidValue, value))
+			return
+		}
+		f.Operator, f.Value = filter.OpNull, nil
+		if exists {
+			f.Operator = filter.OpNnull
+		}
+	default:
+		op, ok := operators[key]
+		if !ok {
+			fail(fmt.Errorf("%w: mongo operator %s has no equivalent", filter.ErrUnsupportedOperator, key))
+			return
+		}
+		f.Operator = op
+
+		switch {
+		case value == nil && op == filter.OpEq:
+			f.Operator = filter.OpNull
+		case value == nil && op == filter.OpNe:
+			f.Operator = filter.OpNnull
+		case value == nil:
+			fail(fmt.Errorf("%w: %s cannot compare with null", filter.ErrInvalidValue, key))
+			return
+		case op == filter.OpIn || op == filter.OpNin || op == filter.OpHasAll:
+			if _, ok := value.([]any); !ok {
+				fail(fmt.Errorf("%w: %s expects an array, got %T", filter.ErrInvalidValue, key, value))
+				return
+			}
+		}
+	}
+
+	group.Filters = append(group.Filters, f)
+}
+
+// regex adds a regex filter, translating $options into RE2 inline flags.
+func (c *converter) regex(group *filter.FilterGroup, field string, value, options any, path string) {
+	fail := func(err error) {
+		c.errs = append(c.errs, &filter.FilterError{
+			Path:     path,
+			Index:    len(group.Filters),
+			Field:    field,
+			Operator: "$regex",
+			Err:      err,
+		})
+	}
+
+	pattern, ok := value.(string)
+	if !ok {
+		fail(fmt.Errorf("%w: $regex expects a string, got %T", filter.ErrInvalidValue, value))
+		return
+	}
+
+	if options != nil {
+		opts, ok := options.(string)
+		if !ok {
+			fail(fmt.Errorf("%w: $options expects a string, got %T", filter.ErrInvalidValue, options))
+			return
+		}
+		var flags string
+		for _, o := range opts {
+			flag, ok := regexOptions[o]
+			if !ok {
+				fail(fmt.Errorf("%w: regex option %q is not supported", filter.ErrUnsupportedOperator, o))
+				return
+			}
+			if !strings.Contains(flags, flag) {
+				flags += flag
+			}
+		}
+		if flags != "" {
+			pattern = "(?" + flags + ")" + pattern
+		}
+	}
+
+	group.Filters = append(group.Filters, filter.Filter{Field: field, Operator: filter.OpRegex, Value: pattern})
+}
@@ -0,0 +1,236 @@
+package mongo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/filter/mongo"
+)
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected filter.FilterGroup
+	}{
+		{
+			name:  "implicit equality and operators",
+			input: `{"age": {"$gte": 18, "$lt": 65}, "status": "active", "manager_id": null, "author.name": "Ada"}`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "age", Operator: filter.OpGte, Value: json.Number("18")},
+					{Field: "age", Operator: filter.OpLt, Value: json.Number("65")},
+					{Field: "author.name", Operator: filter.OpEq, Value: "Ada"},
+					{Field: "manager_id", Operator: filter.OpNull},
+					{Field: "status", Operator: filter.OpEq, Value: "active"},
+				},
+			},
+		},
+		{
+			name:  "combinators",
+			input: `{"age": {"$gte": 18}, "$or": [{"status": "a"}, {"tags": {"$in": ["x"]}}], "$nor": [{"role": "bot"}, {"banned": true}]}`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "age", Operator: filter.OpGte, Value: json.Number("18")},
+				},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "or",
+						Not:   true,
+						Filters: []filter.Filter{
+							{Field: "role", Operator: filter.OpEq, Value: "bot"},
+							{Field: "banned", Operator: filter.OpEq, Value: true},
+						},
+					},
+					{
+						Logic: "or",
+						Filters: []filter.Filter{
+							{Field: "status", Operator: filter.OpEq, Value: "a"},
+							{Field: "tags", Operator: filter.OpIn, Value: []any{"x"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "and with multi-field documents",
+			input: `{"$and": [{"a": 1, "b": 2}, {"c": {"$ne": null}}]}`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Groups: []filter.FilterGroup{
+					{
+						Logic:   "and",
+						Filters: []filter.Filter{{Field: "c", Operator: filter.OpNnull}},
+						Groups: []filter.FilterGroup{
+							{
+								Logic: "and",
+								Filters: []filter.Filter{
+									{Field: "a", Operator: filter.OpEq, Value: json.Number("1")},
+									{Field: "b", Operator: filter.OpEq, Value: json.Number("2")},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "exists, regex and not",
+			input: `{"deleted_at": {"$exists": false}, "email": {"$regex": "@acme\\.com$", "$options": "i"}, "name": {"$not": {"$regex": "^test"}}, "phone": {"$exists": true}}`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "deleted_at", Operator: filter.OpNull},
+					{Field: "email", Operator: filter.OpRegex, Value: `(?i)@acme\.com$`},
+					{Field: "phone", Operator: filter.OpNnull},
+				},
+				Groups: []filter.FilterGroup{
+					{Logic: "and", Not: true, Filters: []filter.Filter{{Field: "name", Operator: filter.OpRegex, Value: "^test"}}},
+				},
+			},
+		},
+		{
+			name:  "array operators",
+			input: `{"tags": {"$all": ["go", "sql"], "$size": 2}, "role": {"$nin": ["bot"]}}`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "role", Operator: filter.OpNin, Value: []any{"bot"}},
+					{Field: "tags", Operator: filter.OpHasAll, Value: []any{"go", "sql"}},
+					{Field: "tags", Operator: filter.OpLen, Value: json.Number("2")},
+				},
+			},
+		},
+		{
+			name:     "empty document matches everything",
+			input:    `{}`,
+			expected: filter.FilterGroup{Logic: "and"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := mongo.ParseJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(group, tt.expected) {
+				t.Errorf("\nexpected: %#v\ngot:      %#v", tt.expected, group)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Run("problems are reported with their location", func(t *testing.T) {
+		_, err := mongo.ParseJSON([]byte(`{
+			"$or": [{"tags": {"$elemMatch": {"$eq": "x"}}}, "oops"],
+			"$where": "this.a > 1",
+			"$nor": [],
+			"author": {"name": "Ada"},
+			"age": {"$gt": null},
+			"role": {"$in": "admin"},
+			"deleted_at": {"$exists": "no"},
+			"email": {"$regex": "x", "$options": "x"},
+			"name": {"$options": "i"}
+		}`))
+
+		var filterErrs filter.Errors
+		if !errors.As(err, &filterErrs) {
+			t.Fatalf("expected filter.Errors, got %T: %v", err, err)
+		}
+
+		got := make(map[string]error, len(filterErrs))
+		for _, e := range filterErrs {
+			got[e.Path] = e.Err
+		}
+		expected := map[string]error{
+			"$or[0].tags.$elemMatch": filter.ErrUnsupportedOperator,
+			"$or[1]":                 filter.ErrInvalidGroup,
+			"$where":                 filter.ErrUnsupportedOperator,
+			"$nor":                   filter.ErrInvalidGroup,
+			"author.name":            filter.ErrUnsupportedOperator,
+			"age.$gt":                filter.ErrInvalidValue,
+			"role.$in":               filter.ErrInvalidValue,
+			"deleted_at.$exists":     filter.ErrInvalidValue,
+			"email.$regex":           filter.ErrUnsupportedOperator,
+			"name.$options":          filter.ErrInvalidGroup,
+		}
+		if len(got) != len(expected) {
+			t.Fatalf("expected %d errors, got %d: %v", len(expected), len(got), err)
+		}
+		for path, sentinel := range expected {
+			if !errors.Is(got[path], sentinel) {
+				t.Errorf("%s: expected %v, got %v", path, sentinel, got[path])
+			}
+		}
+	})
+
+	t.Run("nesting is limited", func(t *testing.T) {
+		doc := map[string]any{"id": 1}
+		for range 100 {
+			doc = map[string]any{"$and": []any{doc}}
+		}
+		if _, err := mongo.Parse(doc); !errors.Is(err, filter.ErrInvalidGroup) {
+			t.Fatalf("expected ErrInvalidGroup, got %v", err)
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := mongo.ParseJSON([]byte(`[{"a": 1}]`))
+		if err == nil || !strings.HasPrefix(err.Error(), "mongo: decoding query") {
+			t.Fatalf("expected decoding error, got %v", err)
+		}
+	})
+}
+
+// MockPredicate represents a WHERE condition
+type MockPredicate string
+
+func TestParse_AppliesWithStructuredFilters(t *testing.T) {
+	op := func(field, op string) func(string) MockPredicate {
+		return func(v string) MockPredicate { return MockPredicate(fmt.Sprintf("%s %s %s", field, op, v)) }
+	}
+	join := func(sep string) func(...MockPredicate) MockPredicate {
+		return func(ps ...MockPredicate) MockPredicate {
+			strs := make([]string, len(ps))
+			for i, p := range ps {
+				strs[i] = string(p)
+			}
+			return MockPredicate("(" + strings.Join(strs, sep) + ")")
+		}
+	}
+	not := func(p MockPredicate) MockPredicate { return "NOT " + p }
+
+	builders := filter.BuildFilterMap(
+		filter.Combinators[MockPredicate]{Or: join(" OR "), And: join(" AND ")},
+		filter.StringField("status", filter.StringPredicates[MockPredicate]{Eq: op("status", "=")}),
+		filter.StringField("email", filter.StringPredicates[MockPredicate]{Regex: op("email", "~")}),
+	)
+	predicates := filter.PredicateBuilder[MockPredicate]{Or: join(" OR "), And: join(" AND "), Not: not}
+	cfg := filter.Config[[]MockPredicate, MockPredicate]{
+		Where: func(q []MockPredicate, p MockPredicate) []MockPredicate { return append(q, p) },
+	}
+
+	group, err := mongo.ParseJSON([]byte(`{"$nor": [{"status": "banned"}, {"email": {"$regex": "@test$", "$options": "i"}}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, err := filter.ApplyStructuredFiltersE(nil, cfg, group, builders, predicates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "NOT (status = banned OR email ~ (?i)@test$)"
+	if len(query) != 1 || string(query[0]) != expected {
+		t.Errorf("\nexpected: %s\ngot:      %v", expected, query)
+	}
+}
@@ -0,0 +1,88 @@
+package filter
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxPatternLength limits the length, in characters, of regex and LIKE patterns accepted by the
+// regex, nregex and like operators. Patterns come straight from request input, so keep this small.
+const MaxPatternLength = 256
+
+// ValidateRegex checks that a pattern submitted with the regex or nregex operator is safe to pass
+// to the database. The pattern must:
+//   - be at most MaxPatternLength characters long
+//   - compile as RE2 syntax, which rules out backreferences and lookaround
+//   - not nest unbounded repetitions such as (a+)+ or (a*)*, which backtracking engines
+//     evaluate in exponential time
+//
+// Failures wrap ErrInvalidValue. ApplyStructuredFilters skips filters with a rejected pattern.
+func ValidateRegex(pattern string) error {
+	if err := checkPatternLength(pattern); err != nil {
+		return err
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return fmt.Errorf("%w: invalid regex: %v", ErrInvalidValue, err)
+	}
+	if nestedRepeat(re, false) {
+		return fmt.Errorf("%w: regex %q nests unbounded repetitions", ErrInvalidValue, pattern)
+	}
+	return nil
+}
+
+// ValidateLike checks that a pattern submitted with the like operator is well formed. Patterns use
+// the SQL wildcards % (any sequence) and _ (any single character); a backslash escapes %, _ or
+// another backslash and may not appear anywhere else. Failures wrap ErrInvalidValue.
+func ValidateLike(pattern string) error {
+	if err := checkPatternLength(pattern); err != nil {
+		return err
+	}
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '\\' {
+			continue
+		}
+		if i+1 == len(pattern) {
+			return fmt.Errorf("%w: like pattern %q ends with an escape character", ErrInvalidValue, pattern)
+		}
+		switch pattern[i+1] {
+		case '%', '_', '\\':
+			i++
+		default:
+			return fmt.Errorf("%w: like pattern %q escapes %q", ErrInvalidValue, pattern, pattern[i+1])
+		}
+	}
+	return nil
+}
+
+func checkPatternLength(pattern string) error {
+	if n := utf8.RuneCountInString(pattern); n > MaxPatternLength {
+		return fmt.Errorf("%w: pattern is %d characters, maximum is %d", ErrInvalidValue, n, MaxPatternLength)
+	}
+	return nil
+}
+
+// nestedRepeat reports whether re contains an unbounded repetition inside another one.
+func nestedRepeat(re *syntax.Regexp, inRepeat bool) bool {
+	unbounded := re.Op == syntax.OpStar || re.Op == syntax.OpPlus ||
+		re.Op == syntax.OpRepeat && re.Max == -1
+	if unbounded && inRepeat {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if nestedRepeat(sub, inRepeat || unbounded) {
+			return true
+		}
+	}
+	return false
+}
+
+// EscapeLike escapes the LIKE wildcards % and _ and the backslash in s, so it matches literally
+// inside a pattern accepted by ValidateLike.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -0,0 +1,421 @@
+// Package qs parses Strapi/qs style bracket query strings into dewey filter groups.
+//
+// Clients filter with plain URL parameters instead of a JSON-encoded filters param:
+//
+//	?filters[email][$containsi]=john
+//	&filters[$or][0][age][$gte]=18
+//	&filters[$or][1][role][$in][0]=admin&filters[$or][1][role][$in][1]=owner
+//
+// Keys inside an object are combined with AND, $or and $and take an array (or object) of
+// conditions, and $not negates the conditions nested under it. Nested field keys such as
+// filters[author][name][$eq]=ada become the dotted field "author.name".
+//
+// Example:
+//
+//	group, err := qs.Parse(r.URL.Query())
+//	if err != nil {
+//	    return http.StatusBadRequest, err
+//	}
+//	query, err = filter.ApplyStructuredFiltersE(query, cfg, group, userFilterBuilders, predicates)
+package qs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tone-labs/dewey/filter"
+)
+
+var (
+	// ErrInvalidKey is reported for malformed bracket keys and conflicting parameters
+	ErrInvalidKey = errors.New("invalid filter key")
+
+	// ErrLimitExceeded is reported when a query exceeds one of the Options limits
+	ErrLimitExceeded = errors.New("filter limit exceeded")
+)
+
+// Default limits used when the corresponding Options field is zero.
+const (
+	DefaultMaxDepth   = 10
+	DefaultMaxFilters = 100
+	DefaultMaxValues  = 100
+)
+
+// Options configures the parser. The zero value uses the "filters" parameter and the
+// default limits.
+type Options struct {
+	// Key is the root query parameter (default "filters")
+	Key string
+
+	// MaxDepth limits the number of bracket segments in a key, e.g.
+	// filters[$or][0][age][$gte] has a depth of 4
+	MaxDepth int
+
+	// MaxFilters limits the total number of field conditions
+	MaxFilters int
+
+	// MaxValues limits the number of elements in an array value or $and/$or list
+	MaxValues int
+}
+
+// operator describes how a Strapi operator maps onto dewey.
+type operator struct {
+	op     filter.Operator
+	negate bool // wrap the filter in a negated group
+}
+
+// operators maps the Strapi field operators. Strapi's unsuffixed string operators are
+// case-sensitive and the "i"-suffixed ones are case-insensitive.
+var operators = map[string]operator{
+	"$eq":           {op: filter.OpEq},
+	"$ne":           {op: filter.OpNe},
+	"$lt":           {op: filter.OpLt},
+	"$lte":          {op: filter.OpLte},
+	"$gt":           {op: filter.OpGt},
+	"$gte":          {op: filter.OpGte},
+	"$in":           {op: filter.OpIn},
+	"$notIn":        {op: filter.OpNin},
+	"$contains":     {op: filter.OpContainsCS},
+	"$notContains":  {op: filter.OpContainsCS, negate: true},
+	"$containsi":    {op: filter.OpContains},
+	"$notContainsi": {op: filter.OpNcontains},
+	"$startsWith":   {op: filter.OpStartsWithCS},
+	"$startsWithi":  {op: filter.OpStartsWith},
+	"$endsWith":     {op: filter.OpEndsWithCS},
+	"$endsWithi":    {op: filter.OpEndsWith},
+	"$between":      {op: filter.OpBetween},
+	"$null":         {op: filter.OpNull},
+	"$notNull":      {op: filter.OpNnull},
+}
+
+// Parse builds a filter group from the "filters" parameters in values using the default limits.
+func Parse(values url.Values) (filter.FilterGroup, error) {
+	return Options{}.Parse(values)
+}
+
+// Parse builds a filter group from the bracket parameters in values. Parameters that don't
+// start with the root key are ignored, so the same url.Values can carry sort and pagination
+// parameters.
+func (o Options) Parse(values url.Values) (filter.FilterGroup, error) {
+	o = o.withDefaults()
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	root := &node{}
+	for _, key := range keys {
+		segments, ok, err := o.segments(key)
+		if err != nil {
+			return filter.FilterGroup{}, err
+		}
+		if !ok {
+			continue
+		}
+		if err := root.insert(segments, values[key]); err != nil {
+			return filter.FilterGroup{}, fmt.Errorf("qs: %s: %w", key, err)
+		}
+	}
+
+	c := converter{opts: o}
+	group, err := c.object(root, nil, o.Key)
+	if err != nil {
+		return filter.FilterGroup{}, err
+	}
+	return group, nil
+}
+
+func (o Options) withDefaults() Options {
+	if o.Key == "" {
+		o.Key = "filters"
+	}
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultMaxDepth
+	}
+	if o.MaxFilters <= 0 {
+		o.MaxFilters = DefaultMaxFilters
+	}
+	if o.MaxValues <= 0 {
+		o.MaxValues = DefaultMaxValues
+	}
+	return o
+}
+
+// segments splits a key such as "filters[$or][0][age]" into ["$or", "0", "age"].
+// ok is false for keys that don't belong to the root parameter.
+func (o Options) segments(key string) (segments []string, ok bool, err error) {
+	if key == o.Key {
+		return nil, false, fmt.Errorf("qs: %s: %w: expected bracket syntax such as %s[field][$eq]=value", key, ErrInvalidKey, o.Key)
+	}
+	rest, ok := strings.CutPrefix(key, o.Key+"[")
+	if !ok {
+		return nil, false, nil
+	}
+	rest = "[" + rest
+
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 || strings.ContainsAny(rest[1:end], "[") {
+			return nil, false, fmt.Errorf("qs: %s: %w: unbalanced brackets", key, ErrInvalidKey)
+		}
+		segments = append(segments, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	// A trailing [] only marks the parameter as a list; repeated values are already collected.
+	if segments[len(segments)-1] == "" {
+		segments = segments[:len(segments)-1]
+	}
+	if len(segments) == 0 || slices.Contains(segments, "") {
+		return nil, false, fmt.Errorf("qs: %s: %w: empty brackets are only allowed at the end", key, ErrInvalidKey)
+	}
+	if len(segments) > o.MaxDepth {
+		return nil, false, fmt.Errorf("qs: %s: %w: depth %d exceeds %d", key, ErrLimitExceeded, len(segments), o.MaxDepth)
+	}
+	return segments, true, nil
+}
+
+// node is one level of the bracket tree: either a leaf holding values or an object/array
+// holding children.
+type node struct {
+	values   []string
+	children map[string]*node
+}
+
+func (n *node) insert(segments []string, values []string) error {
+	if len(segments) == 0 {
+		if n.children != nil {
+			return fmt.Errorf("%w: value conflicts with nested parameters", ErrInvalidKey)
+		}
+		n.values = append(n.values, values...)
+		return nil
+	}
+	if n.values != nil {
+		return fmt.Errorf("%w: nested parameters conflict with a value", ErrInvalidKey)
+	}
+	if n.children == nil {
+		n.children = make(map[string]*node)
+	}
+	child, ok := n.children[segments[0]]
+	if !ok {
+		child = &node{}
+		n.children[segments[0]] = child
+	}
+	return child.insert(segments[1:], values)
+}
+
+// isArray reports whether every child key is an index, as in [0], [1].
+func (n *node) isArray() bool {
+	if len(n.children) == 0 {
+		return false
+	}
+	for key := range n.children {
+		if _, err := strconv.ParseUint(key, 10, 31); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// elements returns the children of an array node in index order. Sparse indices are
+// compacted, so [0] and [5] yield two elements.
+func (n *node) elements() []*node {
+	type indexed struct {
+		index int
+		node  *node
+	}
+	items := make([]indexed, 0, len(n.children))
+	for key, child := range n.children {
+		i, _ := strconv.Atoi(key)
+		items = append(items, indexed{i, child})
+	}
+	slices.SortFunc(items, func(a, b indexed) int { return a.index - b.index })
+
+	nodes := make([]*node, len(items))
+	for i, item := range items {
+		nodes[i] = item.node
+	}
+	return nodes
+}
+
+// sortedKeys returns the child keys of an object node in a deterministic order.
+func (n *node) sortedKeys() []string {
+	keys := make([]string, 0, len(n.children))
+	for key := range n.children {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+type converter struct {
+	opts    Options
+	filters int
+}
+
+// object converts an object node to an AND group. field holds the enclosing field path
+// for nested relation keys.
+func (c *converter) object(n *node, field []string, path string) (filter.FilterGroup, error) {
+	group := filter.FilterGroup{Logic: "and"}
+
+	if n.values != nil {
+		// filters[email]=john is shorthand for filters[email][$eq]=john
+		f, err := c.leaf("$eq", n, field, path)
+		if err != nil {
+			return group, err
+		}
+		group.Filters = append(group.Filters, f)
+		return group, nil
+	}
+
+	for _, key := range n.sortedKeys() {
+		child := n.children[key]
+		childPath := path + "[" + key + "]"
+
+		switch {
+		case key == "$and" || key == "$or":
+			sub, err := c.list(strings.TrimPrefix(key, "$"), child, field, childPath)
+			if err != nil {
+				return group, err
+			}
+			group.Groups = append(group.Groups, sub)
+		case key == "$not":
+			sub, err := c.object(child, field, childPath)
+			if err != nil {
+				return group, err
+			}
+			sub.Not = true
+			group.Groups = append(group.Groups, sub)
+		case strings.HasPrefix(key, "$"):
+			m, ok := operators[key]
+			if !ok {
+				return group, fmt.Errorf("qs: %s: %w: %s", childPath, filter.ErrUnsupportedOperator, key)
+			}
+			f, err := c.leaf(key, child, field, childPath)
+			if err != nil {
+				return group, err
+			}
+			if m.negate {
+				group.Groups = append(group.Groups, filter.FilterGroup{
+					Filters: []filter.Filter{f},
+					Logic:   "and",
+					Not:     true,
+				})
+				continue
+			}
+			group.Filters = append(group.Filters, f)
+		default:
+			sub, err := c.object(child, append(slices.Clip(field), key), childPath)
+			if err != nil {
+				return group, err
+			}
+			group.Filters = append(group.Filters, sub.Filters...)
+			group.Groups = append(group.Groups, sub.Groups...)
+		}
+	}
+
+	return group, nil
+}
+
+// list converts the conditions of $and/$or into a group with the given logic.
+// Conditions consisting of a single filter are inlined into the group's filters.
+func (c *converter) list(logic string, n *node, field []string, path string) (filter.FilterGroup, error) {
+	group := filter.FilterGroup{Logic: logic}
+
+	elements := []*node{n}
+	if n.isArray() {
+		elements = n.elements()
+	}
+	if len(elements) > c.opts.MaxValues {
+		return group, fmt.Errorf("qs: %s: %w: %d conditions exceed %d", path, ErrLimitExceeded, len(elements), c.opts.MaxValues)
+	}
+
+	for i, element := range elements {
+		if element.values != nil {
+			return group, fmt.Errorf("qs: %s[%d]: %w: expected conditions, got a value", path, i, ErrInvalidKey)
+		}
+		sub, err := c.object(element, field, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return group, err
+		}
+		if len(sub.Filters) == 1 && len(sub.Groups) == 0 {
+			group.Filters = append(group.Filters, sub.Filters[0])
+			continue
+		}
+		group.Groups = append(group.Groups, sub)
+	}
+
+	return group, nil
+}
+
+// leaf builds the filter for a field operator.
+func (c *converter) leaf(key string, n *node, field []string, path string) (filter.Filter, error) {
+	if len(field) == 0 {
+		return filter.Filter{}, fmt.Errorf("qs: %s: %w: operator %s has no field", path, ErrInvalidKey, key)
+	}
+	c.filters++
+	if c.filters > c.opts.MaxFilters {
+		return filter.Filter{}, fmt.Errorf("qs: %s: %w: more than %d filters", path, ErrLimitExceeded, c.opts.MaxFilters)
+	}
+
+	value, err := c.value(n, path)
+	if err != nil {
+		return filter.Filter{}, err
+	}
+
+	f := filter.Filter{Field: strings.Join(field, "."), Operator: operators[key].op, Value: value}
+
+	// $null=false and $notNull=false invert the check
+	if key == "$null" || key == "$notNull" {
+		s, _ := value.(string)
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return filter.Filter{}, fmt.Errorf("qs: %s: %w: expected true or false", path, filter.ErrInvalidValue)
+		}
+		if b == (key == "$null") {
+			f.Operator = filter.OpNull
+		} else {
+			f.Operator = filter.OpNnull
+		}
+		f.Value = nil
+	}
+
+	return f, nil
+}
+
+// value converts a leaf or array node to a filter value: a string for a single value,
+// otherwise []any of strings.
+func (c *converter) value(n *node, path string) (any, error) {
+	var values []string
+	switch {
+	case n.children == nil:
+		values = n.values
+	case n.isArray():
+		for i, element := range n.elements() {
+			if element.children != nil || len(element.values) != 1 {
+				return nil, fmt.Errorf("qs: %s[%d]: %w: expected a single value", path, i, ErrInvalidKey)
+			}
+			values = append(values, element.values[0])
+		}
+	default:
+		return nil, fmt.Errorf("qs: %s: %w: expected a value", path, ErrInvalidKey)
+	}
+
+	if len(values) > c.opts.MaxValues {
+		return nil, fmt.Errorf("qs: %s: %w: %d values exceed %d", path, ErrLimitExceeded, len(values), c.opts.MaxValues)
+	}
+	if len(values) == 1 && n.children == nil {
+		return values[0], nil
+	}
+	list := make([]any, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	return list, nil
+}
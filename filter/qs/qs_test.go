@@ -0,0 +1,271 @@
+package qs_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/filter/qs"
+)
+
+func mustQuery(t *testing.T, raw string) url.Values {
+	t.Helper()
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		t.Fatalf("invalid test query %q: %v", raw, err)
+	}
+	return values
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected filter.FilterGroup
+	}{
+		{
+			name:  "field operators are combined with and",
+			query: "filters[email][$containsi]=john&filters[age][$gte]=18&sort=name&page=2",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "age", Operator: filter.OpGte, Value: "18"},
+					{Field: "email", Operator: filter.OpContains, Value: "john"},
+				},
+			},
+		},
+		{
+			name:  "implicit eq",
+			query: "filters[status]=published",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "status", Operator: filter.OpEq, Value: "published"}},
+			},
+		},
+		{
+			name:  "or array",
+			query: "filters[$or][0][age][$gte]=18&filters[$or][1][role][$eq]=admin",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "or",
+						Filters: []filter.Filter{
+							{Field: "age", Operator: filter.OpGte, Value: "18"},
+							{Field: "role", Operator: filter.OpEq, Value: "admin"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "multi-condition elements stay grouped",
+			query: "filters[$or][0][age][$gte]=18&filters[$or][0][age][$lt]=65&filters[$or][1][vip][$eq]=true",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Groups: []filter.FilterGroup{
+					{
+						Logic:   "or",
+						Filters: []filter.Filter{{Field: "vip", Operator: filter.OpEq, Value: "true"}},
+						Groups: []filter.FilterGroup{
+							{
+								Logic: "and",
+								Filters: []filter.Filter{
+									{Field: "age", Operator: filter.OpGte, Value: "18"},
+									{Field: "age", Operator: filter.OpLt, Value: "65"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "not group",
+			query: "filters[$not][$or][0][role][$eq]=admin&filters[$not][$or][1][role][$eq]=owner",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "and",
+						Not:   true,
+						Groups: []filter.FilterGroup{
+							{
+								Logic: "or",
+								Filters: []filter.Filter{
+									{Field: "role", Operator: filter.OpEq, Value: "admin"},
+									{Field: "role", Operator: filter.OpEq, Value: "owner"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "field-level not",
+			query: "filters[title][$not][$contains]=draft",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Groups: []filter.FilterGroup{
+					{
+						Logic:   "and",
+						Not:     true,
+						Filters: []filter.Filter{{Field: "title", Operator: filter.OpContainsCS, Value: "draft"}},
+					},
+				},
+			},
+		},
+		{
+			name:  "indexed array values",
+			query: "filters[role][$in][1]=owner&filters[role][$in][0]=admin",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "role", Operator: filter.OpIn, Value: []any{"admin", "owner"}}},
+			},
+		},
+		{
+			name:  "repeated array values",
+			query: "filters[role][$notIn][]=admin&filters[role][$notIn][]=owner",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "role", Operator: filter.OpNin, Value: []any{"admin", "owner"}}},
+			},
+		},
+		{
+			name:  "between",
+			query: "filters[price][$between][0]=10&filters[price][$between][1]=20",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "price", Operator: filter.OpBetween, Value: []any{"10", "20"}}},
+			},
+		},
+		{
+			name:  "nested relation fields",
+			query: "filters[author][name][$eq]=ada",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "author.name", Operator: filter.OpEq, Value: "ada"}},
+			},
+		},
+		{
+			name:  "null checks",
+			query: "filters[deleted_at][$null]=true&filters[archived_at][$null]=false&filters[email][$notNull]=true",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "archived_at", Operator: filter.OpNnull},
+					{Field: "deleted_at", Operator: filter.OpNull},
+					{Field: "email", Operator: filter.OpNnull},
+				},
+			},
+		},
+		{
+			name:  "case-sensitive negation becomes a not group",
+			query: "filters[code][$notContains]=TMP",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Groups: []filter.FilterGroup{
+					{
+						Logic:   "and",
+						Not:     true,
+						Filters: []filter.Filter{{Field: "code", Operator: filter.OpContainsCS, Value: "TMP"}},
+					},
+				},
+			},
+		},
+		{
+			name:     "no filters",
+			query:    "sort=name",
+			expected: filter.FilterGroup{Logic: "and"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := qs.Parse(mustQuery(t, tt.query))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(group, tt.expected) {
+				t.Errorf("\nexpected: %#v\ngot:      %#v", tt.expected, group)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  qs.Options
+		query string
+		err   error
+	}{
+		{name: "unknown operator", query: "filters[email][$like]=a", err: filter.ErrUnsupportedOperator},
+		{name: "operator without field", query: "filters[$eq]=a", err: qs.ErrInvalidKey},
+		{name: "unbalanced brackets", query: "filters[email[$eq]=a", err: qs.ErrInvalidKey},
+		{name: "empty brackets in the middle", query: "filters[][email]=a", err: qs.ErrInvalidKey},
+		{name: "json filters param", query: `filters={"field":"email"}`, err: qs.ErrInvalidKey},
+		{name: "value conflicts with nested keys", query: "filters[email]=a&filters[email][$eq]=b", err: qs.ErrInvalidKey},
+		{name: "or with a plain value", query: "filters[$or]=a", err: qs.ErrInvalidKey},
+		{name: "invalid null flag", query: "filters[email][$null]=maybe", err: filter.ErrInvalidValue},
+		{
+			name:  "depth limit",
+			opts:  qs.Options{MaxDepth: 3},
+			query: "filters[$or][0][age][$gte]=18",
+			err:   qs.ErrLimitExceeded,
+		},
+		{
+			name:  "filter limit",
+			opts:  qs.Options{MaxFilters: 1},
+			query: "filters[a][$eq]=1&filters[b][$eq]=2",
+			err:   qs.ErrLimitExceeded,
+		},
+		{
+			name:  "value limit",
+			opts:  qs.Options{MaxValues: 2},
+			query: "filters[id][$in][0]=1&filters[id][$in][1]=2&filters[id][$in][2]=3",
+			err:   qs.ErrLimitExceeded,
+		},
+		{
+			name:  "condition limit",
+			opts:  qs.Options{MaxValues: 1},
+			query: "filters[$or][0][a]=1&filters[$or][1][b]=2",
+			err:   qs.ErrLimitExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.opts.Parse(mustQuery(t, tt.query))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestOptions_Key(t *testing.T) {
+	group, err := qs.Options{Key: "where"}.Parse(mustQuery(t, "where[email][$eq]=a&filters[email][$eq]=b"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []filter.Filter{{Field: "email", Operator: filter.OpEq, Value: "a"}}
+	if !reflect.DeepEqual(group.Filters, expected) {
+		t.Errorf("expected %v, got %v", expected, group.Filters)
+	}
+}
+
+func TestParse_SparseIndicesAreCompacted(t *testing.T) {
+	group, err := qs.Parse(mustQuery(t, "filters[id][$in][2000000000]=b&filters[id][$in][0]=a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := group.Filters[0].Value; !reflect.DeepEqual(got, []any{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", got)
+	}
+}
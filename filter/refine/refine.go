@@ -0,0 +1,196 @@
+// Package refine decodes Refine.js CrudFilters into dewey filter groups.
+//
+// Refine sends filters as a JSON array mixing two shapes:
+//
+//	// LogicalFilter
+//	{"field": "status", "operator": "eq", "value": "published"}
+//
+//	// ConditionalFilter
+//	{"operator": "or", "value": [{"field": "views", "operator": "gt", "value": 100}, ...]}
+//
+// Parse turns that array into a filter.FilterGroup that can be passed straight to
+// filter.ApplyStructuredFiltersE:
+//
+//	group, err := refine.Parse([]byte(r.URL.Query().Get("filters")))
+//	if err != nil {
+//	    return http.StatusBadRequest, err
+//	}
+//	query, err = filter.ApplyStructuredFiltersE(query, cfg, group, userFilterBuilders, predicates)
+package refine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tone-labs/dewey/filter"
+)
+
+// CrudFilter is a single Refine filter: either a LogicalFilter (Field, Operator, Value) or a
+// ConditionalFilter ("and"/"or" Operator with nested Filters).
+type CrudFilter struct {
+	// Field is the filtered field of a LogicalFilter
+	Field string `json:"field,omitempty"`
+
+	// Operator is a Refine CrudOperator, e.g. "eq", "ncontains" or "or"
+	Operator string `json:"operator"`
+
+	// Value is the value of a LogicalFilter
+	Value any `json:"-"`
+
+	// Filters holds the value of a ConditionalFilter
+	Filters []CrudFilter `json:"-"`
+
+	// Key optionally identifies a ConditionalFilter; it is not used when converting
+	Key string `json:"key,omitempty"`
+}
+
+// IsConditional reports whether the filter is a ConditionalFilter ("and"/"or").
+func (f CrudFilter) IsConditional() bool {
+	return f.Operator == "and" || f.Operator == "or"
+}
+
+// UnmarshalJSON decodes either filter shape, reading a ConditionalFilter's value as nested filters.
+func (f *CrudFilter) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Field    string          `json:"field"`
+		Operator string          `json:"operator"`
+		Value    json.RawMessage `json:"value"`
+		Key      string          `json:"key"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*f = CrudFilter{Field: raw.Field, Operator: raw.Operator, Key: raw.Key}
+	if len(raw.Value) == 0 {
+		return nil
+	}
+	if f.IsConditional() {
+		return json.Unmarshal(raw.Value, &f.Filters)
+	}
+	return json.Unmarshal(raw.Value, &f.Value)
+}
+
+// MarshalJSON encodes the filter in Refine's wire format.
+func (f CrudFilter) MarshalJSON() ([]byte, error) {
+	if f.IsConditional() {
+		filters := f.Filters
+		if filters == nil {
+			filters = []CrudFilter{}
+		}
+		return json.Marshal(struct {
+			Key      string       `json:"key,omitempty"`
+			Operator string       `json:"operator"`
+			Value    []CrudFilter `json:"value"`
+		}{f.Key, f.Operator, filters})
+	}
+	return json.Marshal(struct {
+		Field    string `json:"field"`
+		Operator string `json:"operator"`
+		Value    any    `json:"value"`
+	}{f.Field, f.Operator, f.Value})
+}
+
+// operator describes how a Refine operator maps onto dewey.
+type operator struct {
+	op     filter.Operator
+	negate bool // wrap the filter in a negated group
+}
+
+// operators maps every Refine CrudOperator except the "and"/"or" conditionals.
+// Refine operators without a direct dewey counterpart are expressed as negated groups.
+var operators = map[string]operator{
+	"eq":           {op: filter.OpEq},
+	"ne":           {op: filter.OpNe},
+	"lt":           {op: filter.OpLt},
+	"gt":           {op: filter.OpGt},
+	"lte":          {op: filter.OpLte},
+	"gte":          {op: filter.OpGte},
+	"in":           {op: filter.OpIn},
+	"nin":          {op: filter.OpNin},
+	"ina":          {op: filter.OpHasAll},
+	"nina":         {op: filter.OpHasAll, negate: true},
+	"contains":     {op: filter.OpContains},
+	"ncontains":    {op: filter.OpNcontains},
+	"containss":    {op: filter.OpContainsCS},
+	"ncontainss":   {op: filter.OpContainsCS, negate: true},
+	"between":      {op: filter.OpBetween},
+	"nbetween":     {op: filter.OpNbetween},
+	"null":         {op: filter.OpNull},
+	"nnull":        {op: filter.OpNnull},
+	"startswith":   {op: filter.OpStartsWith},
+	"nstartswith":  {op: filter.OpNstartsWith},
+	"startswiths":  {op: filter.OpStartsWithCS},
+	"nstartswiths": {op: filter.OpStartsWithCS, negate: true},
+	"endswith":     {op: filter.OpEndsWith},
+	"nendswith":    {op: filter.OpNendsWith},
+	"endswiths":    {op: filter.OpEndsWithCS},
+	"nendswiths":   {op: filter.OpEndsWithCS, negate: true},
+}
+
+// Operator returns the dewey operator for a Refine operator. negate reports whether the
+// result must be wrapped in a negated group (e.g. "ncontainss" is NOT containss).
+func Operator(refineOp string) (op filter.Operator, negate bool, ok bool) {
+	m, ok := operators[refineOp]
+	return m.op, m.negate, ok
+}
+
+// Parse decodes a JSON array of Refine CrudFilters and converts it with Convert.
+func Parse(data []byte) (filter.FilterGroup, error) {
+	var filters []CrudFilter
+	if err := json.Unmarshal(data, &filters); err != nil {
+		return filter.FilterGroup{}, fmt.Errorf("refine: decoding filters: %w", err)
+	}
+	return Convert(filters)
+}
+
+// Convert converts Refine CrudFilters into a filter group. The top-level filters are combined
+// with AND, as Refine does. Operators with no dewey equivalent are reported as a filter.Errors
+// whose entries wrap filter.ErrUnsupportedOperator; paths such as "[1].value[0]" locate them
+// in the Refine input.
+func Convert(filters []CrudFilter) (filter.FilterGroup, error) {
+	var errs filter.Errors
+	group := convertGroup("and", filters, "", &errs)
+	if len(errs) > 0 {
+		return filter.FilterGroup{}, errs
+	}
+	return group, nil
+}
+
+func convertGroup(logic string, filters []CrudFilter, path string, errs *filter.Errors) filter.FilterGroup {
+	group := filter.FilterGroup{Logic: logic}
+
+	for i, f := range filters {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+
+		if f.IsConditional() {
+			group.Groups = append(group.Groups, convertGroup(f.Operator, f.Filters, itemPath+".value", errs))
+			continue
+		}
+
+		m, ok := operators[f.Operator]
+		if !ok {
+			*errs = append(*errs, &filter.FilterError{
+				Path:     itemPath,
+				Index:    i,
+				Field:    f.Field,
+				Operator: filter.Operator(f.Operator),
+				Err:      fmt.Errorf("%w: refine operator %q has no equivalent", filter.ErrUnsupportedOperator, f.Operator),
+			})
+			continue
+		}
+
+		converted := filter.Filter{Field: f.Field, Operator: m.op, Value: f.Value}
+		if m.negate {
+			group.Groups = append(group.Groups, filter.FilterGroup{
+				Filters: []filter.Filter{converted},
+				Logic:   "and",
+				Not:     true,
+			})
+			continue
+		}
+		group.Filters = append(group.Filters, converted)
+	}
+
+	return group
+}
@@ -0,0 +1,242 @@
+package refine_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/filter/refine"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected filter.FilterGroup
+	}{
+		{
+			name:  "logical filters are combined with and",
+			input: `[{"field":"status","operator":"eq","value":"published"},{"field":"views","operator":"gte","value":100}]`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "status", Operator: filter.OpEq, Value: "published"},
+					{Field: "views", Operator: filter.OpGte, Value: float64(100)},
+				},
+			},
+		},
+		{
+			name: "conditional filters become nested groups",
+			input: `[
+				{"field":"status","operator":"eq","value":"published"},
+				{"key":"search","operator":"or","value":[
+					{"field":"title","operator":"contains","value":"go"},
+					{"operator":"and","value":[
+						{"field":"tags","operator":"ina","value":["go","sql"]},
+						{"field":"deleted_at","operator":"null","value":true}
+					]}
+				]}
+			]`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "status", Operator: filter.OpEq, Value: "published"},
+				},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "or",
+						Filters: []filter.Filter{
+							{Field: "title", Operator: filter.OpContains, Value: "go"},
+						},
+						Groups: []filter.FilterGroup{
+							{
+								Logic: "and",
+								Filters: []filter.Filter{
+									{Field: "tags", Operator: filter.OpHasAll, Value: []any{"go", "sql"}},
+									{Field: "deleted_at", Operator: filter.OpNull, Value: true},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "operators without a direct equivalent are negated groups",
+			input: `[{"field":"code","operator":"ncontainss","value":"TMP"},{"field":"tags","operator":"nina","value":["spam"]}]`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Groups: []filter.FilterGroup{
+					{
+						Logic:   "and",
+						Not:     true,
+						Filters: []filter.Filter{{Field: "code", Operator: filter.OpContainsCS, Value: "TMP"}},
+					},
+					{
+						Logic:   "and",
+						Not:     true,
+						Filters: []filter.Filter{{Field: "tags", Operator: filter.OpHasAll, Value: []any{"spam"}}},
+					},
+				},
+			},
+		},
+		{
+			name:  "range operators keep their array value",
+			input: `[{"field":"price","operator":"between","value":[10,20]}]`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "price", Operator: filter.OpBetween, Value: []any{float64(10), float64(20)}},
+				},
+			},
+		},
+		{
+			name:     "empty filters",
+			input:    `[]`,
+			expected: filter.FilterGroup{Logic: "and"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := refine.Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(group, tt.expected) {
+				t.Errorf("\nexpected: %#v\ngot:      %#v", tt.expected, group)
+			}
+		})
+	}
+}
+
+func TestOperator(t *testing.T) {
+	refineOperators := []string{
+		"eq", "ne", "lt", "gt", "lte", "gte", "in", "nin", "ina", "nina",
+		"contains", "ncontains", "containss", "ncontainss", "between", "nbetween", "null", "nnull",
+		"startswith", "nstartswith", "startswiths", "nstartswiths",
+		"endswith", "nendswith", "endswiths", "nendswiths",
+	}
+	for _, op := range refineOperators {
+		if _, _, ok := refine.Operator(op); !ok {
+			t.Errorf("refine operator %q is not mapped", op)
+		}
+	}
+
+	op, negate, ok := refine.Operator("nendswiths")
+	if !ok || op != filter.OpEndsWithCS || !negate {
+		t.Errorf("expected nendswiths to map to negated endswiths, got %q, %t, %t", op, negate, ok)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Run("unknown operators are reported with their location", func(t *testing.T) {
+		_, err := refine.Parse([]byte(`[
+			{"field":"status","operator":"eq","value":"published"},
+			{"operator":"or","value":[
+				{"field":"title","operator":"fuzzy","value":"go"}
+			]},
+			{"field":"views","operator":"around","value":5}
+		]`))
+
+		if !errors.Is(err, filter.ErrUnsupportedOperator) {
+			t.Fatalf("expected ErrUnsupportedOperator, got %v", err)
+		}
+
+		var filterErrs filter.Errors
+		if !errors.As(err, &filterErrs) {
+			t.Fatalf("expected filter.Errors, got %T", err)
+		}
+		if len(filterErrs) != 2 {
+			t.Fatalf("expected 2 errors, got %d: %v", len(filterErrs), err)
+		}
+		if filterErrs[0].Path != "[1].value[0]" || filterErrs[0].Field != "title" || filterErrs[0].Operator != "fuzzy" {
+			t.Errorf("unexpected first error: %+v", filterErrs[0])
+		}
+		if filterErrs[1].Path != "[2]" || filterErrs[1].Field != "views" {
+			t.Errorf("unexpected second error: %+v", filterErrs[1])
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := refine.Parse([]byte(`{"field":"status"}`))
+		if err == nil || !strings.HasPrefix(err.Error(), "refine: decoding filters") {
+			t.Fatalf("expected decoding error, got %v", err)
+		}
+	})
+}
+
+func TestCrudFilter_JSONRoundTrip(t *testing.T) {
+	input := `[{"field":"status","operator":"eq","value":"published"},` +
+		`{"key":"k","operator":"or","value":[{"field":"views","operator":"gt","value":100}]}]`
+
+	var filters []refine.CrudFilter
+	if err := json.Unmarshal([]byte(input), &filters); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !filters[1].IsConditional() || len(filters[1].Filters) != 1 || filters[1].Key != "k" {
+		t.Fatalf("unexpected conditional filter: %+v", filters[1])
+	}
+
+	output, err := json.Marshal(filters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(output) != input {
+		t.Errorf("\nexpected: %s\ngot:      %s", input, output)
+	}
+}
+
+// MockPredicate represents a WHERE condition
+type MockPredicate string
+
+func TestParse_AppliesWithStructuredFilters(t *testing.T) {
+	op := func(field, op string) func(string) MockPredicate {
+		return func(v string) MockPredicate { return MockPredicate(fmt.Sprintf("%s %s %s", field, op, v)) }
+	}
+	join := func(sep string) func(...MockPredicate) MockPredicate {
+		return func(ps ...MockPredicate) MockPredicate {
+			strs := make([]string, len(ps))
+			for i, p := range ps {
+				strs[i] = string(p)
+			}
+			return MockPredicate("(" + strings.Join(strs, sep) + ")")
+		}
+	}
+	not := func(p MockPredicate) MockPredicate { return "NOT " + p }
+
+	builders := filter.BuildFilterMap(
+		filter.Combinators[MockPredicate]{Or: join(" OR "), And: join(" AND ")},
+		filter.StringField("email", filter.StringPredicates[MockPredicate]{
+			Eq:                    op("email", "="),
+			Contains:              op("email", "ILIKE"),
+			ContainsCaseSensitive: op("email", "LIKE"),
+		}),
+	)
+	predicates := filter.PredicateBuilder[MockPredicate]{Or: join(" OR "), And: join(" AND "), Not: not}
+	cfg := filter.Config[[]MockPredicate, MockPredicate]{
+		Where: func(q []MockPredicate, p MockPredicate) []MockPredicate { return append(q, p) },
+	}
+
+	group, err := refine.Parse([]byte(`[
+		{"field":"email","operator":"ncontains","value":"@test"},
+		{"field":"email","operator":"ncontainss","value":"Bot"}
+	]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, err := filter.ApplyStructuredFiltersE(nil, cfg, group, builders, predicates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "(NOT email ILIKE @test AND NOT email LIKE Bot)"
+	if len(query) != 1 || string(query[0]) != expected {
+		t.Errorf("\nexpected: %s\ngot:      %v", expected, query)
+	}
+}
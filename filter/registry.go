@@ -126,14 +126,19 @@ func BoolField[P any](
 //	    In:  user.CreatedAtIn,
 //	    Nin: user.CreatedAtNotIn,
 //	})
+//
+// Values may also be relative expressions such as "now-7d" or "startOf:month". Pass WithClock
+// and WithLocation to control how they resolve.
 func TimeField[P any](
 	name string,
 	predicates TimePredicates[P],
+	opts ...TimeOption,
 ) FieldBuilder[P] {
+	options := newTimeOptions(opts)
 	return FieldBuilder[P]{
 		Name: name,
 		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
-			return newTimeFilterWithCombinators(predicates, combinators, false)
+			return newTimeFilterWithCombinators(predicates, combinators, false, options)
 		},
 	}
 }
@@ -142,11 +147,221 @@ func TimeField[P any](
 func NullableTimeField[P any](
 	name string,
 	predicates TimePredicates[P],
+	opts ...TimeOption,
+) FieldBuilder[P] {
+	options := newTimeOptions(opts)
+	return FieldBuilder[P]{
+		Name: name,
+		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
+			return newTimeFilterWithCombinators(predicates, combinators, true, options)
+		},
+	}
+}
+
+// IntField creates a FieldBuilder for a non-nullable integer field.
+// The column type T is inferred from the predicate functions.
+//
+// Example:
+//
+//	IntField("age", IntPredicates[predicate.User, int]{
+//	    Eq:  user.AgeEQ,
+//	    Ne:  user.AgeNEQ,
+//	    Gt:  user.AgeGT,
+//	    Gte: user.AgeGTE,
+//	    Lt:  user.AgeLT,
+//	    Lte: user.AgeLTE,
+//	    In:  user.AgeIn,
+//	    Nin: user.AgeNotIn,
+//	})
+func IntField[P any, T Integer](
+	name string,
+	predicates IntPredicates[P, T],
+) FieldBuilder[P] {
+	return FieldBuilder[P]{
+		Name: name,
+		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
+			return newNumberFilterWithCombinators(numberPredicates[P, T](predicates), combinators, false, intValue[T])
+		},
+	}
+}
+
+// NullableIntField creates a FieldBuilder for a nullable integer field.
+func NullableIntField[P any, T Integer](
+	name string,
+	predicates IntPredicates[P, T],
+) FieldBuilder[P] {
+	return FieldBuilder[P]{
+		Name: name,
+		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
+			return newNumberFilterWithCombinators(numberPredicates[P, T](predicates), combinators, true, intValue[T])
+		},
+	}
+}
+
+// FloatField creates a FieldBuilder for a non-nullable floating-point field.
+//
+// Example:
+//
+//	FloatField("price", FloatPredicates[predicate.Product, float64]{
+//	    Eq:  product.PriceEQ,
+//	    Gt:  product.PriceGT,
+//	    Gte: product.PriceGTE,
+//	    Lt:  product.PriceLT,
+//	    Lte: product.PriceLTE,
+//	})
+func FloatField[P any, T Float](
+	name string,
+	predicates FloatPredicates[P, T],
+) FieldBuilder[P] {
+	return FieldBuilder[P]{
+		Name: name,
+		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
+			return newNumberFilterWithCombinators(numberPredicates[P, T](predicates), combinators, false, floatValue[T])
+		},
+	}
+}
+
+// NullableFloatField creates a FieldBuilder for a nullable floating-point field.
+func NullableFloatField[P any, T Float](
+	name string,
+	predicates FloatPredicates[P, T],
+) FieldBuilder[P] {
+	return FieldBuilder[P]{
+		Name: name,
+		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
+			return newNumberFilterWithCombinators(numberPredicates[P, T](predicates), combinators, true, floatValue[T])
+		},
+	}
+}
+
+// UUIDField creates a FieldBuilder for a non-nullable UUID field.
+// String values are converted with parse (typically uuid.Parse), which keeps dewey free of
+// any UUID dependency while rejecting malformed IDs before they reach the database.
+//
+// Example:
+//
+//	UUIDField("org_id", uuid.Parse, UUIDPredicates[predicate.User, uuid.UUID]{
+//	    Eq:  user.OrgIDEQ,
+//	    Ne:  user.OrgIDNEQ,
+//	    In:  user.OrgIDIn,
+//	    Nin: user.OrgIDNotIn,
+//	})
+func UUIDField[P any, U any](
+	name string,
+	parse func(string) (U, error),
+	predicates UUIDPredicates[P, U],
+) FieldBuilder[P] {
+	return FieldBuilder[P]{
+		Name: name,
+		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
+			return newUUIDFilterWithCombinators(predicates, combinators, false, parse)
+		},
+	}
+}
+
+// NullableUUIDField creates a FieldBuilder for a nullable UUID field.
+func NullableUUIDField[P any, U any](
+	name string,
+	parse func(string) (U, error),
+	predicates UUIDPredicates[P, U],
+) FieldBuilder[P] {
+	return FieldBuilder[P]{
+		Name: name,
+		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
+			return newUUIDFilterWithCombinators(predicates, combinators, true, parse)
+		},
+	}
+}
+
+// EnumField creates a FieldBuilder for a non-nullable enumerated field.
+// Filter values are validated against enum, and the accepted values can be listed
+// through the builder's ValueLister implementation.
+//
+// Example:
+//
+//	EnumField("status",
+//	    Enum[int]{
+//	        Values:  []int{1, 2, 3},
+//	        Aliases: map[string]int{"active": 1, "suspended": 2, "deleted": 3},
+//	    },
+//	    EnumPredicates[predicate.User, int]{
+//	        Eq: user.StatusEQ,
+//	        Ne: user.StatusNEQ,
+//	        In: user.StatusIn,
+//	    },
+//	)
+func EnumField[P any, E comparable](
+	name string,
+	enum Enum[E],
+	predicates EnumPredicates[P, E],
+) FieldBuilder[P] {
+	return FieldBuilder[P]{
+		Name: name,
+		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
+			return newEnumFilterWithCombinators(enum, predicates, combinators, false)
+		},
+	}
+}
+
+// NullableEnumField creates a FieldBuilder for a nullable enumerated field.
+func NullableEnumField[P any, E comparable](
+	name string,
+	enum Enum[E],
+	predicates EnumPredicates[P, E],
+) FieldBuilder[P] {
+	return FieldBuilder[P]{
+		Name: name,
+		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
+			return newEnumFilterWithCombinators(enum, predicates, combinators, true)
+		},
+	}
+}
+
+// JSONField creates a FieldBuilder for a JSON/JSONB document column.
+// Filters address values inside the document through the field name ("metadata.plan.tier"),
+// and only paths matching one of the allow-listed patterns are accepted. Pattern segments
+// are separated by dots; "*" matches any single segment and a trailing "**" matches any
+// deeper path.
+//
+// Example:
+//
+//	JSONField("metadata", JSONPredicates[predicate.User]{
+//	    Eq:       metadataValueEQ,
+//	    HasKey:   metadataHasKey,
+//	    Contains: metadataContains,
+//	}, "plan.tier", "flags.*", "settings.**")
+func JSONField[P any](
+	name string,
+	predicates JSONPredicates[P],
+	patterns ...string,
+) FieldBuilder[P] {
+	return FieldBuilder[P]{
+		Name: name,
+		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
+			return newJSONFilter(predicates, combinators, patterns)
+		},
+	}
+}
+
+// ArrayField creates a FieldBuilder for an array/tag column.
+// The element type T is inferred from the predicate functions.
+//
+// Example:
+//
+//	ArrayField("tags", ArrayPredicates[predicate.Post, string]{
+//	    Has:    postTagsHas,    // $1 = ANY(tags)
+//	    HasAll: postTagsHasAll, // tags @> $1
+//	    HasAny: postTagsHasAny, // tags && $1
+//	    LenGte: postTagsLenGTE, // cardinality(tags) >= $1
+//	})
+func ArrayField[P any, T any](
+	name string,
+	predicates ArrayPredicates[P, T],
 ) FieldBuilder[P] {
 	return FieldBuilder[P]{
 		Name: name,
 		Create: func(combinators Combinators[P]) FieldFilterBuilder[P] {
-			return newTimeFilterWithCombinators(predicates, combinators, true)
+			return newArrayFilter(predicates, combinators)
 		},
 	}
 }
@@ -0,0 +1,263 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeOption configures how a time field resolves its filter values.
+type TimeOption func(*timeOptions)
+
+type timeOptions struct {
+	now      func() time.Time
+	location *time.Location
+}
+
+// WithClock sets the clock used to resolve relative date expressions such as "now-7d".
+// Defaults to time.Now. Useful for tests and for evaluating saved filters as of a fixed instant.
+func WithClock(now func() time.Time) TimeOption {
+	return func(o *timeOptions) {
+		o.now = now
+	}
+}
+
+// WithLocation sets the timezone used for calendar boundaries ("today", "now/d", "startOf:month")
+// and for date-only values such as "2024-01-01". Defaults to UTC; a nil location also means UTC.
+func WithLocation(loc *time.Location) TimeOption {
+	return func(o *timeOptions) {
+		if loc == nil {
+			loc = time.UTC
+		}
+		o.location = loc
+	}
+}
+
+func newTimeOptions(opts []TimeOption) timeOptions {
+	o := timeOptions{now: time.Now, location: time.UTC}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// parseRelativeTime resolves a relative date expression against now. The expression is an anchor
+// followed by any number of offsets ("+1d", "-2w") and roundings ("/d" rounds down to the start of
+// the day):
+//
+//	now, now-7d, now/d, now-1M/M
+//	today, yesterday, tomorrow
+//	startOf:month, endOf:week, startOf:year-1y
+//
+// Units are s, m, h, d, w, M (month) and y. startOf and endOf also accept the unit names second,
+// minute, hour, day, week, month and year. Weeks start on Monday and endOf resolves to the last
+// nanosecond of the period, so it pairs with lte. Offsets too large to add, such as
+// now-99999999999999s, are rejected rather than wrapped around.
+//
+// The boolean result is false when expr is not a relative expression at all.
+func parseRelativeTime(expr string, now time.Time) (time.Time, bool, error) {
+	t, rest, ok := relativeAnchor(expr, now)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	for rest != "" {
+		switch rest[0] {
+		case '+', '-':
+			end := 1
+			for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+				end++
+			}
+			if end == 1 || end == len(rest) {
+				return time.Time{}, true, fmt.Errorf("invalid offset %q in %q", rest, expr)
+			}
+			n, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return time.Time{}, true, fmt.Errorf("invalid offset %q in %q", rest, expr)
+			}
+			if rest[0] == '-' {
+				n = -n
+			}
+			unit, ok := timeUnitSymbol(rest[end])
+			if !ok {
+				return time.Time{}, true, fmt.Errorf("unknown time unit %q in %q", rest[end], expr)
+			}
+			if !offsetInRange(unit, n) {
+				return time.Time{}, true, fmt.Errorf("offset %q out of range in %q", rest[:end+1], expr)
+			}
+			t = addTimeUnit(t, unit, n)
+			rest = rest[end+1:]
+		case '/':
+			if len(rest) < 2 {
+				return time.Time{}, true, fmt.Errorf("missing rounding unit in %q", expr)
+			}
+			unit, ok := timeUnitSymbol(rest[1])
+			if !ok {
+				return time.Time{}, true, fmt.Errorf("unknown time unit %q in %q", rest[1], expr)
+			}
+			t = startOfTimeUnit(t, unit)
+			rest = rest[2:]
+		default:
+			return time.Time{}, true, fmt.Errorf("unexpected %q in %q", rest, expr)
+		}
+	}
+
+	return t, true, nil
+}
+
+// relativeAnchor parses the leading anchor of a relative expression and returns the remainder.
+func relativeAnchor(expr string, now time.Time) (time.Time, string, bool) {
+	for _, prefix := range []string{"startOf:", "endOf:"} {
+		if len(expr) < len(prefix) || !strings.EqualFold(expr[:len(prefix)], prefix) {
+			continue
+		}
+		rest := expr[len(prefix):]
+		end := strings.IndexAny(rest, "+-/")
+		if end < 0 {
+			end = len(rest)
+		}
+		unit, ok := timeUnitName(rest[:end])
+		if !ok {
+			return time.Time{}, "", false
+		}
+		t := startOfTimeUnit(now, unit)
+		if prefix == "endOf:" {
+			t = addTimeUnit(t, unit, 1).Add(-time.Nanosecond)
+		}
+		return t, rest[end:], true
+	}
+
+	end := strings.IndexAny(expr, "+-/")
+	if end < 0 {
+		end = len(expr)
+	}
+	today := startOfTimeUnit(now, 'd')
+	switch strings.ToLower(expr[:end]) {
+	case "now":
+		return now, expr[end:], true
+	case "today":
+		return today, expr[end:], true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), expr[end:], true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), expr[end:], true
+	default:
+		return time.Time{}, "", false
+	}
+}
+
+// timeUnitSymbol maps a single-letter unit to its canonical symbol. "m" is minutes and "M" months.
+func timeUnitSymbol(c byte) (byte, bool) {
+	switch c {
+	case 's', 'm', 'h', 'd', 'w', 'M', 'y':
+		return c, true
+	default:
+		return 0, false
+	}
+}
+
+// timeUnitName maps a startOf/endOf unit, either a name or a single-letter symbol.
+func timeUnitName(name string) (byte, bool) {
+	if len(name) == 1 {
+		return timeUnitSymbol(name[0])
+	}
+	switch strings.ToLower(name) {
+	case "second":
+		return 's', true
+	case "minute":
+		return 'm', true
+	case "hour":
+		return 'h', true
+	case "day":
+		return 'd', true
+	case "week":
+		return 'w', true
+	case "month":
+		return 'M', true
+	case "year":
+		return 'y', true
+	default:
+		return 0, false
+	}
+}
+
+// addTimeUnit moves t by n units. Days and larger units follow the calendar, so "now-1d" is the
+// same wall-clock time yesterday even across a daylight saving change. Months and years clamp to
+// the last day of the target month, so "now-1M" on March 31 is February 28 (or 29), not March 3.
+func addTimeUnit(t time.Time, unit byte, n int) time.Time {
+	switch unit {
+	case 's':
+		return t.Add(time.Duration(n) * time.Second)
+	case 'm':
+		return t.Add(time.Duration(n) * time.Minute)
+	case 'h':
+		return t.Add(time.Duration(n) * time.Hour)
+	case 'd':
+		return t.AddDate(0, 0, n)
+	case 'w':
+		return t.AddDate(0, 0, 7*n)
+	case 'M':
+		return addMonths(t, n)
+	default:
+		return addMonths(t, 12*n)
+	}
+}
+
+// offsetInRange reports whether addTimeUnit can move by n units without overflowing: seconds,
+// minutes and hours are converted to a time.Duration, weeks to days and years to months.
+func offsetInRange(unit byte, n int) bool {
+	var limit int64
+	switch unit {
+	case 's':
+		limit = math.MaxInt64 / int64(time.Second)
+	case 'm':
+		limit = math.MaxInt64 / int64(time.Minute)
+	case 'h':
+		limit = math.MaxInt64 / int64(time.Hour)
+	case 'w':
+		limit = math.MaxInt / 7
+	case 'y':
+		limit = math.MaxInt / 12
+	default:
+		return true
+	}
+	return int64(n) <= limit && int64(n) >= -limit
+}
+
+// addMonths moves t by n calendar months, clamping the day to the length of the target month.
+func addMonths(t time.Time, n int) time.Time {
+	y, mo, d := t.Date()
+	h, mi, s := t.Clock()
+	first := time.Date(y, mo+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	if last := time.Date(first.Year(), first.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day(); d > last {
+		d = last
+	}
+	return time.Date(first.Year(), first.Month(), d, h, mi, s, t.Nanosecond(), t.Location())
+}
+
+// startOfTimeUnit rounds t down to the start of the unit in t's location.
+func startOfTimeUnit(t time.Time, unit byte) time.Time {
+	y, mo, d := t.Date()
+	h, mi, s := t.Clock()
+	loc := t.Location()
+
+	switch unit {
+	case 's':
+		return time.Date(y, mo, d, h, mi, s, 0, loc)
+	case 'm':
+		return time.Date(y, mo, d, h, mi, 0, 0, loc)
+	case 'h':
+		return time.Date(y, mo, d, h, 0, 0, 0, loc)
+	case 'd':
+		return time.Date(y, mo, d, 0, 0, 0, 0, loc)
+	case 'w':
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, mo, d-offset, 0, 0, 0, 0, loc)
+	case 'M':
+		return time.Date(y, mo, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+	}
+}
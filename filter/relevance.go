@@ -0,0 +1,246 @@
+package filter
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MatchMode selects how a search term matches a column.
+type MatchMode string
+
+const (
+	MatchContains MatchMode = "contains" // The column contains the term anywhere (default)
+	MatchPrefix   MatchMode = "prefix"   // The column starts with the term, e.g. SKUs
+	MatchExact    MatchMode = "exact"    // The whole column equals the term, e.g. IDs or emails
+	MatchWord     MatchMode = "word"     // The term is a whole space-separated word of the column
+	MatchFuzzy    MatchMode = "fuzzy"    // The column is similar to the term, tolerating typos
+)
+
+// DefaultFuzzyThreshold is the minimum similarity used by MatchFuzzy fields without a
+// Threshold. It matches the pg_trgm default.
+const DefaultFuzzyThreshold = 0.3
+
+// SearchField describes how a column takes part in search. Terms are matched
+// case-insensitively through ILike with the patterns MatchPatterns returns for Mode, or
+// through Similar for MatchFuzzy.
+//
+// Example:
+//
+//	columns := map[string]filter.SearchField[predicate.Product]{
+//	    product.FieldTitle:       {Weight: 3, ILike: ilike(product.FieldTitle)},
+//	    product.FieldDescription: {ILike: ilike(product.FieldDescription)},
+//	    product.FieldSku:         {Mode: filter.MatchPrefix, Weight: 5, ILike: ilike(product.FieldSku)},
+//	}
+//
+// where ilike(column) returns a function building the ORM's "column ILIKE pattern" predicate.
+type SearchField[P any] struct {
+	// Mode selects how terms match the column (MatchContains if empty)
+	Mode MatchMode
+
+	// Weight is added to the relevance score of a row when the column matches a term
+	// (1 if zero)
+	Weight float64
+
+	// ILike matches the column case-insensitively against a LIKE pattern using % and _
+	// wildcards with backslash escapes, like StringPredicates.ILike
+	ILike func(pattern string) P
+
+	// Similar matches the column when its similarity to term, between 0 and 1, is at least
+	// threshold. It is required for MatchFuzzy; see TrigramSimilar for PostgreSQL and
+	// WordSimilarity for in-memory matching.
+	Similar func(term string, threshold float64) P
+
+	// Threshold is the minimum similarity for MatchFuzzy (DefaultFuzzyThreshold if zero)
+	Threshold float64
+
+	// Type restricts the column to tokens that parse as the type, such as SearchUUID. They
+	// are matched through Eq regardless of Mode, and skip the text columns and Fields. Typed
+	// columns don't contribute to SearchScore. SearchText, the default, accepts every token.
+	Type SearchType
+
+	// Eq matches the column exactly against a token parsed by Type (see SearchType.Parse).
+	// It is required for typed columns.
+	Eq func(value any) P
+}
+
+// weight returns the field's weight, defaulting to 1.
+func (f SearchField[P]) weight() float64 {
+	if f.Weight == 0 {
+		return 1
+	}
+	return f.Weight
+}
+
+// threshold returns the field's fuzzy threshold, defaulting to DefaultFuzzyThreshold.
+func (f SearchField[P]) threshold() float64 {
+	if f.Threshold == 0 {
+		return DefaultFuzzyThreshold
+	}
+	return f.Threshold
+}
+
+// predicate matches term against the column.
+func (f SearchField[P]) predicate(term string, builder PredicateBuilder[P]) (P, bool) {
+	if f.Mode == MatchFuzzy {
+		return f.Similar(term, f.threshold()), true
+	}
+	patterns := MatchPatterns(f.Mode, term)
+	predicates := make([]P, len(patterns))
+	for i, pattern := range patterns {
+		predicates[i] = f.ILike(pattern)
+	}
+	return combinePredicates(predicates, builder.Or)
+}
+
+// MatchPatterns returns the LIKE patterns that match term in the given mode; a column matches
+// if it matches any of them. The term is escaped with EscapeLike, and MatchFuzzy, which
+// doesn't use patterns, falls back to MatchContains:
+//
+//	MatchContains  %term%
+//	MatchPrefix    term%
+//	MatchExact     term
+//	MatchWord      term, term %, % term, % term %
+func MatchPatterns(mode MatchMode, term string) []string {
+	term = EscapeLike(term)
+	switch mode {
+	case MatchPrefix:
+		return []string{term + "%"}
+	case MatchExact:
+		return []string{term}
+	case MatchWord:
+		return []string{term, term + " %", "% " + term, "% " + term + " %"}
+	default:
+		return []string{"%" + term + "%"}
+	}
+}
+
+// ScoreBuilder renders relevance score expressions for an ORM or SQL dialect. E is the
+// expression type, such as SQLExpr.
+type ScoreBuilder[E any] interface {
+	// Match returns an expression that evaluates to weight when column matches any of the
+	// LIKE patterns case-insensitively, and to 0 otherwise
+	Match(column string, patterns []string, weight float64) E
+
+	// Similarity returns an expression that evaluates to weight times the similarity of
+	// term to column, between 0 and 1, for MatchFuzzy columns
+	Similarity(column string, term string, weight float64) E
+
+	// Sum adds up expressions
+	Sum(exprs ...E) E
+}
+
+// SQLExpr is a SQL expression with ? placeholders and their arguments.
+type SQLExpr struct {
+	SQL  string
+	Args []any
+}
+
+// SQLScoreBuilder builds portable SQL score expressions:
+//
+//	CASE WHEN LOWER(title) LIKE ? ESCAPE '\' THEN 3 ELSE 0 END + CASE WHEN ... END
+//
+// Patterns are lower-cased and passed as arguments. Column names are written as given, so
+// they must come from code, never from user input. Similarity uses word_similarity from the
+// PostgreSQL pg_trgm extension, the only part that isn't portable.
+type SQLScoreBuilder struct {
+	// Escape is appended to every LIKE comparison (ESCAPE '\' if empty). MySQL, which treats
+	// backslashes in string literals as escapes, needs ESCAPE '\\'.
+	Escape string
+}
+
+// Match implements ScoreBuilder.
+func (b SQLScoreBuilder) Match(column string, patterns []string, weight float64) SQLExpr {
+	escape := b.Escape
+	if escape == "" {
+		escape = `ESCAPE '\'`
+	}
+
+	conditions := make([]string, len(patterns))
+	args := make([]any, len(patterns))
+	for i, pattern := range patterns {
+		conditions[i] = "LOWER(" + column + ") LIKE ? " + escape
+		args[i] = strings.ToLower(pattern)
+	}
+	return SQLExpr{
+		SQL:  "CASE WHEN " + strings.Join(conditions, " OR ") + " THEN " + strconv.FormatFloat(weight, 'g', -1, 64) + " ELSE 0 END",
+		Args: args,
+	}
+}
+
+// Similarity implements ScoreBuilder.
+func (SQLScoreBuilder) Similarity(column string, term string, weight float64) SQLExpr {
+	expr := SQLExpr{SQL: "word_similarity(?, " + column + ")", Args: []any{term}}
+	if weight != 1 {
+		expr.SQL += " * " + strconv.FormatFloat(weight, 'g', -1, 64)
+	}
+	return expr
+}
+
+// Sum implements ScoreBuilder. The sum of no expressions is 0.
+func (SQLScoreBuilder) Sum(exprs ...SQLExpr) SQLExpr {
+	if len(exprs) == 0 {
+		return SQLExpr{SQL: "0"}
+	}
+	var sum SQLExpr
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = e.SQL
+		sum.Args = append(sum.Args, e.Args...)
+	}
+	sum.SQL = strings.Join(parts, " + ")
+	return sum
+}
+
+// SearchScore returns an expression that scores rows by how well they match the free text of
+// a search: every term adds the weight of each of Searcher.Columns it matches, or for
+// MatchFuzzy columns the weight scaled by the term's similarity. Excluded terms, qualifiers,
+// typed columns and the tokens routed to them, and the plain functions in Searcher.Fields
+// don't contribute. ok is false when there is nothing to score.
+//
+// Order by the score before any other sort criteria:
+//
+//	score, ok := filter.SearchScore(searcher, input.Search, filter.SQLScoreBuilder{})
+//	if ok {
+//	    query = query.Order(func(s *sql.Selector) {
+//	        s.OrderExpr(sql.ExprP("("+score.SQL+") DESC", score.Args...))
+//	    })
+//	}
+//	query = sort.ApplyMultiple(query, sortCfg, sortFields, orderBuilder, sorts)
+func SearchScore[P any, E any](s Searcher[P], search string, sb ScoreBuilder[E]) (score E, ok bool) {
+	names := make([]string, 0, len(s.Columns))
+	for name := range s.Columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var exprs []E
+	for _, term := range s.freeText(ParseSearch(search)) {
+		if term.exclude {
+			continue
+		}
+		for _, token := range term.tokens {
+			for _, alternative := range append([]string{token.Text}, token.Synonyms...) {
+				if s.typedToken(alternative) {
+					continue
+				}
+				for _, name := range names {
+					field := s.Columns[name]
+					if field.Type != SearchText {
+						continue
+					}
+					if field.Mode == MatchFuzzy {
+						exprs = append(exprs, sb.Similarity(name, alternative, field.weight()))
+						continue
+					}
+					exprs = append(exprs, sb.Match(name, MatchPatterns(field.Mode, alternative), field.weight()))
+				}
+			}
+		}
+	}
+
+	if len(exprs) == 0 {
+		return score, false
+	}
+	return sb.Sum(exprs...), true
+}
@@ -0,0 +1,136 @@
+package filter_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+)
+
+func mockILike(field string) func(string) MockPredicate {
+	return func(pattern string) MockPredicate {
+		return MockPredicate(fmt.Sprintf("%s ILIKE '%s'", field, pattern))
+	}
+}
+
+func TestMatchPatterns(t *testing.T) {
+	tests := []struct {
+		mode     filter.MatchMode
+		term     string
+		expected []string
+	}{
+		{mode: "", term: "ab", expected: []string{"%ab%"}},
+		{mode: filter.MatchContains, term: `50%_off\`, expected: []string{`%50\%\_off\\%`}},
+		{mode: filter.MatchPrefix, term: "SKU-1", expected: []string{"SKU-1%"}},
+		{mode: filter.MatchExact, term: "a@b.com", expected: []string{"a@b.com"}},
+		{mode: filter.MatchWord, term: "go", expected: []string{"go", "go %", "% go", "% go %"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+" "+tt.term, func(t *testing.T) {
+			patterns := filter.MatchPatterns(tt.mode, tt.term)
+			if !reflect.DeepEqual(patterns, tt.expected) {
+				t.Errorf("\nexpected: %q\ngot:      %q", tt.expected, patterns)
+			}
+			for _, p := range patterns {
+				if err := filter.ValidateLike(p); err != nil {
+					t.Errorf("pattern %q is invalid: %v", p, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSearcher_Columns(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+	builder := filter.PredicateBuilder[MockPredicate]{Or: mockOr, And: mockAnd, Not: mockNot}
+
+	searcher := filter.Searcher[MockPredicate]{
+		Fields: filter.SearchFields[MockPredicate]{
+			"notes": mockContainsFold("notes"),
+		},
+		Columns: map[string]filter.SearchField[MockPredicate]{
+			"title": {Weight: 3, ILike: mockILike("title")},
+			"sku":   {Mode: filter.MatchPrefix, Weight: 5, ILike: mockILike("sku")},
+			"email": {Mode: filter.MatchExact, ILike: mockILike("email")},
+			"tags":  {Mode: filter.MatchWord, ILike: mockILike("tags")},
+		},
+	}
+
+	query, err := filter.ApplySearchQuery(&MockQuery{}, cfg, searcher, builder, "ab")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"(email ILIKE 'ab' OR notes ILIKE '%ab%' OR sku ILIKE 'ab%' OR " +
+		"(tags ILIKE 'ab' OR tags ILIKE 'ab %' OR tags ILIKE '% ab' OR tags ILIKE '% ab %') OR title ILIKE '%ab%')"}
+	if !reflect.DeepEqual(query.predicates, expected) {
+		t.Errorf("\nexpected: %v\ngot:      %v", expected, query.predicates)
+	}
+}
+
+func TestSearchScore(t *testing.T) {
+	searcher := filter.Searcher[MockPredicate]{
+		Fields: filter.SearchFields[MockPredicate]{
+			"notes": mockContainsFold("notes"),
+		},
+		Columns: map[string]filter.SearchField[MockPredicate]{
+			"title": {Weight: 2.5, ILike: mockILike("title")},
+			"sku":   {Mode: filter.MatchPrefix, ILike: mockILike("sku")},
+		},
+		Filters: filter.BuildFilterMap(
+			mockCombinators,
+			filter.StringField("status", mockStringPredicates("status")),
+		),
+		Analyzer: filter.Analyzer{
+			Filters: []filter.TokenFilter{filter.Synonyms(map[string][]string{"tv": {"Television"}})},
+		},
+	}
+
+	t.Run("sql", func(t *testing.T) {
+		score, ok := filter.SearchScore(searcher, `tv -refurbished status:new`, filter.SQLScoreBuilder{})
+		if !ok {
+			t.Fatal("expected a score")
+		}
+
+		expected := filter.SQLExpr{
+			SQL: `CASE WHEN LOWER(sku) LIKE ? ESCAPE '\' THEN 1 ELSE 0 END + ` +
+				`CASE WHEN LOWER(title) LIKE ? ESCAPE '\' THEN 2.5 ELSE 0 END + ` +
+				`CASE WHEN LOWER(sku) LIKE ? ESCAPE '\' THEN 1 ELSE 0 END + ` +
+				`CASE WHEN LOWER(title) LIKE ? ESCAPE '\' THEN 2.5 ELSE 0 END`,
+			Args: []any{"tv%", "%tv%", "television%", "%television%"},
+		}
+		if !reflect.DeepEqual(score, expected) {
+			t.Errorf("\nexpected: %#v\ngot:      %#v", expected, score)
+		}
+	})
+
+	t.Run("word mode and escape", func(t *testing.T) {
+		s := filter.Searcher[MockPredicate]{
+			Columns: map[string]filter.SearchField[MockPredicate]{
+				"tags": {Mode: filter.MatchWord, ILike: mockILike("tags")},
+			},
+		}
+		score, _ := filter.SearchScore(s, "Go", filter.SQLScoreBuilder{Escape: `ESCAPE '\\'`})
+
+		expected := filter.SQLExpr{
+			SQL: `CASE WHEN LOWER(tags) LIKE ? ESCAPE '\\' OR LOWER(tags) LIKE ? ESCAPE '\\' OR ` +
+				`LOWER(tags) LIKE ? ESCAPE '\\' OR LOWER(tags) LIKE ? ESCAPE '\\' THEN 1 ELSE 0 END`,
+			Args: []any{"go", "go %", "% go", "% go %"},
+		}
+		if !reflect.DeepEqual(score, expected) {
+			t.Errorf("\nexpected: %#v\ngot:      %#v", expected, score)
+		}
+	})
+
+	t.Run("nothing to score", func(t *testing.T) {
+		if _, ok := filter.SearchScore(searcher, `-tv status:new`, filter.SQLScoreBuilder{}); ok {
+			t.Error("expected no score")
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package rsql
+
+import (
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF        tokenKind = iota
+	tokenLParen               // (
+	tokenRParen               // )
+	tokenAnd                  // ; or the keyword "and"
+	tokenOr                   // , or the keyword "or"
+	tokenComparison           // ==, !=, =gt=, <=, ...
+	tokenValue                // selector or argument, quoted or unreserved
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of input"
+	case tokenLParen:
+		return `"("`
+	case tokenRParen:
+		return `")"`
+	case tokenAnd:
+		return `";"`
+	case tokenOr:
+		return `","`
+	case tokenComparison:
+		return "comparison operator"
+	default:
+		return "value"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	pos  int    // byte offset of the token in the input
+	text string // the token as written, used for operators and error messages
+
+	// parts holds a value split on its wildcard stars: "jo*n" is ["jo", "n"]
+	// and a value without wildcards has a single part. Escaped stars in quoted
+	// values (\*) are literal.
+	parts []string
+
+	// quoted reports whether the value was written in quotes, so "and" and "or"
+	// are only treated as keywords when unquoted
+	quoted bool
+}
+
+// value returns the value with wildcards written back as literal stars.
+func (t token) value() string {
+	return strings.Join(t.parts, "*")
+}
+
+// reserved reports whether c ends an unreserved string.
+func reserved(c byte) bool {
+	switch c {
+	case '"', '\'', '(', ')', ';', ',', '=', '!', '<', '>', ' ', '\t', '\n', '\r':
+		return true
+	default:
+		return false
+	}
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+// next returns the next token, skipping whitespace.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && strings.IndexByte(" \t\n\r", l.input[l.pos]) >= 0 {
+		l.pos++
+	}
+	if l.pos == len(l.input) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.input[l.pos]
+	switch c {
+	case '(':
+		l.pos++
+		return token{kind: tokenLParen, pos: start, text: "("}, nil
+	case ')':
+		l.pos++
+		return token{kind: tokenRParen, pos: start, text: ")"}, nil
+	case ';':
+		l.pos++
+		return token{kind: tokenAnd, pos: start, text: ";"}, nil
+	case ',':
+		l.pos++
+		return token{kind: tokenOr, pos: start, text: ","}, nil
+	case '=', '!', '<', '>':
+		return l.comparison()
+	case '"', '\'':
+		return l.quoted()
+	default:
+		for l.pos < len(l.input) && !reserved(l.input[l.pos]) {
+			l.pos++
+		}
+		text := l.input[start:l.pos]
+		return token{kind: tokenValue, pos: start, text: text, parts: strings.Split(text, "*")}, nil
+	}
+}
+
+// comparison lexes ==, !=, =name=, <, <=, > and >=.
+func (l *lexer) comparison() (token, error) {
+	start := l.pos
+	c := l.input[l.pos]
+	l.pos++
+
+	switch c {
+	case '<', '>':
+		if l.pos < len(l.input) && l.input[l.pos] == '=' {
+			l.pos++
+		}
+	case '!':
+		if l.pos == len(l.input) || l.input[l.pos] != '=' {
+			return token{}, syntaxError(start, `expected "=" after "!"`)
+		}
+		l.pos++
+	default:
+		for l.pos < len(l.input) && isAlpha(l.input[l.pos]) {
+			l.pos++
+		}
+		if l.pos == len(l.input) || l.input[l.pos] != '=' {
+			return token{}, syntaxError(start, "unterminated comparison operator %q", l.input[start:l.pos])
+		}
+		l.pos++
+	}
+
+	return token{kind: tokenComparison, pos: start, text: l.input[start:l.pos]}, nil
+}
+
+// quoted lexes a single- or double-quoted value. A backslash escapes the next character;
+// \* is a literal star and an unescaped * is a wildcard.
+func (l *lexer) quoted() (token, error) {
+	start := l.pos
+	quote := l.input[l.pos]
+	l.pos++
+
+	var parts []string
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		l.pos++
+		switch c {
+		case quote:
+			parts = append(parts, b.String())
+			return token{kind: tokenValue, pos: start, text: l.input[start:l.pos], parts: parts, quoted: true}, nil
+		case '\\':
+			if l.pos == len(l.input) {
+				return token{}, syntaxError(l.pos-1, "unterminated escape sequence")
+			}
+			b.WriteByte(l.input[l.pos])
+			l.pos++
+		case '*':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return token{}, syntaxError(start, "unterminated quoted value")
+}
+
+func isAlpha(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,326 @@
+// Package rsql parses RSQL/FIQL filter expressions into dewey filter groups.
+//
+// RSQL combines comparisons with ";" (and) and "," (or), where "and" binds tighter, and
+// parentheses group sub-expressions:
+//
+//	name==john*;age=gt=18,status=in=(active,pending)
+//
+// parses as (name starts with "john" AND age > 18) OR status IN (active, pending).
+//
+// Example:
+//
+//	group, err := rsql.Parse(r.URL.Query().Get("filter"))
+//	if err != nil {
+//	    return http.StatusBadRequest, err
+//	}
+//	query, err = filter.ApplyStructuredFiltersE(query, cfg, group, userFilterBuilders, predicates)
+package rsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/internal/parse"
+)
+
+// SyntaxError reports a malformed expression. Pos is the byte offset in the input where
+// the problem was found, and Err wraps filter.ErrUnsupportedOperator for an unknown
+// comparison operator.
+type SyntaxError parse.Error
+
+func (e *SyntaxError) Error() string {
+	return (*parse.Error)(e).Message("rsql")
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+func syntaxError(pos int, format string, args ...any) *SyntaxError {
+	return (*SyntaxError)(parse.Errorf(pos, format, args...))
+}
+
+// operators maps RSQL and FIQL comparison operators onto dewey operators.
+var operators = map[string]filter.Operator{
+	"==":        filter.OpEq,
+	"!=":        filter.OpNe,
+	"=gt=":      filter.OpGt,
+	">":         filter.OpGt,
+	"=ge=":      filter.OpGte,
+	">=":        filter.OpGte,
+	"=lt=":      filter.OpLt,
+	"<":         filter.OpLt,
+	"=le=":      filter.OpLte,
+	"<=":        filter.OpLte,
+	"=in=":      filter.OpIn,
+	"=out=":     filter.OpNin,
+	"=between=": filter.OpBetween,
+	"=like=":    filter.OpLike,
+	"=null=":    filter.OpNull,
+}
+
+// Parse parses an RSQL expression into a filter group. An empty expression yields an empty group.
+//
+// Arguments are passed to the field builders as strings, or []any of strings for
+// argument lists such as =in=(a,b). Unescaped "*" in == and != arguments are wildcards:
+//
+//	name==john*   startswiths "john"
+//	name==*son    endswiths "son"
+//	name==*oh*    containss "oh"
+//	name==j*n     like "j%n"
+//	name!=john*   NOT startswiths "john"
+//
+// =null= takes true or false and maps to null or nnull.
+func Parse(input string) (filter.FilterGroup, error) {
+	p := &parser{lexer: lexer{input: input}}
+	if err := p.advance(); err != nil {
+		return filter.FilterGroup{}, err
+	}
+	if p.tok.kind == tokenEOF {
+		return filter.FilterGroup{Logic: "and"}, nil
+	}
+
+	n, err := p.or(0)
+	if err != nil {
+		return filter.FilterGroup{}, err
+	}
+	if p.tok.kind != tokenEOF {
+		return filter.FilterGroup{}, p.unexpected()
+	}
+	return n.ToGroup(), nil
+}
+
+type parser struct {
+	lexer lexer
+	tok   token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return syntaxError(p.tok.pos, "unexpected end of input")
+	}
+	return syntaxError(p.tok.pos, "unexpected %q", p.tok.text)
+}
+
+// isKeyword reports whether the current token is the unquoted keyword "and" or "or".
+func (p *parser) isKeyword(keyword string) bool {
+	return p.tok.kind == tokenValue && !p.tok.quoted && strings.EqualFold(p.tok.text, keyword)
+}
+
+// or parses: and { ("," | "or") and }
+func (p *parser) or(depth int) (parse.Node, error) {
+	return p.list("or", tokenOr, depth, p.and)
+}
+
+// and parses: constraint { (";" | "and") constraint }
+func (p *parser) and(depth int) (parse.Node, error) {
+	return p.list("and", tokenAnd, depth, p.constraint)
+}
+
+// list parses operands separated by the given operator, combining more than one into a group.
+func (p *parser) list(logic string, separator tokenKind, depth int, operand func(int) (parse.Node, error)) (parse.Node, error) {
+	first, err := operand(depth)
+	if err != nil {
+		return parse.Node{}, err
+	}
+
+	nodes := []parse.Node{first}
+	for p.tok.kind == separator || p.isKeyword(logic) {
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		n, err := operand(depth)
+		if err != nil {
+			return parse.Node{}, err
+		}
+		nodes = append(nodes, n)
+	}
+	return parse.Combine(logic, nodes...), nil
+}
+
+// constraint parses: "(" or ")" | comparison
+func (p *parser) constraint(depth int) (parse.Node, error) {
+	if p.tok.kind != tokenLParen {
+		return p.comparison()
+	}
+	if depth >= parse.MaxDepth {
+		return parse.Node{}, syntaxError(p.tok.pos, "expression nested deeper than %d levels", parse.MaxDepth)
+	}
+
+	open := p.tok.pos
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+	n, err := p.or(depth + 1)
+	if err != nil {
+		return parse.Node{}, err
+	}
+	if p.tok.kind != tokenRParen {
+		if p.tok.kind == tokenEOF {
+			return parse.Node{}, syntaxError(open, "unclosed parenthesis")
+		}
+		return parse.Node{}, p.unexpected()
+	}
+	return n, p.advance()
+}
+
+// comparison parses: selector operator arguments
+func (p *parser) comparison() (parse.Node, error) {
+	if p.tok.kind != tokenValue || p.tok.quoted {
+		if p.tok.kind == tokenEOF {
+			return parse.Node{}, syntaxError(p.tok.pos, "expected selector, got end of input")
+		}
+		return parse.Node{}, syntaxError(p.tok.pos, "expected selector, got %q", p.tok.text)
+	}
+	selector := p.tok
+	if len(selector.parts) > 1 {
+		return parse.Node{}, syntaxError(selector.pos, "wildcards are not allowed in selector %q", selector.text)
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	if p.tok.kind != tokenComparison {
+		return parse.Node{}, syntaxError(p.tok.pos, "expected comparison operator after %q", selector.text)
+	}
+	opTok := p.tok
+	op, ok := operators[opTok.text]
+	if !ok {
+		return parse.Node{}, &SyntaxError{
+			Pos: opTok.pos,
+			Msg: fmt.Sprintf("unknown comparison operator %q", opTok.text),
+			Err: filter.ErrUnsupportedOperator,
+		}
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	args, err := p.arguments()
+	if err != nil {
+		return parse.Node{}, err
+	}
+
+	return buildComparison(selector.text, op, opTok, args)
+}
+
+// arguments parses: value | "(" value { "," value } ")"
+func (p *parser) arguments() ([]token, error) {
+	if p.tok.kind == tokenValue {
+		arg := p.tok
+		return []token{arg}, p.advance()
+	}
+	if p.tok.kind != tokenLParen {
+		if p.tok.kind == tokenEOF {
+			return nil, syntaxError(p.tok.pos, "expected argument, got end of input")
+		}
+		return nil, syntaxError(p.tok.pos, "expected argument, got %q", p.tok.text)
+	}
+
+	open := p.tok.pos
+	var args []token
+	for {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenValue {
+			if p.tok.kind == tokenEOF {
+				return nil, syntaxError(open, "unclosed argument list")
+			}
+			return nil, syntaxError(p.tok.pos, "expected argument, got %q", p.tok.text)
+		}
+		args = append(args, p.tok)
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		switch p.tok.kind {
+		case tokenOr:
+			continue
+		case tokenRParen:
+			return args, p.advance()
+		case tokenEOF:
+			return nil, syntaxError(open, "unclosed argument list")
+		default:
+			return nil, syntaxError(p.tok.pos, `expected "," or ")", got %q`, p.tok.text)
+		}
+	}
+}
+
+// buildComparison turns a parsed comparison into a filter, expanding wildcards and =null=.
+func buildComparison(field string, op filter.Operator, opTok token, args []token) (parse.Node, error) {
+	switch op {
+	case filter.OpIn, filter.OpNin:
+		values := make([]any, len(args))
+		for i, arg := range args {
+			values[i] = arg.value()
+		}
+		return parse.FilterNode(field, op, values), nil
+	case filter.OpBetween:
+		if len(args) != 2 {
+			return parse.Node{}, syntaxError(opTok.pos, "%s expects 2 arguments, got %d", opTok.text, len(args))
+		}
+		return parse.FilterNode(field, op, []any{args[0].value(), args[1].value()}), nil
+	}
+
+	if len(args) != 1 {
+		return parse.Node{}, syntaxError(opTok.pos, "%s expects a single argument, got %d", opTok.text, len(args))
+	}
+	arg := args[0]
+
+	switch op {
+	case filter.OpNull:
+		isNull, err := strconv.ParseBool(arg.value())
+		if err != nil {
+			return parse.Node{}, &SyntaxError{
+				Pos: arg.pos,
+				Msg: fmt.Sprintf("=null= expects true or false, got %q", arg.text),
+				Err: filter.ErrInvalidValue,
+			}
+		}
+		if !isNull {
+			op = filter.OpNnull
+		}
+		return parse.FilterNode(field, op, nil), nil
+	case filter.OpEq, filter.OpNe:
+		if len(arg.parts) == 1 {
+			return parse.FilterNode(field, op, arg.parts[0]), nil
+		}
+		n := wildcardNode(field, arg.parts)
+		if op == filter.OpNe {
+			n = n.Negate()
+		}
+		return n, nil
+	default:
+		return parse.FilterNode(field, op, arg.value()), nil
+	}
+}
+
+// wildcardNode maps a value containing wildcards to the narrowest string operator.
+func wildcardNode(field string, parts []string) parse.Node {
+	first, last := parts[0], parts[len(parts)-1]
+	switch {
+	case len(parts) == 2 && last == "":
+		return parse.FilterNode(field, filter.OpStartsWithCS, first)
+	case len(parts) == 2 && first == "":
+		return parse.FilterNode(field, filter.OpEndsWithCS, last)
+	case len(parts) == 3 && first == "" && last == "":
+		return parse.FilterNode(field, filter.OpContainsCS, parts[1])
+	}
+
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = filter.EscapeLike(part)
+	}
+	return parse.FilterNode(field, filter.OpLike, strings.Join(escaped, "%"))
+}
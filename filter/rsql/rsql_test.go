@@ -0,0 +1,207 @@
+package rsql_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/filter/rsql"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected filter.FilterGroup
+	}{
+		{
+			name:  "single comparison",
+			input: "status==active",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "status", Operator: filter.OpEq, Value: "active"}},
+			},
+		},
+		{
+			name:  "and binds tighter than or",
+			input: "name==john*;age=gt=18,status=in=(a,b)",
+			expected: filter.FilterGroup{
+				Logic: "or",
+				Filters: []filter.Filter{
+					{Field: "status", Operator: filter.OpIn, Value: []any{"a", "b"}},
+				},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "and",
+						Filters: []filter.Filter{
+							{Field: "name", Operator: filter.OpStartsWithCS, Value: "john"},
+							{Field: "age", Operator: filter.OpGt, Value: "18"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "parentheses override precedence",
+			input: "age=ge=18;(role==admin,role==owner)",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "age", Operator: filter.OpGte, Value: "18"}},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "or",
+						Filters: []filter.Filter{
+							{Field: "role", Operator: filter.OpEq, Value: "admin"},
+							{Field: "role", Operator: filter.OpEq, Value: "owner"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "keywords, fiql operators and whitespace",
+			input: "age >= 18 and age < 65 or vip == true",
+			expected: filter.FilterGroup{
+				Logic:   "or",
+				Filters: []filter.Filter{{Field: "vip", Operator: filter.OpEq, Value: "true"}},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "and",
+						Filters: []filter.Filter{
+							{Field: "age", Operator: filter.OpGte, Value: "18"},
+							{Field: "age", Operator: filter.OpLt, Value: "65"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "quoted values",
+			input: `title=="Hello, World";author=='O\'Brien';note=="a \* star"`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "title", Operator: filter.OpEq, Value: "Hello, World"},
+					{Field: "author", Operator: filter.OpEq, Value: "O'Brien"},
+					{Field: "note", Operator: filter.OpEq, Value: "a * star"},
+				},
+			},
+		},
+		{
+			name:  "wildcards",
+			input: "a==*son;b==*oh*;c==j*n_1;d==\"* quoted\"",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "a", Operator: filter.OpEndsWithCS, Value: "son"},
+					{Field: "b", Operator: filter.OpContainsCS, Value: "oh"},
+					{Field: "c", Operator: filter.OpLike, Value: `j%n\_1`},
+					{Field: "d", Operator: filter.OpEndsWithCS, Value: " quoted"},
+				},
+			},
+		},
+		{
+			name:  "negated wildcard",
+			input: "email!=*@test.com",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Not:     true,
+				Filters: []filter.Filter{{Field: "email", Operator: filter.OpEndsWithCS, Value: "@test.com"}},
+			},
+		},
+		{
+			name:  "list, range and null operators",
+			input: "role=out=(guest);price=between=(10,20);deleted_at=null=true;email=null=false",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "role", Operator: filter.OpNin, Value: []any{"guest"}},
+					{Field: "price", Operator: filter.OpBetween, Value: []any{"10", "20"}},
+					{Field: "deleted_at", Operator: filter.OpNull},
+					{Field: "email", Operator: filter.OpNnull},
+				},
+			},
+		},
+		{
+			name:  "wildcards are literal outside == and !=",
+			input: "code=in=(A*,B)",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "code", Operator: filter.OpIn, Value: []any{"A*", "B"}}},
+			},
+		},
+		{
+			name:     "empty expression",
+			input:    "  ",
+			expected: filter.FilterGroup{Logic: "and"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := rsql.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(group, tt.expected) {
+				t.Errorf("\nexpected: %#v\ngot:      %#v", tt.expected, group)
+			}
+		})
+	}
+}
+
+func TestParse_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		pos   int
+		err   error
+	}{
+		{name: "missing operator", input: "name", pos: 4},
+		{name: "missing argument", input: "name==", pos: 6},
+		{name: "unknown operator", input: "age=gte=18", pos: 3, err: filter.ErrUnsupportedOperator},
+		{name: "unterminated operator", input: "age=gt18", pos: 3},
+		{name: "bang without equals", input: "age!18", pos: 3},
+		{name: "dangling separator", input: "a==1;", pos: 5},
+		{name: "unclosed parenthesis", input: "a==1;(b==2,c==3", pos: 5},
+		{name: "unexpected closing parenthesis", input: "a==1)", pos: 4},
+		{name: "unclosed argument list", input: "a=in=(1,2", pos: 5},
+		{name: "unterminated quote", input: `a=="abc`, pos: 3},
+		{name: "quoted selector", input: `"a"==1`, pos: 0},
+		{name: "wildcard selector", input: `a*==1`, pos: 0},
+		{name: "too many arguments", input: "a==(1,2)", pos: 1},
+		{name: "between needs two arguments", input: "a=between=(1)", pos: 1},
+		{name: "invalid null flag", input: "a=null=maybe", pos: 7, err: filter.ErrInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rsql.Parse(tt.input)
+
+			var syntaxErr *rsql.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected SyntaxError, got %v", err)
+			}
+			if syntaxErr.Pos != tt.pos {
+				t.Errorf("expected position %d, got %d (%v)", tt.pos, syntaxErr.Pos, err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestParse_NestingLimit(t *testing.T) {
+	input := ""
+	for range 100 {
+		input += "("
+	}
+	input += "a==1"
+
+	var syntaxErr *rsql.SyntaxError
+	if _, err := rsql.Parse(input); !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected SyntaxError, got %v", err)
+	}
+}
@@ -0,0 +1,594 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SearchTerm is a single term of a parsed search string.
+type SearchTerm struct {
+	// Text is the word, the phrase without its quotes, or the qualifier value
+	Text string
+
+	// Qualifier is the name before the colon of a qualified term such as email:foo
+	// (empty for free text)
+	Qualifier string
+
+	// Phrase reports whether Text was quoted
+	Phrase bool
+
+	// Exclude reports whether the term was prefixed with "-"
+	Exclude bool
+}
+
+// SearchClause is a list of terms combined with OR.
+type SearchClause []SearchTerm
+
+// SearchQuery is a parsed search string. Its clauses are combined with AND.
+type SearchQuery struct {
+	Clauses []SearchClause
+}
+
+// ParseSearch parses a search box string:
+//
+//	john doe                  both words (AND)
+//	"john doe"                the phrase
+//	-spam                     excludes a word, phrase or qualifier
+//	bug OR defect             either word
+//	email:foo is:active       qualifiers, e.g. GitHub-style filters
+//	created:>2024-01-01       qualifier with a comparison
+//	author:"Le Guin"          qualifier with a quoted value
+//
+// OR is case-sensitive and binds two neighbouring terms; a dangling OR is ignored. Parsing is
+// lenient: an unterminated quote runs to the end of the input and never fails.
+func ParseSearch(search string) SearchQuery {
+	var (
+		query  SearchQuery
+		joinOr bool // the previous word was OR
+	)
+
+	for i := 0; i < len(search); {
+		if isSearchSpace(search[i]) {
+			i++
+			continue
+		}
+
+		term, next, raw := scanSearchTerm(search, i)
+		i = next
+
+		if raw == "OR" {
+			joinOr = len(query.Clauses) > 0
+			continue
+		}
+		if raw == "-" || term.Text == "" && term.Qualifier == "" {
+			continue
+		}
+
+		if joinOr {
+			last := len(query.Clauses) - 1
+			query.Clauses[last] = append(query.Clauses[last], term)
+		} else {
+			query.Clauses = append(query.Clauses, SearchClause{term})
+		}
+		joinOr = false
+	}
+
+	return query
+}
+
+func isSearchSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// scanSearchTerm scans the term starting at i and returns it together with the position after
+// it and its raw text.
+func scanSearchTerm(s string, i int) (term SearchTerm, next int, raw string) {
+	start := i
+	if s[i] == '-' && i+1 < len(s) && !isSearchSpace(s[i+1]) {
+		term.Exclude = true
+		i++
+	}
+
+	// qualifier:value, where the qualifier is an identifier
+	if end := qualifierEnd(s, i); end > i && end+1 < len(s) && !isSearchSpace(s[end+1]) {
+		term.Qualifier = s[i:end]
+		i = end + 1
+	}
+
+	if s[i] == '"' {
+		end := strings.IndexByte(s[i+1:], '"')
+		if end < 0 {
+			term.Text, term.Phrase = strings.TrimSpace(s[i+1:]), true
+			return term, len(s), s[start:]
+		}
+		term.Text, term.Phrase = strings.TrimSpace(s[i+1:i+1+end]), true
+		return term, i + end + 2, s[start : i+end+2]
+	}
+
+	end := i
+	for end < len(s) && !isSearchSpace(s[end]) {
+		end++
+	}
+	term.Text = s[i:end]
+	return term, end, s[start:end]
+}
+
+// qualifierEnd returns the position of the colon ending a qualifier name at i, or i if there
+// is none.
+func qualifierEnd(s string, i int) int {
+	for j := i; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case c == ':':
+			if j == i {
+				return i
+			}
+			return j
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case j > i && (c == '.' || c >= '0' && c <= '9'):
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// Searcher turns search strings into predicates: free text is matched through Fields and
+// qualifiers are built through the same field builders as structured filters.
+//
+// Example for Ent:
+//
+//	searcher := filter.Searcher[predicate.User]{
+//	    Fields: filter.SearchFields[predicate.User]{
+//	        user.FieldEmail: user.EmailContainsFold,
+//	        user.FieldName:  user.NameContainsFold,
+//	    },
+//	    Filters:    userFilterBuilders, // from filter.BuildFilterMap
+//	    Qualifiers: map[string]string{"created": "created_at"},
+//	}
+//	query, err = filter.ApplySearchQuery(query, cfg, searcher, predicates, `"john doe" -spam created:>2024-01-01`)
+type Searcher[P any] struct {
+	// Fields match free text and phrases. A term matches if any field or column matches it.
+	Fields SearchFields[P]
+
+	// Columns match free text like Fields, with a match mode and a relevance weight per
+	// column (see SearchScore). A column listed in both is searched through Columns.
+	Columns map[string]SearchField[P]
+
+	// Filters build qualifiers, keyed by field name like the map passed to
+	// ApplyStructuredFilters
+	Filters map[string]FieldFilterBuilder[P]
+
+	// Qualifiers maps qualifier names onto Filters keys, e.g. "created" to "created_at".
+	// Names that are not listed are looked up in Filters directly.
+	Qualifiers map[string]string
+
+	// Analyzer splits and normalizes free text before it reaches Fields. The zero Analyzer
+	// splits on whitespace and leaves words unchanged; quoted phrases skip the tokenizer but
+	// still run through the filters.
+	Analyzer Analyzer
+
+	// IDs is the type of the model's ID. Tokens of that type, such as a pasted UUID, also
+	// match rows by ID through PredicateBuilder.IDIn, which must then be set, and skip the
+	// text fields.
+	IDs SearchType
+
+	// Strategy, when set, matches free text instead of Fields, Columns and Analyzer, e.g.
+	// through a full-text index (see PostgresFullText and SQLiteFTS5). Qualifiers are still
+	// built through Filters.
+	Strategy SearchStrategy[P]
+}
+
+// QualifierFilter converts a qualified term into the filter it stands for. is:X, has:X and
+// no:X check field X for true, not null and null. Other qualifiers compare the field with
+// the value:
+//
+//	status:active           eq
+//	status:active,pending   in
+//	age:>18  age:>=18       gt, gte (and < / <= for lt, lte)
+//	created:2024-01-01..*   between, with * leaving a bound open
+//
+// Quoted values are always compared with eq.
+func QualifierFilter(field string, term SearchTerm) Filter {
+	switch term.Qualifier {
+	case "is":
+		return Filter{Field: field, Operator: OpEq, Value: true}
+	case "has":
+		return Filter{Field: field, Operator: OpNnull}
+	case "no":
+		return Filter{Field: field, Operator: OpNull}
+	}
+
+	value := term.Text
+	if term.Phrase {
+		return Filter{Field: field, Operator: OpEq, Value: value}
+	}
+
+	for _, c := range []struct {
+		prefix string
+		op     Operator
+	}{{">=", OpGte}, {"<=", OpLte}, {">", OpGt}, {"<", OpLt}} {
+		if rest, ok := strings.CutPrefix(value, c.prefix); ok && rest != "" {
+			return Filter{Field: field, Operator: c.op, Value: rest}
+		}
+	}
+	if from, to, ok := strings.Cut(value, ".."); ok && from != "" && to != "" {
+		bound := func(s string) any {
+			if s == "*" {
+				return nil
+			}
+			return s
+		}
+		return Filter{Field: field, Operator: OpBetween, Value: Range{From: bound(from), To: bound(to)}}
+	}
+	if strings.Contains(value, ",") {
+		var values []any
+		for _, v := range strings.Split(value, ",") {
+			if v != "" {
+				values = append(values, v)
+			}
+		}
+		return Filter{Field: field, Operator: OpIn, Value: values}
+	}
+	return Filter{Field: field, Operator: OpEq, Value: value}
+}
+
+// Validate checks that the searcher has every function its configuration needs: a function
+// for each Fields entry, Eq for typed columns, Similar for MatchFuzzy columns, ILike for the
+// other columns and PredicateBuilder.IDIn when IDs is set. Fields, Columns and IDs aren't used
+// when Strategy is set, so they aren't checked then; instead a strategy with a
+// "Validate() error" method, such as PostgresFullText and SQLiteFTS5, checks its own fields.
+//
+// Predicate calls Validate first, so a misconfigured searcher fails every search with
+// ErrInvalidSearcher instead of panicking while handling a request. Call it at startup to
+// catch the problem earlier.
+func (s Searcher[P]) Validate(builder PredicateBuilder[P]) error {
+	if s.Strategy != nil {
+		v, ok := s.Strategy.(interface{ Validate() error })
+		if !ok {
+			return nil
+		}
+		err := v.Validate()
+		if err != nil && !errors.Is(err, ErrInvalidSearcher) {
+			return fmt.Errorf("%w: %w", ErrInvalidSearcher, err)
+		}
+		return err
+	}
+
+	var errs []error
+	if s.IDs != SearchText && builder.IDIn == nil {
+		errs = append(errs, fmt.Errorf("%w: IDs requires PredicateBuilder.IDIn", ErrInvalidSearcher))
+	}
+
+	var names []string
+	for name, fn := range s.Fields {
+		if _, ok := s.Columns[name]; !ok && fn == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = append(errs, fmt.Errorf("%w: field %q has no function", ErrInvalidSearcher, name))
+	}
+
+	names = names[:0]
+	for name := range s.Columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := s.Columns[name]
+		switch {
+		case field.Type != SearchText && field.Eq == nil:
+			errs = append(errs, fmt.Errorf("%w: column %q has Type %q but no Eq", ErrInvalidSearcher, name, field.Type))
+		case field.Type == SearchText && field.Mode == MatchFuzzy && field.Similar == nil:
+			errs = append(errs, fmt.Errorf("%w: fuzzy column %q has no Similar", ErrInvalidSearcher, name))
+		case field.Type == SearchText && field.Mode != MatchFuzzy && field.ILike == nil:
+			errs = append(errs, fmt.Errorf("%w: column %q has no ILike", ErrInvalidSearcher, name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Predicate builds the predicate for a search string. ok is false when the search produces
+// no predicate, e.g. because it is empty or the analyzer dropped every word.
+//
+// Qualifiers whose name does not resolve through Qualifiers and Filters are searched as free
+// text, so "http://example.com" or "re:invoice" still work. For is:X, has:X and no:X the
+// value X is the name that is resolved. Qualifiers that fail to build are reported as an
+// Errors value whose paths, such as "search[2]", give the position of the clause. Excluded
+// terms require PredicateBuilder.Not. A searcher that fails Validate reports its error for
+// every search.
+func (s Searcher[P]) Predicate(search string, builder PredicateBuilder[P]) (predicate P, ok bool, err error) {
+	if err := s.Validate(builder); err != nil {
+		return predicate, false, err
+	}
+
+	query := ParseSearch(search)
+	gb := &groupBuilder[P]{fieldBuilders: s.Filters, predicates: builder, strict: true}
+
+	var texts []searchText
+	if s.Strategy == nil {
+		texts = s.freeText(query)
+	}
+
+	var (
+		text     int
+		fullText SearchQuery // free-text clauses the strategy renders together
+	)
+	clausePredicates := make([]P, 0, len(query.Clauses)+1)
+	for i, clause := range query.Clauses {
+		termPredicates := make([]P, 0, len(clause))
+		var strategyTerms SearchClause
+		for _, term := range clause {
+			var (
+				predicate P
+				ok        bool
+			)
+			if field, found := s.qualifierField(term); found {
+				predicate, ok = s.qualifierPredicate(gb, field, term, i)
+			} else if s.Strategy != nil {
+				strategyTerms = append(strategyTerms, textTerm(term))
+				continue
+			} else {
+				predicate, ok = s.textPredicate(texts[text].tokens, builder)
+				text++
+			}
+			if !ok {
+				continue
+			}
+
+			if term.Exclude {
+				if builder.Not == nil {
+					gb.groupError(fmt.Sprintf("search[%d]", i), fmt.Errorf("%w: excluding terms requires PredicateBuilder.Not", ErrInvalidGroup))
+					continue
+				}
+				predicate = builder.Not(predicate)
+			}
+			termPredicates = append(termPredicates, predicate)
+		}
+
+		if len(strategyTerms) > 0 {
+			// Clauses of free text alone are rendered in one strategy call; text that is OR'd
+			// with qualifiers is rendered on its own
+			if len(termPredicates) == 0 {
+				fullText.Clauses = append(fullText.Clauses, strategyTerms)
+				continue
+			}
+			clauseText := SearchQuery{Clauses: []SearchClause{strategyTerms}}
+			if p, ok := s.strategyPredicate(gb, clauseText, fmt.Sprintf("search[%d]", i)); ok {
+				termPredicates = append(termPredicates, p)
+			}
+		}
+		if p, ok := combinePredicates(termPredicates, builder.Or); ok {
+			clausePredicates = append(clausePredicates, p)
+		}
+	}
+
+	if len(fullText.Clauses) > 0 {
+		if p, ok := s.strategyPredicate(gb, fullText, "search"); ok {
+			clausePredicates = append(clausePredicates, p)
+		}
+	}
+
+	if len(gb.errs) > 0 {
+		return predicate, false, gb.errs
+	}
+	predicate, ok = combinePredicates(clausePredicates, builder.And)
+	return predicate, ok, nil
+}
+
+// qualifierField resolves the qualifier of a term to a Filters key. Terms without a
+// qualifier, or whose qualifier is unknown, are free text.
+func (s Searcher[P]) qualifierField(term SearchTerm) (string, bool) {
+	name := term.Qualifier
+	switch name {
+	case "":
+		return "", false
+	case "is", "has", "no":
+		name = term.Text
+	}
+
+	if field, ok := s.Qualifiers[name]; ok {
+		name = field
+	}
+	if _, ok := s.Filters[name]; ok {
+		return name, true
+	}
+	if segments, err := SplitFieldPath(name); err == nil && len(segments) > 1 {
+		_, ok := s.Filters[segments[0]].(PathBuilder[P])
+		return name, ok
+	}
+	return "", false
+}
+
+// qualifierPredicate builds a qualified term of clause i, recording failures in gb.errs.
+func (s Searcher[P]) qualifierPredicate(gb *groupBuilder[P], field string, term SearchTerm, i int) (P, bool) {
+	f := QualifierFilter(field, term)
+	predicate, err := gb.buildFilter(f)
+	if err != nil {
+		gb.errs = append(gb.errs, &FilterError{
+			Path:     fmt.Sprintf("search[%d]", i),
+			Index:    i,
+			Field:    f.Field,
+			Operator: f.Operator,
+			Err:      err,
+		})
+		return predicate, false
+	}
+	return predicate, true
+}
+
+// textTerm turns a term that isn't a known qualifier into free text, folding an unknown
+// qualifier back into the text.
+func textTerm(term SearchTerm) SearchTerm {
+	if term.Qualifier != "" {
+		term.Text = term.Qualifier + ":" + term.Text
+		term.Qualifier = ""
+	}
+	return term
+}
+
+// strategyPredicate renders free text through the strategy, recording failures at path.
+func (s Searcher[P]) strategyPredicate(gb *groupBuilder[P], query SearchQuery, path string) (P, bool) {
+	predicate, ok, err := s.Strategy.Search(query, gb.predicates)
+	if err != nil {
+		gb.groupError(path, err)
+		return predicate, false
+	}
+	return predicate, ok
+}
+
+// searchText is a free-text term of a search together with its analyzed tokens.
+type searchText struct {
+	exclude bool
+	tokens  []Token
+}
+
+// freeText analyzes the free-text terms of query in order of appearance. They are analyzed in
+// one pass so limits such as MaxTokens apply to the whole search.
+func (s Searcher[P]) freeText(query SearchQuery) []searchText {
+	var (
+		texts   []searchText
+		inputs  []string
+		phrases []bool
+	)
+	for _, clause := range query.Clauses {
+		for _, term := range clause {
+			if _, ok := s.qualifierField(term); ok {
+				continue
+			}
+			term = textTerm(term)
+			texts = append(texts, searchText{exclude: term.Exclude})
+			inputs = append(inputs, term.Text)
+			phrases = append(phrases, term.Phrase)
+		}
+	}
+
+	for _, t := range s.Analyzer.analyze(inputs, phrases) {
+		if t.Position >= 0 && t.Position < len(texts) {
+			texts[t.Position].tokens = append(texts[t.Position].tokens, t)
+		}
+	}
+	return texts
+}
+
+// textPredicate matches the tokens of a term: every token must match, and a token matches if
+// any search field or column matches its text or one of its synonyms.
+//
+// Tokens that parse as the type of IDs or of a typed column only match those, exactly;
+// other tokens match the text fields and columns.
+func (s Searcher[P]) textPredicate(tokens []Token, builder PredicateBuilder[P]) (P, bool) {
+	var names, typed []string
+	for name := range s.Fields {
+		names = append(names, name)
+	}
+	for name, field := range s.Columns {
+		switch {
+		case field.Type != SearchText:
+			typed = append(typed, name)
+		case s.Fields[name] == nil:
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	sort.Strings(typed)
+
+	tokenPredicates := make([]P, 0, len(tokens))
+	for _, token := range tokens {
+		alternatives := append([]string{token.Text}, token.Synonyms...)
+		fieldPredicates := make([]P, 0, len(names)*len(alternatives))
+		for _, alternative := range alternatives {
+			if exact := s.typedPredicates(alternative, typed, builder); len(exact) > 0 {
+				fieldPredicates = append(fieldPredicates, exact...)
+				continue
+			}
+			for _, name := range names {
+				if field, ok := s.Columns[name]; ok {
+					if p, ok := field.predicate(alternative, builder); ok {
+						fieldPredicates = append(fieldPredicates, p)
+					}
+					continue
+				}
+				fieldPredicates = append(fieldPredicates, s.Fields[name](alternative))
+			}
+		}
+		if p, ok := combinePredicates(fieldPredicates, builder.Or); ok {
+			tokenPredicates = append(tokenPredicates, p)
+		}
+	}
+	return combinePredicates(tokenPredicates, builder.And)
+}
+
+// typedToken reports whether a token parses as the type of IDs or of a typed column.
+func (s Searcher[P]) typedToken(token string) bool {
+	if _, ok := s.IDs.Parse(token); ok && s.IDs != SearchText {
+		return true
+	}
+	for _, field := range s.Columns {
+		if _, ok := field.Type.Parse(token); ok && field.Type != SearchText {
+			return true
+		}
+	}
+	return false
+}
+
+// typedPredicates matches a token exactly against the IDs and the typed columns whose type
+// parses it.
+func (s Searcher[P]) typedPredicates(token string, typed []string, builder PredicateBuilder[P]) []P {
+	var predicates []P
+	if s.IDs != SearchText {
+		if value, ok := s.IDs.Parse(token); ok {
+			predicates = append(predicates, builder.IDIn(value))
+		}
+	}
+	for _, name := range typed {
+		field := s.Columns[name]
+		if value, ok := field.Type.Parse(token); ok {
+			predicates = append(predicates, field.Eq(value))
+		}
+	}
+	return predicates
+}
+
+// combinePredicates combines predicates with fn, returning a single predicate unchanged.
+func combinePredicates[P any](predicates []P, fn func(...P) P) (P, bool) {
+	var zero P
+	switch len(predicates) {
+	case 0:
+		return zero, false
+	case 1:
+		return predicates[0], true
+	default:
+		return fn(predicates...), true
+	}
+}
+
+// ApplySearchQuery applies a search string written in the ParseSearch syntax to a query.
+//
+// Unlike ApplySearch, quoted phrases are matched as a whole, "-" excludes terms, OR joins
+// terms and qualifiers such as email:foo or created:>2024-01-01 are built through
+// Searcher.Filters. Invalid qualifier values are reported as an Errors value and the query is
+// returned unchanged.
+//
+// Example:
+//
+//	query, err := filter.ApplySearchQuery(query, cfg, searcher, predicates, input.Search)
+//	if err != nil {
+//	    return nil, huma.Error400BadRequest("invalid search", err)
+//	}
+func ApplySearchQuery[Q any, P any](
+	query Q,
+	cfg Config[Q, P],
+	searcher Searcher[P],
+	builder PredicateBuilder[P],
+	search string,
+) (Q, error) {
+	predicate, ok, err := searcher.Predicate(search, builder)
+	if err != nil || !ok {
+		return query, err
+	}
+	return cfg.Where(query, predicate), nil
+}
@@ -0,0 +1,308 @@
+package filter_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+)
+
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		search   string
+		expected []filter.SearchClause
+	}{
+		{
+			name:   "words and phrases",
+			search: `john "doe smith"  x`,
+			expected: []filter.SearchClause{
+				{{Text: "john"}},
+				{{Text: "doe smith", Phrase: true}},
+				{{Text: "x"}},
+			},
+		},
+		{
+			name:   "exclusions",
+			search: `-spam -"junk mail" - a-b`,
+			expected: []filter.SearchClause{
+				{{Text: "spam", Exclude: true}},
+				{{Text: "junk mail", Phrase: true, Exclude: true}},
+				{{Text: "a-b"}},
+			},
+		},
+		{
+			name:   "or joins neighbouring terms",
+			search: `bug OR defect OR "root cause" crash or fix OR`,
+			expected: []filter.SearchClause{
+				{{Text: "bug"}, {Text: "defect"}, {Text: "root cause", Phrase: true}},
+				{{Text: "crash"}},
+				{{Text: "or"}},
+				{{Text: "fix"}},
+			},
+		},
+		{
+			name:   "leading or is ignored",
+			search: `OR bug`,
+			expected: []filter.SearchClause{
+				{{Text: "bug"}},
+			},
+		},
+		{
+			name:   "qualifiers",
+			search: `email:foo -is:archived created:>2024-01-01 author:"Le Guin" meta.plan:pro`,
+			expected: []filter.SearchClause{
+				{{Qualifier: "email", Text: "foo"}},
+				{{Qualifier: "is", Text: "archived", Exclude: true}},
+				{{Qualifier: "created", Text: ">2024-01-01"}},
+				{{Qualifier: "author", Text: "Le Guin", Phrase: true}},
+				{{Qualifier: "meta.plan", Text: "pro"}},
+			},
+		},
+		{
+			name:   "colons that are not qualifiers",
+			search: `email: :foo 10:30 https://example.com`,
+			expected: []filter.SearchClause{
+				{{Text: "email:"}},
+				{{Text: ":foo"}},
+				{{Text: "10:30"}},
+				{{Qualifier: "https", Text: "//example.com"}},
+			},
+		},
+		{
+			name:   "unterminated quote runs to the end",
+			search: `a "b c`,
+			expected: []filter.SearchClause{
+				{{Text: "a"}},
+				{{Text: "b c", Phrase: true}},
+			},
+		},
+		{
+			name:     "empty",
+			search:   `   ""  `,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := filter.ParseSearch(tt.search)
+			if !reflect.DeepEqual(query.Clauses, tt.expected) {
+				t.Errorf("\nexpected: %#v\ngot:      %#v", tt.expected, query.Clauses)
+			}
+		})
+	}
+}
+
+func TestApplySearchQuery(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+
+	builder := filter.PredicateBuilder[MockPredicate]{
+		Or:  mockOr,
+		And: mockAnd,
+		Not: mockNot,
+	}
+
+	searcher := filter.Searcher[MockPredicate]{
+		Fields: filter.SearchFields[MockPredicate]{
+			"email": mockContainsFold("email"),
+			"name":  mockContainsFold("name"),
+		},
+		Filters: filter.BuildFilterMap(
+			mockCombinators,
+			filter.StringField("email", mockStringPredicates("email")),
+			filter.StringField("status", mockStringPredicates("status")),
+			filter.NullableStringField("phone", mockStringPredicates("phone")),
+			filter.BoolField("archived", mockBoolPredicates("archived")),
+			filter.IntField("age", filter.IntPredicates[MockPredicate, int]{
+				Gt:  mockNumberOp[int]("age", ">"),
+				Gte: mockNumberOp[int]("age", ">="),
+				Lte: mockNumberOp[int]("age", "<="),
+			}),
+			filter.TimeField("created_at", mockTimePredicates("created_at")),
+		),
+		Qualifiers: map[string]string{"created": "created_at"},
+	}
+
+	tests := []struct {
+		name     string
+		search   string
+		expected []string
+	}{
+		{
+			name:     "single word",
+			search:   "john",
+			expected: []string{"(email ILIKE '%john%' OR name ILIKE '%john%')"},
+		},
+		{
+			name:     "phrase is searched as a whole",
+			search:   `"john doe"`,
+			expected: []string{"(email ILIKE '%john doe%' OR name ILIKE '%john doe%')"},
+		},
+		{
+			name:     "exclusion",
+			search:   `john -spam`,
+			expected: []string{"((email ILIKE '%john%' OR name ILIKE '%john%') AND NOT (email ILIKE '%spam%' OR name ILIKE '%spam%'))"},
+		},
+		{
+			name:     "or",
+			search:   `status:active OR status:pending`,
+			expected: []string{"(status = active OR status = pending)"},
+		},
+		{
+			name:     "qualifier operators",
+			search:   `age:>=18 status:a,b created:2024-01-01..*`,
+			expected: []string{"(age >= 18 AND status IN (a,b) AND created_at >= 2024-01-01T00:00:00Z)"},
+		},
+		{
+			name:     "range",
+			search:   `age:18..65`,
+			expected: []string{"(age >= 18 AND age <= 65)"},
+		},
+		{
+			name:     "is, has and no",
+			search:   `-is:archived has:phone OR no:phone`,
+			expected: []string{"(NOT archived = true AND (phone IS NOT NULL OR phone IS NULL))"},
+		},
+		{
+			name:     "quoted qualifier values are compared as is",
+			search:   `status:">pending"`,
+			expected: []string{"status = >pending"},
+		},
+		{
+			name:     "unknown qualifiers are free text",
+			search:   `re:invoice`,
+			expected: []string{"(email ILIKE '%re:invoice%' OR name ILIKE '%re:invoice%')"},
+		},
+		{
+			name:     "empty search",
+			search:   "   ",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := filter.ApplySearchQuery(&MockQuery{}, cfg, searcher, builder, tt.search)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(query.predicates, tt.expected) {
+				t.Errorf("\nexpected: %v\ngot:      %v", tt.expected, query.predicates)
+			}
+		})
+	}
+
+	t.Run("invalid qualifiers are reported", func(t *testing.T) {
+		_, err := filter.ApplySearchQuery(&MockQuery{}, cfg, searcher, builder, `john age:>old created:>yesterdayish`)
+
+		var filterErrs filter.Errors
+		if !errors.As(err, &filterErrs) {
+			t.Fatalf("expected filter.Errors, got %T: %v", err, err)
+		}
+		if len(filterErrs) != 2 || filterErrs[0].Path != "search[1]" || filterErrs[1].Path != "search[2]" {
+			t.Fatalf("unexpected errors: %v", err)
+		}
+		if !errors.Is(filterErrs[0], filter.ErrInvalidValue) {
+			t.Errorf("expected ErrInvalidValue, got %v", filterErrs[0])
+		}
+	})
+
+	t.Run("exclusion requires not", func(t *testing.T) {
+		noNot := filter.PredicateBuilder[MockPredicate]{Or: mockOr, And: mockAnd}
+		_, err := filter.ApplySearchQuery(&MockQuery{}, cfg, searcher, noNot, `-spam`)
+		if !errors.Is(err, filter.ErrInvalidGroup) {
+			t.Fatalf("expected ErrInvalidGroup, got %v", err)
+		}
+	})
+}
+
+func TestApplySearchQuery_Analyzer(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+
+	builder := filter.PredicateBuilder[MockPredicate]{
+		Or:  mockOr,
+		And: mockAnd,
+		Not: mockNot,
+	}
+
+	searcher := filter.Searcher[MockPredicate]{
+		Fields: filter.SearchFields[MockPredicate]{
+			"title": mockContainsFold("title"),
+		},
+		Filters: filter.BuildFilterMap(
+			mockCombinators,
+			filter.StringField("status", mockStringPredicates("status")),
+		),
+		Analyzer: filter.Analyzer{
+			Tokenizer: filter.CJKTokenizer,
+			Filters: []filter.TokenFilter{
+				filter.Lowercase,
+				filter.FoldAccents,
+				filter.Stopwords(filter.EnglishStopwords...),
+				filter.Synonyms(map[string][]string{"nyc": {"new york"}}),
+				filter.MaxTokens(3),
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		search   string
+		expected []string
+	}{
+		{
+			name:     "stopwords are dropped and words normalized",
+			search:   "The Café",
+			expected: []string{"title ILIKE '%cafe%'"},
+		},
+		{
+			name:     "synonyms are alternatives",
+			search:   "NYC",
+			expected: []string{"(title ILIKE '%nyc%' OR title ILIKE '%new york%')"},
+		},
+		{
+			name:     "phrases are normalized but not split",
+			search:   `"Crème Brûlée"`,
+			expected: []string{"title ILIKE '%creme brulee%'"},
+		},
+		{
+			name:     "cjk words are split into bigrams that must all match",
+			search:   "東京都",
+			expected: []string{"(title ILIKE '%東京%' AND title ILIKE '%京都%')"},
+		},
+		{
+			name:     "token limit spans the whole search and skips qualifiers",
+			search:   "one two status:open three four five",
+			expected: []string{"(title ILIKE '%one%' AND title ILIKE '%two%' AND status = open AND title ILIKE '%three%')"},
+		},
+		{
+			name:     "only stopwords",
+			search:   "the and -of",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := filter.ApplySearchQuery(&MockQuery{}, cfg, searcher, builder, tt.search)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(query.predicates, tt.expected) {
+				t.Errorf("\nexpected: %v\ngot:      %v", tt.expected, query.predicates)
+			}
+		})
+	}
+}
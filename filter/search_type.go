@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SearchType is the kind of value a search column holds. Columns of a type other than
+// SearchText only receive tokens the type parses, and match them exactly.
+type SearchType string
+
+const (
+	SearchText    SearchType = ""        // Any token, matched through the column's Mode (default)
+	SearchUUID    SearchType = "uuid"    // Tokens accepted by ParseUUIDString, like 3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6f
+	SearchInteger SearchType = "integer" // Tokens like 1042 or -7
+	SearchDate    SearchType = "date"    // Tokens like 2024-01-31
+	SearchEmail   SearchType = "email"   // Tokens like ada@example.com
+)
+
+// Parse converts a search token into a value of the type. ok is false if the token isn't of
+// the type; SearchText accepts every token. Values are:
+//
+//	SearchUUID     the UUID in the canonical form returned by ParseUUIDString
+//	SearchInteger  an int64
+//	SearchDate     a time.Time at midnight UTC
+//	SearchEmail    the address as a lower-case string
+func (t SearchType) Parse(token string) (value any, ok bool) {
+	switch t {
+	case SearchText:
+		return token, true
+	case SearchUUID:
+		id, err := ParseUUIDString(token)
+		if err != nil {
+			return nil, false
+		}
+		return id, true
+	case SearchInteger:
+		n, err := strconv.ParseInt(token, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		return n, true
+	case SearchDate:
+		d, err := time.Parse("2006-01-02", token)
+		if err != nil {
+			return nil, false
+		}
+		return d, true
+	case SearchEmail:
+		addr, err := mail.ParseAddress(token)
+		if err != nil || addr.Address != token || addr.Name != "" {
+			return nil, false
+		}
+		return strings.ToLower(token), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,236 @@
+package filter_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tone-labs/dewey/filter"
+)
+
+func TestSearchType_Parse(t *testing.T) {
+	tests := []struct {
+		typ      filter.SearchType
+		token    string
+		expected any
+		ok       bool
+	}{
+		{typ: filter.SearchText, token: "anything", expected: "anything", ok: true},
+		{typ: filter.SearchUUID, token: "3F2B8C1E-5D4A-4C2B-9E7F-1A2B3C4D5E6F", expected: "3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6f", ok: true},
+		{typ: filter.SearchUUID, token: "3f2b8c1e5d4a4c2b9e7f1a2b3c4d5e6f", expected: "3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6f", ok: true},
+		{typ: filter.SearchUUID, token: "{3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6f}", expected: "3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6f", ok: true},
+		{typ: filter.SearchUUID, token: "urn:uuid:3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6f", expected: "3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6f", ok: true},
+		{typ: filter.SearchUUID, token: "3f2b8c1e-5d4a4c2b-9e7f-1a2b3c4d5e6f"},
+		{typ: filter.SearchUUID, token: "3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6g"},
+		{typ: filter.SearchInteger, token: "1042", expected: int64(1042), ok: true},
+		{typ: filter.SearchInteger, token: "-7", expected: int64(-7), ok: true},
+		{typ: filter.SearchInteger, token: "10.5"},
+		{typ: filter.SearchDate, token: "2024-01-31", expected: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), ok: true},
+		{typ: filter.SearchDate, token: "2024-02-30"},
+		{typ: filter.SearchEmail, token: "Ada@Example.com", expected: "ada@example.com", ok: true},
+		{typ: filter.SearchEmail, token: "ada@"},
+		{typ: filter.SearchEmail, token: "<ada@example.com>"},
+		{typ: "ipv4", token: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.typ)+" "+tt.token, func(t *testing.T) {
+			value, ok := tt.typ.Parse(tt.token)
+			if ok != tt.ok || !reflect.DeepEqual(value, tt.expected) {
+				t.Errorf("expected %v (%t), got %v (%t)", tt.expected, tt.ok, value, ok)
+			}
+		})
+	}
+}
+
+func TestSearcher_TypedColumns(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+	builder := filter.PredicateBuilder[MockPredicate]{IDIn: mockIDIn, Or: mockOr, And: mockAnd, Not: mockNot}
+
+	eq := func(field string) func(any) MockPredicate {
+		return func(v any) MockPredicate {
+			if d, ok := v.(time.Time); ok {
+				v = d.Format(time.DateOnly)
+			}
+			return MockPredicate(fmt.Sprintf("%s = %v", field, v))
+		}
+	}
+
+	searcher := filter.Searcher[MockPredicate]{
+		Fields: filter.SearchFields[MockPredicate]{
+			"name": mockContainsFold("name"),
+		},
+		Columns: map[string]filter.SearchField[MockPredicate]{
+			"notes":        {ILike: mockILike("notes")},
+			"order_number": {Type: filter.SearchInteger, Eq: eq("order_number")},
+			"placed_on":    {Type: filter.SearchDate, Eq: eq("placed_on")},
+			"email":        {Type: filter.SearchEmail, Weight: 5, Eq: eq("email")},
+		},
+		IDs: filter.SearchUUID,
+	}
+
+	tests := []struct {
+		name     string
+		search   string
+		expected []string
+	}{
+		{
+			name:     "uuid matches the id",
+			search:   "3F2B8C1E-5D4A-4C2B-9E7F-1A2B3C4D5E6F",
+			expected: []string{"ID IN (3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6f)"},
+		},
+		{
+			name:     "integer and date columns",
+			search:   "1042 2024-01-31",
+			expected: []string{"(order_number = 1042 AND placed_on = 2024-01-31)"},
+		},
+		{
+			name:     "email column",
+			search:   "Ada@Example.com",
+			expected: []string{"email = ada@example.com"},
+		},
+		{
+			name:     "text skips typed columns",
+			search:   "ada",
+			expected: []string{"(name ILIKE '%ada%' OR notes ILIKE '%ada%')"},
+		},
+		{
+			name:     "excluded ids",
+			search:   "ada -3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6f",
+			expected: []string{"((name ILIKE '%ada%' OR notes ILIKE '%ada%') AND NOT ID IN (3f2b8c1e-5d4a-4c2b-9e7f-1a2b3c4d5e6f))"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := filter.ApplySearchQuery(&MockQuery{}, cfg, searcher, builder, tt.search)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(query.predicates, tt.expected) {
+				t.Errorf("\nexpected: %v\ngot:      %v", tt.expected, query.predicates)
+			}
+		})
+	}
+
+	t.Run("typed tokens are not scored", func(t *testing.T) {
+		score, ok := filter.SearchScore(searcher, "ada 1042", filter.SQLScoreBuilder{})
+		if !ok {
+			t.Fatal("expected a score")
+		}
+		expected := filter.SQLExpr{
+			SQL:  `CASE WHEN LOWER(notes) LIKE ? ESCAPE '\' THEN 1 ELSE 0 END`,
+			Args: []any{"%ada%"},
+		}
+		if !reflect.DeepEqual(score, expected) {
+			t.Errorf("\nexpected: %#v\ngot:      %#v", expected, score)
+		}
+	})
+}
+
+func TestSearcher_Validate(t *testing.T) {
+	builder := filter.PredicateBuilder[MockPredicate]{IDIn: mockIDIn, Or: mockOr, And: mockAnd}
+	noIDIn := filter.PredicateBuilder[MockPredicate]{Or: mockOr, And: mockAnd}
+
+	tests := []struct {
+		name     string
+		searcher filter.Searcher[MockPredicate]
+		builder  filter.PredicateBuilder[MockPredicate]
+		err      bool
+	}{
+		{
+			name: "complete",
+			searcher: filter.Searcher[MockPredicate]{
+				Fields:  filter.SearchFields[MockPredicate]{"name": mockContainsFold("name")},
+				Columns: map[string]filter.SearchField[MockPredicate]{"notes": {ILike: mockILike("notes")}},
+				IDs:     filter.SearchUUID,
+			},
+			builder: builder,
+		},
+		{
+			name:     "ids without IDIn",
+			searcher: filter.Searcher[MockPredicate]{IDs: filter.SearchUUID},
+			builder:  noIDIn,
+			err:      true,
+		},
+		{
+			name: "typed column without Eq",
+			searcher: filter.Searcher[MockPredicate]{
+				Columns: map[string]filter.SearchField[MockPredicate]{"order_number": {Type: filter.SearchInteger}},
+			},
+			builder: builder,
+			err:     true,
+		},
+		{
+			name: "fuzzy column without Similar",
+			searcher: filter.Searcher[MockPredicate]{
+				Columns: map[string]filter.SearchField[MockPredicate]{"name": {Mode: filter.MatchFuzzy, ILike: mockILike("name")}},
+			},
+			builder: builder,
+			err:     true,
+		},
+		{
+			name: "text column without ILike",
+			searcher: filter.Searcher[MockPredicate]{
+				Columns: map[string]filter.SearchField[MockPredicate]{"notes": {Mode: filter.MatchPrefix}},
+			},
+			builder: builder,
+			err:     true,
+		},
+		{
+			name: "field without function",
+			searcher: filter.Searcher[MockPredicate]{
+				Fields: filter.SearchFields[MockPredicate]{"name": nil},
+			},
+			builder: builder,
+			err:     true,
+		},
+		{
+			name: "complete strategy",
+			searcher: filter.Searcher[MockPredicate]{
+				Strategy: filter.PostgresFullText[MockPredicate]{Column: "tsv", Where: mockWhere},
+			},
+			builder: builder,
+		},
+		{
+			name: "full-text strategy without Where",
+			searcher: filter.Searcher[MockPredicate]{
+				Strategy: filter.PostgresFullText[MockPredicate]{Column: "tsv"},
+			},
+			builder: builder,
+			err:     true,
+		},
+		{
+			name: "fts5 strategy without Table",
+			searcher: filter.Searcher[MockPredicate]{
+				Strategy: filter.SQLiteFTS5[MockPredicate]{Where: mockWhere},
+			},
+			builder: builder,
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.searcher.Validate(tt.builder)
+			if tt.err != errors.Is(err, filter.ErrInvalidSearcher) || !tt.err && err != nil {
+				t.Fatalf("expected error %t, got %v", tt.err, err)
+			}
+			if !tt.err {
+				return
+			}
+
+			// Predicate reports the same error instead of panicking on the missing function
+			if _, _, err := tt.searcher.Predicate("42 ada", tt.builder); !errors.Is(err, filter.ErrInvalidSearcher) {
+				t.Errorf("expected Predicate to report ErrInvalidSearcher, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SearchStrategy matches the free text of a search, e.g. through a full-text index. Set it on
+// Searcher.Strategy to choose how each model is searched. Strategies that also have a
+// "Validate() error" method are checked by Searcher.Validate.
+type SearchStrategy[P any] interface {
+	// Search builds the predicate for free-text terms. The clauses of query are combined with
+	// AND and the terms of a clause with OR, like in ParseSearch; qualifiers have already been
+	// removed. ok is false when query produces no predicate.
+	Search(query SearchQuery, builder PredicateBuilder[P]) (predicate P, ok bool, err error)
+}
+
+// composeSearch builds one predicate per term with match and combines them through builder:
+// terms of a clause with Or and clauses with And. Excluded terms are built with exclude when
+// it is set and wrapped in Not otherwise.
+func composeSearch[P any](query SearchQuery, builder PredicateBuilder[P], match, exclude func(SearchTerm) P) (P, bool, error) {
+	var zero P
+	clausePredicates := make([]P, 0, len(query.Clauses))
+	for _, clause := range query.Clauses {
+		termPredicates := make([]P, 0, len(clause))
+		for _, term := range clause {
+			var predicate P
+			switch {
+			case !term.Exclude:
+				predicate = match(term)
+			case exclude != nil:
+				predicate = exclude(term)
+			case builder.Not == nil:
+				return zero, false, fmt.Errorf("%w: excluding terms requires PredicateBuilder.Not", ErrInvalidGroup)
+			default:
+				predicate = builder.Not(match(term))
+			}
+			termPredicates = append(termPredicates, predicate)
+		}
+		if p, ok := combinePredicates(termPredicates, builder.Or); ok {
+			clausePredicates = append(clausePredicates, p)
+		}
+	}
+	predicate, ok := combinePredicates(clausePredicates, builder.And)
+	return predicate, ok, nil
+}
+
+// PostgresFullText searches a tsvector column. By default the whole search becomes a single
+// predicate through websearch_to_tsquery (PostgreSQL 11+), which understands the same quoted
+// phrases, "-" exclusions and OR as ParseSearch:
+//
+//	search_vector @@ websearch_to_tsquery(?::regconfig, ?)
+//
+// Example for Ent:
+//
+//	searcher.Strategy = filter.PostgresFullText[predicate.Post]{
+//	    Column: "search_vector",
+//	    Config: "english",
+//	    Where: func(query string, args ...any) predicate.Post {
+//	        return predicate.Post(func(s *sql.Selector) { s.Where(sql.ExprP(query, args...)) })
+//	    },
+//	}
+type PostgresFullText[P any] struct {
+	// Column is the tsvector column or expression, e.g. "search_vector" or
+	// "to_tsvector('english', title)". It is written into the SQL as given, so it must come
+	// from code, never from user input.
+	Column string
+
+	// Config is the text search configuration, e.g. "english" (the server's
+	// default_text_search_config if empty)
+	Config string
+
+	// Plain uses plainto_tsquery for words and phraseto_tsquery for phrases, one predicate
+	// per term combined through the PredicateBuilder, for servers without
+	// websearch_to_tsquery
+	Plain bool
+
+	// Where builds a predicate from SQL with ? placeholders
+	Where func(sql string, args ...any) P
+}
+
+// Validate reports a missing Column or Where as ErrInvalidSearcher.
+func (s PostgresFullText[P]) Validate() error {
+	var errs []error
+	if s.Column == "" {
+		errs = append(errs, fmt.Errorf("%w: PostgresFullText has no Column", ErrInvalidSearcher))
+	}
+	if s.Where == nil {
+		errs = append(errs, fmt.Errorf("%w: PostgresFullText has no Where", ErrInvalidSearcher))
+	}
+	return errors.Join(errs...)
+}
+
+// Search implements SearchStrategy.
+func (s PostgresFullText[P]) Search(query SearchQuery, builder PredicateBuilder[P]) (P, bool, error) {
+	if s.Plain {
+		return composeSearch(query, builder, func(term SearchTerm) P {
+			function := "plainto_tsquery"
+			if term.Phrase {
+				function = "phraseto_tsquery"
+			}
+			return s.match(function, term.Text)
+		}, nil)
+	}
+
+	var zero P
+	text := WebSearchString(query)
+	if text == "" {
+		return zero, false, nil
+	}
+	return s.match("websearch_to_tsquery", text), true, nil
+}
+
+// match builds "column @@ function(config, text)".
+func (s PostgresFullText[P]) match(function, text string) P {
+	if s.Config == "" {
+		return s.Where(s.Column+" @@ "+function+"(?)", text)
+	}
+	return s.Where(s.Column+" @@ "+function+"(?::regconfig, ?)", s.Config, text)
+}
+
+// WebSearchString formats a search in the syntax of websearch_to_tsquery: terms of a clause
+// are joined with "or", phrases are quoted and excluded terms start with "-". Words that
+// websearch_to_tsquery would read as operators are quoted too.
+func WebSearchString(query SearchQuery) string {
+	clauses := make([]string, 0, len(query.Clauses))
+	for _, clause := range query.Clauses {
+		terms := make([]string, 0, len(clause))
+		for _, term := range clause {
+			text := term.Text
+			if term.Phrase || strings.EqualFold(text, "or") || strings.HasPrefix(text, "-") {
+				text = `"` + text + `"`
+			}
+			if term.Exclude {
+				text = "-" + text
+			}
+			terms = append(terms, text)
+		}
+		clauses = append(clauses, strings.Join(terms, " or "))
+	}
+	return strings.Join(clauses, " ")
+}
+
+// SQLiteFTS5 searches an SQLite FTS5 table with a single MATCH predicate:
+//
+//	documents_fts MATCH ?                                                      (Table only)
+//	id IN (SELECT rowid FROM documents_fts WHERE documents_fts MATCH ?)        (Table and RowID)
+//
+// Every term is passed as an FTS5 string, so user input can't inject FTS5 operators.
+// Searches that FTS5 can't express, such as only excluded terms or an excluded term inside an
+// OR, fall back to one rowid subquery per term combined through the PredicateBuilder. FTS5
+// refuses a MATCH wrapped in NOT, so the fallback uses the subquery form even without RowID and
+// excluded terms become NOT IN:
+//
+//	rowid IN (SELECT rowid FROM documents_fts WHERE documents_fts MATCH ?)
+//	rowid NOT IN (SELECT rowid FROM documents_fts WHERE documents_fts MATCH ?)
+//
+// Example:
+//
+//	searcher.Strategy = filter.SQLiteFTS5[predicate.Document]{
+//	    Table: "documents_fts",
+//	    RowID: "id",
+//	    Where: func(query string, args ...any) predicate.Document {
+//	        return predicate.Document(func(s *sql.Selector) { s.Where(sql.ExprP(query, args...)) })
+//	    },
+//	}
+type SQLiteFTS5[P any] struct {
+	// Table is the FTS5 table. It is written into the SQL as given, so it must come from code,
+	// never from user input.
+	Table string
+
+	// RowID is the column of the searched table holding the FTS5 rowid, for FTS5 tables kept
+	// alongside the searched table (match the FTS5 table directly if empty)
+	RowID string
+
+	// Where builds a predicate from SQL with ? placeholders
+	Where func(sql string, args ...any) P
+}
+
+// Validate reports a missing Table or Where as ErrInvalidSearcher.
+func (s SQLiteFTS5[P]) Validate() error {
+	var errs []error
+	if s.Table == "" {
+		errs = append(errs, fmt.Errorf("%w: SQLiteFTS5 has no Table", ErrInvalidSearcher))
+	}
+	if s.Where == nil {
+		errs = append(errs, fmt.Errorf("%w: SQLiteFTS5 has no Where", ErrInvalidSearcher))
+	}
+	return errors.Join(errs...)
+}
+
+// Search implements SearchStrategy.
+func (s SQLiteFTS5[P]) Search(query SearchQuery, builder PredicateBuilder[P]) (P, bool, error) {
+	if expr, ok := FTS5Expression(query); ok {
+		var zero P
+		if expr == "" {
+			return zero, false, nil
+		}
+		return s.match(expr), true, nil
+	}
+	return composeSearch(query, builder, func(term SearchTerm) P {
+		return s.subquery("IN", fts5String(term.Text))
+	}, func(term SearchTerm) P {
+		return s.subquery("NOT IN", fts5String(term.Text))
+	})
+}
+
+// match builds the MATCH predicate for an FTS5 expression.
+func (s SQLiteFTS5[P]) match(expr string) P {
+	if s.RowID == "" {
+		return s.Where(s.Table+" MATCH ?", expr)
+	}
+	return s.subquery("IN", expr)
+}
+
+// subquery builds "rowid IN (SELECT rowid ... MATCH ?)" with op IN or NOT IN, comparing RowID
+// instead of rowid when it is set.
+func (s SQLiteFTS5[P]) subquery(op, expr string) P {
+	column := s.RowID
+	if column == "" {
+		column = "rowid"
+	}
+	return s.Where(column+" "+op+" (SELECT rowid FROM "+s.Table+" WHERE "+s.Table+" MATCH ?)", expr)
+}
+
+// FTS5Expression formats a search as an FTS5 query: every term is an FTS5 string, terms of a
+// clause are joined with OR and clauses with AND, and excluded terms follow NOT. ok is false
+// when FTS5 can't express the search, because it has no positive terms or a clause ORs an
+// excluded term, since NOT in FTS5 needs a left-hand side.
+func FTS5Expression(query SearchQuery) (expr string, ok bool) {
+	var include, exclude []string
+	for _, clause := range query.Clauses {
+		if len(clause) == 1 && clause[0].Exclude {
+			exclude = append(exclude, fts5String(clause[0].Text))
+			continue
+		}
+		terms := make([]string, len(clause))
+		for i, term := range clause {
+			if term.Exclude {
+				return "", false
+			}
+			terms[i] = fts5String(term.Text)
+		}
+		if len(terms) > 1 {
+			include = append(include, "("+strings.Join(terms, " OR ")+")")
+		} else {
+			include = append(include, terms[0])
+		}
+	}
+
+	if len(include) == 0 {
+		return "", len(exclude) == 0
+	}
+	expr = strings.Join(include, " AND ")
+	for _, e := range exclude {
+		expr += " NOT " + e
+	}
+	return expr, true
+}
+
+// fts5String quotes text as an FTS5 string, which FTS5 matches as a phrase.
+func fts5String(text string) string {
+	return `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+}
@@ -0,0 +1,135 @@
+package filter_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+)
+
+// mockWhere renders raw SQL predicates with their arguments inlined
+func mockWhere(sql string, args ...any) MockPredicate {
+	for _, arg := range args {
+		sql = strings.Replace(sql, "?", fmt.Sprintf("'%v'", arg), 1)
+	}
+	return MockPredicate(sql)
+}
+
+func TestWebSearchString(t *testing.T) {
+	tests := []struct {
+		search   string
+		expected string
+	}{
+		{search: `john doe`, expected: `john doe`},
+		{search: `"john doe" -spam`, expected: `"john doe" -spam`},
+		{search: `bug OR defect -"won't fix"`, expected: `bug or defect -"won't fix"`},
+		{search: `crash or --x`, expected: `crash "or" -"-x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			if got := filter.WebSearchString(filter.ParseSearch(tt.search)); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFTS5Expression(t *testing.T) {
+	tests := []struct {
+		search   string
+		expected string
+		ok       bool
+	}{
+		{search: `john doe`, expected: `"john" AND "doe"`, ok: true},
+		{search: `"john doe" -spam -junk`, expected: `"john doe" NOT "spam" NOT "junk"`, ok: true},
+		{search: `bug OR defect NEAR`, expected: `("bug" OR "defect") AND "NEAR"`, ok: true},
+		{search: `say"hi"`, expected: `"say""hi"""`, ok: true},
+		{search: `-spam`, ok: false},
+		{search: `bug OR -defect`, ok: false},
+		{search: ``, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			expr, ok := filter.FTS5Expression(filter.ParseSearch(tt.search))
+			if expr != tt.expected || ok != tt.ok {
+				t.Errorf("expected %s (%t), got %s (%t)", tt.expected, tt.ok, expr, ok)
+			}
+		})
+	}
+}
+
+func TestSearcher_Strategy(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+	builder := filter.PredicateBuilder[MockPredicate]{Or: mockOr, And: mockAnd, Not: mockNot}
+
+	filters := filter.BuildFilterMap(
+		mockCombinators,
+		filter.StringField("status", mockStringPredicates("status")),
+	)
+
+	tests := []struct {
+		name     string
+		strategy filter.SearchStrategy[MockPredicate]
+		search   string
+		expected []string
+	}{
+		{
+			name:     "postgres websearch",
+			strategy: filter.PostgresFullText[MockPredicate]{Column: "tsv", Config: "english", Where: mockWhere},
+			search:   `"john doe" -spam bug OR defect status:open`,
+			expected: []string{`(status = open AND tsv @@ websearch_to_tsquery('english'::regconfig, '"john doe" -spam bug or defect'))`},
+		},
+		{
+			name:     "postgres text ored with a qualifier",
+			strategy: filter.PostgresFullText[MockPredicate]{Column: "tsv", Where: mockWhere},
+			search:   `urgent OR status:open later`,
+			expected: []string{`((status = open OR tsv @@ websearch_to_tsquery('urgent')) AND tsv @@ websearch_to_tsquery('later'))`},
+		},
+		{
+			name:     "postgres plain",
+			strategy: filter.PostgresFullText[MockPredicate]{Column: "tsv", Plain: true, Where: mockWhere},
+			search:   `"john doe" -spam`,
+			expected: []string{`(tsv @@ phraseto_tsquery('john doe') AND NOT tsv @@ plainto_tsquery('spam'))`},
+		},
+		{
+			name:     "sqlite fts5",
+			strategy: filter.SQLiteFTS5[MockPredicate]{Table: "docs_fts", Where: mockWhere},
+			search:   `"john doe" -spam re:invoice`,
+			expected: []string{`docs_fts MATCH '"john doe" AND "re:invoice" NOT "spam"'`},
+		},
+		{
+			name:     "sqlite fts5 rowid and fallback",
+			strategy: filter.SQLiteFTS5[MockPredicate]{Table: "docs_fts", RowID: "id", Where: mockWhere},
+			search:   `-spam`,
+			expected: []string{`id NOT IN (SELECT rowid FROM docs_fts WHERE docs_fts MATCH '"spam"')`},
+		},
+		{
+			name:     "sqlite fts5 fallback without rowid",
+			strategy: filter.SQLiteFTS5[MockPredicate]{Table: "docs_fts", Where: mockWhere},
+			search:   `bug OR -spam`,
+			expected: []string{`(rowid IN (SELECT rowid FROM docs_fts WHERE docs_fts MATCH '"bug"') OR rowid NOT IN (SELECT rowid FROM docs_fts WHERE docs_fts MATCH '"spam"'))`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := filter.Searcher[MockPredicate]{Filters: filters, Strategy: tt.strategy}
+			query, err := filter.ApplySearchQuery(&MockQuery{}, cfg, searcher, builder, tt.search)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(query.predicates, tt.expected) {
+				t.Errorf("\nexpected: %v\ngot:      %v", tt.expected, query.predicates)
+			}
+		})
+	}
+}
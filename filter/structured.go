@@ -1,5 +1,11 @@
 package filter
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // Operator represents a filter comparison operator
 type Operator string
 
@@ -23,22 +29,127 @@ const (
 	OpStartsWith Operator = "startswith" // Starts with (case-insensitive)
 	OpEndsWith   Operator = "endswith"   // Ends with (case-insensitive)
 
+	// Negated and case-sensitive string operators
+	OpNcontains    Operator = "ncontains"   // Does not contain substring (case-insensitive)
+	OpNstartsWith  Operator = "nstartswith" // Does not start with (case-insensitive)
+	OpNendsWith    Operator = "nendswith"   // Does not end with (case-insensitive)
+	OpContainsCS   Operator = "containss"   // Contains substring (case-sensitive)
+	OpStartsWithCS Operator = "startswiths" // Starts with (case-sensitive)
+	OpEndsWithCS   Operator = "endswiths"   // Ends with (case-sensitive)
+
+	// Pattern operators (patterns are validated with ValidateRegex and ValidateLike)
+	OpRegex  Operator = "regex"  // Matches regular expression (RE2 syntax)
+	OpNregex Operator = "nregex" // Does not match regular expression
+	OpLike   Operator = "like"   // Matches SQL LIKE pattern with explicit % and _ wildcards
+	OpIlike  Operator = "ilike"  // Matches SQL LIKE pattern, ignoring case
+
+	// Range operators (value is a two-element array or a Range)
+	OpBetween  Operator = "between"  // Within range (inclusive unless the Range says otherwise)
+	OpNbetween Operator = "nbetween" // Outside range
+
 	// Null operators
 	OpNull  Operator = "null"  // Is null
 	OpNnull Operator = "nnull" // Is not null
+
+	// Document operators
+	OpHasKey Operator = "haskey" // Key exists at path (JSON fields)
+
+	// Array column operators
+	OpHas    Operator = "has"    // Array contains the element
+	OpHasAll Operator = "hasall" // Array contains all of the elements
+	OpHasAny Operator = "hasany" // Array contains at least one of the elements (overlaps)
+	OpLen    Operator = "len"    // Array length equal to
+	OpLenGt  Operator = "lengt"  // Array length greater than
+	OpLenGte Operator = "lengte" // Array length greater than or equal to
+	OpLenLt  Operator = "lenlt"  // Array length less than
+	OpLenLte Operator = "lenlte" // Array length less than or equal to
 )
 
+// extendedOperators lists the operators that have no FieldFilterBuilder method.
+// They can only be built by builders implementing OperatorBuilder or PathBuilder;
+// ApplyStructuredFilters skips filters using them otherwise, or when Build rejects them.
+var extendedOperators = map[Operator]bool{
+	OpNcontains:    true,
+	OpNstartsWith:  true,
+	OpNendsWith:    true,
+	OpContainsCS:   true,
+	OpStartsWithCS: true,
+	OpEndsWithCS:   true,
+	OpRegex:        true,
+	OpNregex:       true,
+	OpLike:         true,
+	OpIlike:        true,
+	OpBetween:      true,
+	OpNbetween:     true,
+	OpHasKey:       true,
+	OpHas:          true,
+	OpHasAll:       true,
+	OpHasAny:       true,
+	OpLen:          true,
+	OpLenGt:        true,
+	OpLenGte:       true,
+	OpLenLt:        true,
+	OpLenLte:       true,
+}
+
+// negatedOperators maps negated string operators to their positive form. When a builder has
+// no dedicated predicate function for a negated operator, the positive predicate is wrapped in
+// PredicateBuilder.Not instead.
+var negatedOperators = map[Operator]Operator{
+	OpNcontains:   OpContains,
+	OpNstartsWith: OpStartsWith,
+	OpNendsWith:   OpEndsWith,
+}
+
 // Filter represents a single field filter with an operator and value
 type Filter struct {
-	Field    string   // The field name to filter on
-	Operator Operator // The comparison operator
-	Value    any      // The value to compare against
+	Field    string   `json:"field"`    // The field name to filter on
+	Operator Operator `json:"operator"` // The comparison operator
+	Value    any      `json:"value"`    // The value to compare against
 }
 
-// FilterGroup represents a group of filters combined with AND or OR logic
+// FilterGroup represents a group of filters combined with AND or OR logic.
+//
+// Groups can be nested: each child group is evaluated on its own and then combined
+// with the group's filters using the group's Logic. Setting Not negates the combined
+// result of the whole group, which requires PredicateBuilder.Not to be set; without it,
+// ApplyStructuredFilters skips negated groups and ApplyStructuredFiltersE reports them.
+//
+// Example JSON for `status = active AND NOT (role = admin OR created_at > X)`:
+//
+//	{
+//	    "logic": "and",
+//	    "filters": [{"field": "status", "operator": "eq", "value": "active"}],
+//	    "groups": [{
+//	        "logic": "or",
+//	        "not": true,
+//	        "filters": [
+//	            {"field": "role", "operator": "eq", "value": "admin"},
+//	            {"field": "created_at", "operator": "gt", "value": "2024-01-01"}
+//	        ]
+//	    }]
+//	}
+//
+// Flat payloads without "groups" or "not" decode exactly as before.
 type FilterGroup struct {
-	Filters []Filter // The filters to apply
-	Logic   string   // "and" or "or"
+	Filters []Filter      `json:"filters"`          // The filters to apply
+	Groups  []FilterGroup `json:"groups,omitempty"` // Nested child groups
+	Logic   string        `json:"logic"`            // "and" or "or"
+	Not     bool          `json:"not,omitempty"`    // Negate the combined result of the group
+}
+
+// Range is the value of a between/nbetween filter. Bounds are inclusive by default;
+// a nil bound leaves that side of the range open.
+//
+// Filters may also pass a two-element array ([from, to]) or a JSON object:
+//
+//	{"field": "price", "operator": "between", "value": [10, 20]}
+//	{"field": "created_at", "operator": "between", "value": {"from": "2024-01-01", "to": "2024-02-01", "exclusive_to": true}}
+type Range struct {
+	From          any  `json:"from"`
+	To            any  `json:"to"`
+	ExclusiveFrom bool `json:"exclusive_from,omitempty"`
+	ExclusiveTo   bool `json:"exclusive_to,omitempty"`
 }
 
 // FieldFilterBuilder builds predicates for a specific field with various operators.
@@ -68,12 +179,51 @@ type FieldFilterBuilder[P any] interface {
 	IsNotNull() P
 }
 
+// OperatorBuilder is an optional interface for FieldFilterBuilder implementations that
+// validate their input. Instead of panicking on a malformed value or silently building a
+// different predicate, Build returns an error wrapping ErrInvalidValue or ErrUnsupportedOperator.
+//
+// ApplyStructuredFiltersE uses Build whenever a builder implements it. ApplyStructuredFilters
+// keeps calling the FieldFilterBuilder methods and only uses Build for operators that have
+// no method, such as regex or between. All generic builders created through BuildFilterMap
+// (StringField, BoolField, TimeField, ...) implement it.
+type OperatorBuilder[P any] interface {
+	// Build creates the predicate for the given operator and raw filter value
+	Build(op Operator, value any) (P, error)
+}
+
+// validatingBuilder is implemented by builders without legacy FieldFilterBuilder behavior to
+// keep, such as JSONFilterBuilder. ApplyStructuredFilters builds all of their filters through
+// Build and skips the ones it rejects, instead of calling the FieldFilterBuilder methods.
+type validatingBuilder interface {
+	validating()
+}
+
+// PathBuilder is an optional interface for builders of document columns, such as JSONField,
+// that address values below the column. When no builder is registered for a filter's full
+// field name, the name is split with SplitFieldPath and the builder registered for the first
+// segment receives the remaining segments, e.g. "metadata.plan.tier" is built by the
+// "metadata" builder with path ["plan", "tier"].
+type PathBuilder[P any] interface {
+	// BuildPath creates the predicate for the given path, operator and raw filter value
+	BuildPath(path []string, op Operator, value any) (P, error)
+}
+
 // ApplyStructuredFilters applies a group of structured filters to a query.
 //
+// Nested groups are walked recursively. Filters are built through the FieldFilterBuilder
+// methods, so invalid values and operators get the builder's fallback: unknown operators
+// are treated as Eq, and operators a field doesn't support match every row. Extended
+// operators such as ncontains, regex or between have no method and are built through
+// OperatorBuilder; when the builder rejects one (or doesn't implement OperatorBuilder), the
+// filter is skipped rather than built as Eq. Filters on unknown fields are skipped as well,
+// and groups that end up with no predicates are ignored (even when negated). Negated groups are skipped as well when PredicateBuilder.Not is not
+// configured. Use ApplyStructuredFiltersE to report these problems instead.
+//
 // Parameters:
 //   - query: The query to filter
 //   - cfg: Configuration containing the where function
-//   - group: The filter group to apply (filters + AND/OR logic)
+//   - group: The filter group to apply (filters, nested groups + AND/OR logic)
 //   - fieldBuilders: Map of field names to FieldFilterBuilder implementations
 //   - predicates: PredicateBuilder for combining predicates
 //
@@ -102,41 +252,223 @@ func ApplyStructuredFilters[Q any, P any](
 	fieldBuilders map[string]FieldFilterBuilder[P],
 	predicates PredicateBuilder[P],
 ) Q {
-	if len(group.Filters) == 0 {
+	gb := &groupBuilder[P]{fieldBuilders: fieldBuilders, predicates: predicates}
+	predicate, ok := gb.build(group, "")
+	if !ok {
 		return query
 	}
 
-	// Build predicates for each filter
-	filterPredicates := make([]P, 0, len(group.Filters))
-	for _, f := range group.Filters {
-		builder, ok := fieldBuilders[f.Field]
-		if !ok {
-			// Skip unknown fields
+	return cfg.Where(query, predicate)
+}
+
+// ApplyStructuredFiltersE applies a group of structured filters to a query, reporting
+// invalid input instead of skipping it.
+//
+// Every filter in the tree is checked. Unknown fields, unsupported operators, values
+// that cannot be converted to the field's type and malformed groups are collected into
+// an Errors value, each entry identifying the filter's path, field and operator. If any
+// error is found, the original query is returned unchanged together with the errors.
+//
+// Builders that do not implement OperatorBuilder are called through the FieldFilterBuilder
+// methods; a panic raised by such a builder is reported as ErrInvalidValue.
+//
+// Example:
+//
+//	query, err := filter.ApplyStructuredFiltersE(query, cfg, group, fieldBuilders, predicates)
+//	if err != nil {
+//	    return nil, huma.Error400BadRequest("invalid filters", err)
+//	}
+func ApplyStructuredFiltersE[Q any, P any](
+	query Q,
+	cfg Config[Q, P],
+	group FilterGroup,
+	fieldBuilders map[string]FieldFilterBuilder[P],
+	predicates PredicateBuilder[P],
+) (Q, error) {
+	gb := &groupBuilder[P]{fieldBuilders: fieldBuilders, predicates: predicates, strict: true}
+	predicate, ok := gb.build(group, "")
+	if len(gb.errs) > 0 {
+		return query, gb.errs
+	}
+	if !ok {
+		return query, nil
+	}
+
+	return cfg.Where(query, predicate), nil
+}
+
+// groupBuilder walks a filter group tree and builds its combined predicate.
+// In strict mode every problem is recorded in errs; otherwise filters on unknown fields are skipped.
+type groupBuilder[P any] struct {
+	fieldBuilders map[string]FieldFilterBuilder[P]
+	predicates    PredicateBuilder[P]
+	strict        bool
+	errs          Errors
+}
+
+// build recursively builds the combined predicate for a filter group.
+// It returns false if neither the group's filters nor its child groups produced a predicate.
+func (gb *groupBuilder[P]) build(group FilterGroup, path string) (P, bool) {
+	var zero P
+
+	if gb.strict {
+		if group.Logic != "" && !strings.EqualFold(group.Logic, "and") && !strings.EqualFold(group.Logic, "or") {
+			gb.groupError(path, fmt.Errorf("%w: unknown logic %q", ErrInvalidGroup, group.Logic))
+		}
+		if group.Not && gb.predicates.Not == nil {
+			gb.groupError(path, fmt.Errorf("%w: negation requires PredicateBuilder.Not", ErrInvalidGroup))
+		}
+	}
+
+	groupPredicates := make([]P, 0, len(group.Filters)+len(group.Groups))
+	for i, f := range group.Filters {
+		predicate, err := gb.buildFilter(f)
+		if err != nil {
+			if gb.strict {
+				gb.errs = append(gb.errs, &FilterError{
+					Path:     fmt.Sprintf("%sfilters[%d]", path, i),
+					Index:    i,
+					Field:    f.Field,
+					Operator: f.Operator,
+					Err:      err,
+				})
+			}
+			// Skip unknown fields and paths in lenient mode
 			continue
 		}
 
-		predicate := buildPredicate(builder, f)
-		filterPredicates = append(filterPredicates, predicate)
+		groupPredicates = append(groupPredicates, predicate)
 	}
 
-	if len(filterPredicates) == 0 {
-		return query
+	for i, child := range group.Groups {
+		if predicate, ok := gb.build(child, fmt.Sprintf("%sgroups[%d].", path, i)); ok {
+			groupPredicates = append(groupPredicates, predicate)
+		}
+	}
+
+	if len(groupPredicates) == 0 || len(gb.errs) > 0 {
+		return zero, false
 	}
 
 	// Combine predicates based on logic
 	var combinedPredicate P
-	if len(filterPredicates) == 1 {
-		combinedPredicate = filterPredicates[0]
+	if len(groupPredicates) == 1 {
+		combinedPredicate = groupPredicates[0]
 	} else {
-		if group.Logic == "or" {
-			combinedPredicate = predicates.Or(filterPredicates...)
+		if strings.EqualFold(group.Logic, "or") {
+			combinedPredicate = gb.predicates.Or(groupPredicates...)
 		} else {
 			// Default to AND
-			combinedPredicate = predicates.And(filterPredicates...)
+			combinedPredicate = gb.predicates.And(groupPredicates...)
+		}
+	}
+
+	if group.Not {
+		if gb.predicates.Not == nil {
+			// Without negation support the group can't be expressed; skip it in lenient mode
+			return zero, false
 		}
+		combinedPredicate = gb.predicates.Not(combinedPredicate)
 	}
 
-	return cfg.Where(query, combinedPredicate)
+	return combinedPredicate, true
+}
+
+// buildFilter builds the predicate for a single filter. Strict mode prefers OperatorBuilder
+// when available. Lenient mode keeps calling the FieldFilterBuilder methods, which fall back
+// to Eq or an always-true predicate for input they can't handle, and only uses Build for the
+// extended operators that have no method and for validatingBuilder implementations. Those
+// never fall back to Eq: when Build rejects them, the error is returned and the filter is
+// skipped.
+func (gb *groupBuilder[P]) buildFilter(f Filter) (P, error) {
+	builder, ok := gb.fieldBuilders[f.Field]
+	if !ok {
+		return gb.buildPathFilter(f)
+	}
+
+	ob, hasBuild := builder.(OperatorBuilder[P])
+	if gb.strict {
+		if hasBuild {
+			return gb.buildOperator(ob, f.Operator, f.Value)
+		}
+		return buildPredicateStrict(builder, f)
+	}
+
+	if _, validating := builder.(validatingBuilder); validating || extendedOperators[f.Operator] {
+		if !hasBuild {
+			var zero P
+			return zero, ErrUnsupportedOperator
+		}
+		return gb.buildOperator(ob, f.Operator, f.Value)
+	}
+	return buildPredicate(builder, f), nil
+}
+
+// buildOperator builds a predicate through ob.Build. A negated string operator the builder
+// doesn't support is built as the negation of its positive operator when PredicateBuilder.Not
+// is configured. Like SQL's NOT LIKE, the negation never matches rows where the field is NULL.
+func (gb *groupBuilder[P]) buildOperator(ob OperatorBuilder[P], op Operator, value any) (P, error) {
+	predicate, err := ob.Build(op, value)
+	positive, ok := negatedOperators[op]
+	if !ok || gb.predicates.Not == nil || !errors.Is(err, ErrUnsupportedOperator) {
+		return predicate, err
+	}
+
+	predicate, err = ob.Build(positive, value)
+	if err != nil {
+		return predicate, err
+	}
+	return gb.predicates.Not(predicate), nil
+}
+
+// buildPathFilter resolves a field like "metadata.plan.tier" to the PathBuilder registered
+// for its first segment.
+func (gb *groupBuilder[P]) buildPathFilter(f Filter) (P, error) {
+	var zero P
+
+	segments, err := SplitFieldPath(f.Field)
+	if err != nil || len(segments) < 2 {
+		return zero, ErrUnknownField
+	}
+
+	pb, ok := gb.fieldBuilders[segments[0]].(PathBuilder[P])
+	if !ok {
+		return zero, ErrUnknownField
+	}
+
+	return pb.BuildPath(segments[1:], f.Operator, f.Value)
+}
+
+// groupError records a group-level error.
+func (gb *groupBuilder[P]) groupError(path string, err error) {
+	gb.errs = append(gb.errs, &FilterError{
+		Path:  strings.TrimSuffix(path, "."),
+		Index: -1,
+		Err:   err,
+	})
+}
+
+// buildPredicateStrict builds a predicate through the FieldFilterBuilder methods, rejecting
+// unknown operators and non-string values for string operators, and converting panics
+// raised by the builder into ErrInvalidValue.
+func buildPredicateStrict[P any](builder FieldFilterBuilder[P], f Filter) (predicate P, err error) {
+	switch f.Operator {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn, OpNin, OpNull, OpNnull:
+	case OpContains, OpStartsWith, OpEndsWith:
+		if _, ok := f.Value.(string); !ok {
+			return predicate, invalidValue(f.Value, "string")
+		}
+	default:
+		return predicate, ErrUnsupportedOperator
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrInvalidValue, r)
+		}
+	}()
+
+	return buildPredicate(builder, f), nil
 }
 
 // buildPredicate builds a single predicate from a filter using the field builder
@@ -195,3 +527,83 @@ func toString(v any) string {
 	}
 	return ""
 }
+
+// SplitFieldPath splits a filter field name into path segments. Segments are separated by
+// dots or written in brackets, optionally quoted:
+//
+//	"metadata.plan.tier"         -> ["metadata", "plan", "tier"]
+//	"metadata[plan][tier]"       -> ["metadata", "plan", "tier"]
+//	`metadata["plan.v2"].tier`   -> ["metadata", "plan.v2", "tier"]
+//	"items[0].name"              -> ["items", "0", "name"]
+//
+// It returns an error for empty segments and unbalanced brackets or quotes.
+func SplitFieldPath(field string) ([]string, error) {
+	var (
+		segments []string
+		current  strings.Builder
+		pending  bool // a dot was seen and must be followed by a segment
+	)
+
+	flush := func() error {
+		if current.Len() == 0 {
+			return fmt.Errorf("empty path segment in %q", field)
+		}
+		segments = append(segments, current.String())
+		current.Reset()
+		return nil
+	}
+
+	for i := 0; i < len(field); i++ {
+		switch c := field[i]; c {
+		case '.':
+			if current.Len() > 0 {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			} else if len(segments) == 0 || pending {
+				return nil, fmt.Errorf("empty path segment in %q", field)
+			}
+			pending = true
+		case '[':
+			if current.Len() > 0 {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			} else if len(segments) == 0 || pending {
+				return nil, fmt.Errorf("empty path segment in %q", field)
+			}
+			end := strings.IndexByte(field[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in %q", field)
+			}
+			inner := field[i+1 : i+1+end]
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') {
+				if inner[len(inner)-1] != inner[0] {
+					return nil, fmt.Errorf("unterminated quote in %q", field)
+				}
+				inner = inner[1 : len(inner)-1]
+			}
+			current.WriteString(inner)
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			i += end + 1
+			pending = false
+		case ']':
+			return nil, fmt.Errorf("unbalanced bracket in %q", field)
+		default:
+			current.WriteByte(c)
+			pending = false
+		}
+	}
+
+	if current.Len() > 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	} else if pending || len(segments) == 0 {
+		return nil, fmt.Errorf("empty path segment in %q", field)
+	}
+
+	return segments, nil
+}
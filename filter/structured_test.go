@@ -1,6 +1,8 @@
 package filter_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -471,3 +473,606 @@ func TestApplyStructuredFilters_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestApplyStructuredFilters_NestedGroups(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+
+	builder := filter.PredicateBuilder[MockPredicate]{
+		IDIn: mockIDIn,
+		Or:   mockOr,
+		And:  mockAnd,
+		Not:  mockNot,
+	}
+
+	fieldBuilders := map[string]filter.FieldFilterBuilder[MockPredicate]{
+		"status":     MockFieldFilterBuilder{fieldName: "status"},
+		"role":       MockFieldFilterBuilder{fieldName: "role"},
+		"created_at": MockFieldFilterBuilder{fieldName: "created_at"},
+	}
+
+	tests := []struct {
+		name     string
+		group    filter.FilterGroup
+		expected string
+	}{
+		{
+			name: "and with nested or group",
+			group: filter.FilterGroup{
+				Filters: []filter.Filter{
+					{Field: "status", Operator: filter.OpEq, Value: "active"},
+				},
+				Groups: []filter.FilterGroup{
+					{
+						Filters: []filter.Filter{
+							{Field: "role", Operator: filter.OpEq, Value: "admin"},
+							{Field: "created_at", Operator: filter.OpGt, Value: "2024-01-01"},
+						},
+						Logic: "or",
+					},
+				},
+				Logic: "and",
+			},
+			expected: "(status = active AND (role = admin OR created_at > 2024-01-01))",
+		},
+		{
+			name: "negated child group",
+			group: filter.FilterGroup{
+				Filters: []filter.Filter{
+					{Field: "status", Operator: filter.OpEq, Value: "active"},
+				},
+				Groups: []filter.FilterGroup{
+					{
+						Filters: []filter.Filter{
+							{Field: "role", Operator: filter.OpEq, Value: "admin"},
+							{Field: "role", Operator: filter.OpEq, Value: "owner"},
+						},
+						Logic: "or",
+						Not:   true,
+					},
+				},
+			},
+			expected: "(status = active AND NOT (role = admin OR role = owner))",
+		},
+		{
+			name: "negated root group",
+			group: filter.FilterGroup{
+				Filters: []filter.Filter{
+					{Field: "role", Operator: filter.OpEq, Value: "admin"},
+				},
+				Not: true,
+			},
+			expected: "NOT role = admin",
+		},
+		{
+			name: "groups only",
+			group: filter.FilterGroup{
+				Groups: []filter.FilterGroup{
+					{Filters: []filter.Filter{{Field: "role", Operator: filter.OpEq, Value: "admin"}}},
+					{Filters: []filter.Filter{{Field: "status", Operator: filter.OpEq, Value: "active"}}},
+				},
+				Logic: "or",
+			},
+			expected: "(role = admin OR status = active)",
+		},
+		{
+			name: "deeply nested",
+			group: filter.FilterGroup{
+				Groups: []filter.FilterGroup{
+					{
+						Filters: []filter.Filter{{Field: "status", Operator: filter.OpEq, Value: "active"}},
+						Groups: []filter.FilterGroup{
+							{
+								Filters: []filter.Filter{{Field: "role", Operator: filter.OpEq, Value: "admin"}},
+								Not:     true,
+							},
+						},
+						Logic: "and",
+					},
+				},
+			},
+			expected: "(status = active AND NOT role = admin)",
+		},
+		{
+			name: "empty and unknown-only child groups are ignored",
+			group: filter.FilterGroup{
+				Filters: []filter.Filter{
+					{Field: "status", Operator: filter.OpEq, Value: "active"},
+				},
+				Groups: []filter.FilterGroup{
+					{},
+					{Filters: []filter.Filter{{Field: "unknown", Operator: filter.OpEq, Value: "x"}}, Not: true},
+				},
+			},
+			expected: "status = active",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &MockQuery{}
+			result := filter.ApplyStructuredFilters(query, cfg, tt.group, fieldBuilders, builder)
+
+			if len(result.predicates) != 1 {
+				t.Fatalf("expected 1 predicate, got %d", len(result.predicates))
+			}
+
+			if result.predicates[0] != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %s", tt.expected, result.predicates[0])
+			}
+		})
+	}
+
+	t.Run("group with only empty children", func(t *testing.T) {
+		query := &MockQuery{}
+		group := filter.FilterGroup{Groups: []filter.FilterGroup{{}, {Not: true}}}
+
+		result := filter.ApplyStructuredFilters(query, cfg, group, fieldBuilders, builder)
+
+		if len(result.predicates) != 0 {
+			t.Errorf("expected no predicates, got %v", result.predicates)
+		}
+	})
+
+	t.Run("negated group without Not is skipped", func(t *testing.T) {
+		query := &MockQuery{}
+		group := filter.FilterGroup{
+			Filters: []filter.Filter{
+				{Field: "status", Operator: filter.OpEq, Value: "active"},
+			},
+			Groups: []filter.FilterGroup{
+				{Filters: []filter.Filter{{Field: "role", Operator: filter.OpEq, Value: "admin"}}, Not: true},
+			},
+		}
+		withoutNot := filter.PredicateBuilder[MockPredicate]{Or: mockOr, And: mockAnd}
+
+		result := filter.ApplyStructuredFilters(query, cfg, group, fieldBuilders, withoutNot)
+
+		if len(result.predicates) != 1 || result.predicates[0] != "status = active" {
+			t.Errorf("expected [status = active], got %v", result.predicates)
+		}
+	})
+}
+
+func TestFilterGroup_UnmarshalJSON(t *testing.T) {
+	t.Run("flat payload", func(t *testing.T) {
+		payload := `{"filters":[{"field":"email","operator":"contains","value":"john"}],"logic":"or"}`
+
+		var group filter.FilterGroup
+		if err := json.Unmarshal([]byte(payload), &group); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if group.Logic != "or" || group.Not || len(group.Groups) != 0 {
+			t.Errorf("unexpected group: %+v", group)
+		}
+		if len(group.Filters) != 1 || group.Filters[0].Field != "email" ||
+			group.Filters[0].Operator != filter.OpContains || group.Filters[0].Value != "john" {
+			t.Errorf("unexpected filters: %+v", group.Filters)
+		}
+	})
+
+	t.Run("nested payload", func(t *testing.T) {
+		payload := `{
+			"logic": "and",
+			"filters": [{"field": "status", "operator": "eq", "value": "active"}],
+			"groups": [{
+				"logic": "or",
+				"not": true,
+				"filters": [
+					{"field": "role", "operator": "eq", "value": "admin"},
+					{"field": "created_at", "operator": "gt", "value": "2024-01-01"}
+				]
+			}]
+		}`
+
+		var group filter.FilterGroup
+		if err := json.Unmarshal([]byte(payload), &group); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(group.Groups) != 1 {
+			t.Fatalf("expected 1 child group, got %d", len(group.Groups))
+		}
+		child := group.Groups[0]
+		if !child.Not || child.Logic != "or" || len(child.Filters) != 2 {
+			t.Errorf("unexpected child group: %+v", child)
+		}
+	})
+}
+
+func TestApplyStructuredFiltersE(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+
+	builder := filter.PredicateBuilder[MockPredicate]{
+		IDIn: mockIDIn,
+		Or:   mockOr,
+		And:  mockAnd,
+		Not:  mockNot,
+	}
+
+	fieldBuilders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.StringField("email", mockStringPredicates("email")),
+		filter.TimeField("created_at", mockTimePredicates("created_at")),
+	)
+	fieldBuilders["age"] = MockFieldFilterBuilder{fieldName: "age"}
+
+	t.Run("valid filters", func(t *testing.T) {
+		group := filter.FilterGroup{
+			Filters: []filter.Filter{
+				{Field: "email", Operator: filter.OpContains, Value: "john"},
+				{Field: "age", Operator: filter.OpGte, Value: 18},
+			},
+		}
+
+		result, err := filter.ApplyStructuredFiltersE(&MockQuery{}, cfg, group, fieldBuilders, builder)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := "(email CONTAINS john AND age >= 18)"
+		if len(result.predicates) != 1 || result.predicates[0] != expected {
+			t.Errorf("\nexpected: %s\ngot:      %v", expected, result.predicates)
+		}
+	})
+
+	t.Run("errors are aggregated with their location", func(t *testing.T) {
+		group := filter.FilterGroup{
+			Filters: []filter.Filter{
+				{Field: "email", Operator: filter.OpEq, Value: float64(42)},
+				{Field: "unknown", Operator: filter.OpEq, Value: "x"},
+			},
+			Groups: []filter.FilterGroup{
+				{
+					Filters: []filter.Filter{
+						{Field: "email", Operator: filter.OpEq, Value: "ok"},
+						{Field: "created_at", Operator: filter.OpGt, Value: "yesterday-ish"},
+						{Field: "age", Operator: "bogus", Value: 1},
+					},
+					Logic: "xor",
+				},
+			},
+		}
+
+		query := &MockQuery{}
+		result, err := filter.ApplyStructuredFiltersE(query, cfg, group, fieldBuilders, builder)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if len(result.predicates) != 0 {
+			t.Errorf("expected query to be unchanged, got %v", result.predicates)
+		}
+
+		var filterErrs filter.Errors
+		if !errors.As(err, &filterErrs) {
+			t.Fatalf("expected filter.Errors, got %T", err)
+		}
+
+		expected := []struct {
+			path  string
+			field string
+			err   error
+		}{
+			{"filters[0]", "email", filter.ErrInvalidValue},
+			{"filters[1]", "unknown", filter.ErrUnknownField},
+			{"groups[0]", "", filter.ErrInvalidGroup},
+			{"groups[0].filters[1]", "created_at", filter.ErrInvalidValue},
+			{"groups[0].filters[2]", "age", filter.ErrUnsupportedOperator},
+		}
+
+		if len(filterErrs) != len(expected) {
+			t.Fatalf("expected %d errors, got %d: %v", len(expected), len(filterErrs), err)
+		}
+		for i, exp := range expected {
+			got := filterErrs[i]
+			if got.Path != exp.path || got.Field != exp.field || !errors.Is(got, exp.err) {
+				t.Errorf("error %d: expected %s/%s/%v, got %s/%s/%v", i, exp.path, exp.field, exp.err, got.Path, got.Field, got.Err)
+			}
+		}
+
+		if !errors.Is(err, filter.ErrUnknownField) {
+			t.Error("expected aggregated error to match ErrUnknownField")
+		}
+		if !strings.Contains(err.Error(), `filters[0] (field "email", operator "eq"): invalid value: expected string, got float64`) {
+			t.Errorf("unexpected error message: %s", err)
+		}
+	})
+
+	t.Run("negation without Not combinator", func(t *testing.T) {
+		noNot := builder
+		noNot.Not = nil
+		group := filter.FilterGroup{
+			Filters: []filter.Filter{{Field: "email", Operator: filter.OpEq, Value: "x"}},
+			Not:     true,
+		}
+
+		_, err := filter.ApplyStructuredFiltersE(&MockQuery{}, cfg, group, fieldBuilders, noNot)
+		if !errors.Is(err, filter.ErrInvalidGroup) {
+			t.Fatalf("expected ErrInvalidGroup, got %v", err)
+		}
+	})
+
+	t.Run("panicking custom builder", func(t *testing.T) {
+		panicking := map[string]filter.FieldFilterBuilder[MockPredicate]{
+			"score": panickingBuilder{MockFieldFilterBuilder{fieldName: "score"}},
+		}
+		group := filter.FilterGroup{
+			Filters: []filter.Filter{{Field: "score", Operator: filter.OpEq, Value: "x"}},
+		}
+
+		_, err := filter.ApplyStructuredFiltersE(&MockQuery{}, cfg, group, panicking, builder)
+		if !errors.Is(err, filter.ErrInvalidValue) {
+			t.Fatalf("expected ErrInvalidValue, got %v", err)
+		}
+	})
+}
+
+func TestApplyStructuredFilters_LegacyFallbacks(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+
+	builder := filter.PredicateBuilder[MockPredicate]{
+		IDIn: mockIDIn,
+		Or:   mockOr,
+		And:  mockAnd,
+	}
+
+	fieldBuilders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.StringField("email", mockStringPredicates("email")),
+		filter.BoolField("is_active", mockBoolPredicates("is_active")),
+		filter.TimeField("created_at", mockTimePredicates("created_at")),
+	)
+
+	tests := []struct {
+		name     string
+		filter   filter.Filter
+		expected string
+	}{
+		{
+			name:     "unparsable time filters on the zero time",
+			filter:   filter.Filter{Field: "created_at", Operator: filter.OpGt, Value: "not a date"},
+			expected: "created_at > 0001-01-01T00:00:00Z",
+		},
+		{
+			name:     "comparison on bool falls back to equality",
+			filter:   filter.Filter{Field: "is_active", Operator: filter.OpGt, Value: true},
+			expected: "is_active = true",
+		},
+		{
+			name:     "string operator on bool is always true",
+			filter:   filter.Filter{Field: "is_active", Operator: filter.OpContains, Value: "x"},
+			expected: "(is_active = true OR is_active = false)",
+		},
+		{
+			name:     "unknown operator falls back to equality",
+			filter:   filter.Filter{Field: "email", Operator: "matches", Value: "john"},
+			expected: "email = john",
+		},
+		{
+			name:     "non-string value on string field is formatted",
+			filter:   filter.Filter{Field: "email", Operator: filter.OpEq, Value: 42},
+			expected: "email = 42",
+		},
+		{
+			name:     "non-string list on string field is formatted",
+			filter:   filter.Filter{Field: "email", Operator: filter.OpIn, Value: []any{"a", 1, nil}},
+			expected: "email IN (a,1,)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := filter.FilterGroup{Filters: []filter.Filter{tt.filter}}
+
+			result := filter.ApplyStructuredFilters(&MockQuery{}, cfg, group, fieldBuilders, builder)
+
+			if len(result.predicates) != 1 || result.predicates[0] != tt.expected {
+				t.Errorf("\nexpected: %s\ngot:      %v", tt.expected, result.predicates)
+			}
+		})
+	}
+
+	t.Run("logic is case-insensitive", func(t *testing.T) {
+		group := filter.FilterGroup{
+			Filters: []filter.Filter{
+				{Field: "email", Operator: filter.OpEq, Value: "a"},
+				{Field: "email", Operator: filter.OpEq, Value: "b"},
+			},
+			Logic: "OR",
+		}
+
+		result := filter.ApplyStructuredFilters(&MockQuery{}, cfg, group, fieldBuilders, builder)
+		_, err := filter.ApplyStructuredFiltersE(&MockQuery{}, cfg, group, fieldBuilders, builder)
+
+		expected := "(email = a OR email = b)"
+		if len(result.predicates) != 1 || result.predicates[0] != expected {
+			t.Errorf("\nexpected: %s\ngot:      %v", expected, result.predicates)
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestApplyStructuredFilters_SkipsRejectedExtendedOperators(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+
+	// No PredicateBuilder.Not, so negated operators can't be derived from their positive form
+	builder := filter.PredicateBuilder[MockPredicate]{
+		IDIn: mockIDIn,
+		Or:   mockOr,
+		And:  mockAnd,
+	}
+
+	fieldBuilders := filter.BuildFilterMap(
+		mockCombinators,
+		filter.StringField("email", mockStringPredicates("email")),
+		filter.EnumField("role",
+			filter.Enum[string]{Values: []string{"admin", "member"}},
+			filter.EnumPredicates[MockPredicate, string]{Eq: mockOp("role", "="), Ne: mockOp("role", "!=")},
+		),
+		filter.UUIDField("id", filter.ParseUUIDString,
+			filter.UUIDPredicates[MockPredicate, string]{Eq: mockOp("id", "="), Ne: mockOp("id", "!=")},
+		),
+	)
+
+	tests := []struct {
+		name   string
+		filter filter.Filter
+	}{
+		{
+			name:   "negated operator without Not",
+			filter: filter.Filter{Field: "email", Operator: filter.OpNcontains, Value: "john"},
+		},
+		{
+			name:   "unconfigured operator",
+			filter: filter.Filter{Field: "email", Operator: filter.OpRegex, Value: "^john"},
+		},
+		{
+			name:   "array operator on string field",
+			filter: filter.Filter{Field: "email", Operator: filter.OpHasAny, Value: []any{"a", "b"}},
+		},
+		{
+			name:   "array operator with a single value on string field",
+			filter: filter.Filter{Field: "email", Operator: filter.OpHasAll, Value: "a"},
+		},
+		{
+			name:   "range on string field",
+			filter: filter.Filter{Field: "email", Operator: filter.OpBetween, Value: []any{"a", "m"}},
+		},
+		{
+			name:   "negated range on enum field",
+			filter: filter.Filter{Field: "role", Operator: filter.OpNbetween, Value: []any{"admin", "member"}},
+		},
+		{
+			name:   "range on UUID field",
+			filter: filter.Filter{Field: "id", Operator: filter.OpBetween, Value: []any{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", "6ba7b811-9dad-11d1-80b4-00c04fd430c8"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := filter.FilterGroup{
+				Filters: []filter.Filter{
+					{Field: "email", Operator: filter.OpEq, Value: "a"},
+					tt.filter,
+				},
+			}
+
+			result := filter.ApplyStructuredFilters(&MockQuery{}, cfg, group, fieldBuilders, builder)
+
+			if len(result.predicates) != 1 || result.predicates[0] != "email = a" {
+				t.Errorf("expected [email = a], got %v", result.predicates)
+			}
+		})
+	}
+}
+
+// panickingBuilder simulates a hand-written builder that type-asserts its input
+type panickingBuilder struct {
+	MockFieldFilterBuilder
+}
+
+func (b panickingBuilder) Eq(value any) MockPredicate {
+	return MockPredicate(fmt.Sprintf("score = %d", value.(int)))
+}
+
+func TestSplitFieldPath(t *testing.T) {
+	tests := []struct {
+		field    string
+		expected []string
+		wantErr  bool
+	}{
+		{field: "email", expected: []string{"email"}},
+		{field: "metadata.plan.tier", expected: []string{"metadata", "plan", "tier"}},
+		{field: "metadata[plan][tier]", expected: []string{"metadata", "plan", "tier"}},
+		{field: `metadata["plan.v2"].tier`, expected: []string{"metadata", "plan.v2", "tier"}},
+		{field: "metadata['plan'].tier", expected: []string{"metadata", "plan", "tier"}},
+		{field: "items[0].name", expected: []string{"items", "0", "name"}},
+		{field: "", wantErr: true},
+		{field: ".plan", wantErr: true},
+		{field: "metadata..plan", wantErr: true},
+		{field: "metadata.", wantErr: true},
+		{field: "metadata[plan", wantErr: true},
+		{field: "metadata[]", wantErr: true},
+		{field: "metadata]", wantErr: true},
+		{field: `metadata["plan]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			got, err := filter.SplitFieldPath(tt.field)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestApplyStructuredFilters_ExtendedOperatorsOnCustomBuilders(t *testing.T) {
+	cfg := filter.Config[*MockQuery, MockPredicate]{
+		Where: func(q *MockQuery, p MockPredicate) *MockQuery {
+			q.predicates = append(q.predicates, string(p))
+			return q
+		},
+	}
+
+	builder := filter.PredicateBuilder[MockPredicate]{
+		IDIn: mockIDIn,
+		Or:   mockOr,
+		And:  mockAnd,
+	}
+
+	fieldBuilders := map[string]filter.FieldFilterBuilder[MockPredicate]{
+		"tags": MockFieldFilterBuilder{fieldName: "tags"},
+	}
+
+	group := filter.FilterGroup{
+		Filters: []filter.Filter{{Field: "tags", Operator: filter.OpHasAll, Value: []any{"a"}}},
+	}
+
+	t.Run("lenient mode skips the filter", func(t *testing.T) {
+		result := filter.ApplyStructuredFilters(&MockQuery{}, cfg, group, fieldBuilders, builder)
+		if len(result.predicates) != 0 {
+			t.Errorf("expected no predicates, got %v", result.predicates)
+		}
+	})
+
+	t.Run("strict mode reports the operator", func(t *testing.T) {
+		_, err := filter.ApplyStructuredFiltersE(&MockQuery{}, cfg, group, fieldBuilders, builder)
+		if !errors.Is(err, filter.ErrUnsupportedOperator) {
+			t.Errorf("expected ErrUnsupportedOperator, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+// Package parse holds the pieces shared by dewey's filter syntax packages (rsql, odata, aip,
+// scim, hasura and mongo): the syntax error type, the nesting limit and the node type the
+// expression parsers build filter groups from.
+package parse
+
+import (
+	"fmt"
+
+	"github.com/tone-labs/dewey/filter"
+)
+
+// MaxDepth limits nesting so hostile input can't exhaust the stack.
+const MaxDepth = 64
+
+// Error reports a malformed expression. Each parser declares its own SyntaxError type with
+// this layout and renders it through Message with its package prefix.
+type Error struct {
+	// Pos is the byte offset in the input where the problem was found
+	Pos int
+	Msg string
+
+	// Err is set for errors that correspond to a filter sentinel, e.g. an unknown
+	// comparison operator wraps filter.ErrUnsupportedOperator
+	Err error
+}
+
+// Errorf returns an Error at pos with a formatted message.
+func Errorf(pos int, format string, args ...any) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Message formats the error for the parser identified by prefix, e.g. "rsql".
+func (e *Error) Message(prefix string) string {
+	return fmt.Sprintf("%s: syntax error at position %d: %s", prefix, e.Pos, e.Msg)
+}
+
+// Node is either a single filter or a group.
+type Node struct {
+	Filter *filter.Filter
+	Group  filter.FilterGroup
+}
+
+// FilterNode returns a node holding a single filter.
+func FilterNode(field string, op filter.Operator, value any) Node {
+	return Node{Filter: &filter.Filter{Field: field, Operator: op, Value: value}}
+}
+
+// ToGroup returns the node as a filter group, wrapping a single filter in an "and" group.
+func (n Node) ToGroup() filter.FilterGroup {
+	if n.Filter != nil {
+		return filter.FilterGroup{Logic: "and", Filters: []filter.Filter{*n.Filter}}
+	}
+	return n.Group
+}
+
+// Negate returns the node as a negated group. A group that is already negated is nested
+// rather than un-negated.
+func (n Node) Negate() Node {
+	g := n.ToGroup()
+	if g.Not {
+		g = filter.FilterGroup{Logic: "and", Groups: []filter.FilterGroup{g}}
+	}
+	g.Not = true
+	return Node{Group: g}
+}
+
+// Combine joins nodes with the given logic, returning a single node unchanged.
+func Combine(logic string, nodes ...Node) Node {
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	group := filter.FilterGroup{Logic: logic}
+	for _, n := range nodes {
+		if n.Filter != nil {
+			group.Filters = append(group.Filters, *n.Filter)
+			continue
+		}
+		group.Groups = append(group.Groups, n.Group)
+	}
+	return Node{Group: group}
+}
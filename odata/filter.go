@@ -0,0 +1,368 @@
+package odata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/internal/parse"
+)
+
+// comparisons maps OData comparison operators onto dewey operators.
+var comparisons = map[string]filter.Operator{
+	"eq": filter.OpEq,
+	"ne": filter.OpNe,
+	"gt": filter.OpGt,
+	"ge": filter.OpGte,
+	"lt": filter.OpLt,
+	"le": filter.OpLte,
+}
+
+// flipped maps a comparison to its equivalent with the operands swapped (18 lt Age is Age gt 18).
+var flipped = map[filter.Operator]filter.Operator{
+	filter.OpEq:  filter.OpEq,
+	filter.OpNe:  filter.OpNe,
+	filter.OpGt:  filter.OpLt,
+	filter.OpGte: filter.OpLte,
+	filter.OpLt:  filter.OpGt,
+	filter.OpLte: filter.OpGte,
+}
+
+// stringFunctions maps the boolean string functions to their case-sensitive and
+// case-insensitive operators. OData's functions are case-sensitive; wrapping the property in
+// tolower() or toupper() selects the case-insensitive operator.
+var stringFunctions = map[string][2]filter.Operator{
+	"contains":   {filter.OpContainsCS, filter.OpContains},
+	"startswith": {filter.OpStartsWithCS, filter.OpStartsWith},
+	"endswith":   {filter.OpEndsWithCS, filter.OpEndsWith},
+}
+
+// ParseFilter parses a $filter expression into a filter group. An empty expression yields
+// an empty group.
+//
+// Supported: eq, ne, gt, ge, lt, le, and, or, not, parentheses, in, and the contains,
+// startswith and endswith functions (optionally over tolower/toupper of a property, and
+// optionally compared with eq true/false). "Name eq null" and "Name ne null" map to null and
+// nnull. Literals are passed to the field builders as string, int64, float64 or bool; dates,
+// datetimes and GUIDs are strings.
+func ParseFilter(expr string) (filter.FilterGroup, error) {
+	p := &parser{lexer: lexer{input: expr}}
+	if err := p.advance(); err != nil {
+		return filter.FilterGroup{}, err
+	}
+	if p.tok.kind == tokenEOF {
+		return filter.FilterGroup{Logic: "and"}, nil
+	}
+
+	n, err := p.or(0)
+	if err != nil {
+		return filter.FilterGroup{}, err
+	}
+	if p.tok.kind != tokenEOF {
+		return filter.FilterGroup{}, p.unexpected()
+	}
+	return n.ToGroup(), nil
+}
+
+type parser struct {
+	lexer lexer
+	tok   token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return syntaxError(p.tok.pos, "unexpected end of input")
+	}
+	return syntaxError(p.tok.pos, "unexpected %q", p.tok.text)
+}
+
+// expect consumes a token of the given kind.
+func (p *parser) expect(kind tokenKind, what string) error {
+	if p.tok.kind != kind {
+		if p.tok.kind == tokenEOF {
+			return syntaxError(p.tok.pos, "expected %s, got end of input", what)
+		}
+		return syntaxError(p.tok.pos, "expected %s, got %q", what, p.tok.text)
+	}
+	return p.advance()
+}
+
+// or parses: and { "or" and }
+func (p *parser) or(depth int) (parse.Node, error) {
+	return p.list("or", depth, p.and)
+}
+
+// and parses: unary { "and" unary }
+func (p *parser) and(depth int) (parse.Node, error) {
+	return p.list("and", depth, p.unary)
+}
+
+func (p *parser) list(logic string, depth int, operand func(int) (parse.Node, error)) (parse.Node, error) {
+	first, err := operand(depth)
+	if err != nil {
+		return parse.Node{}, err
+	}
+
+	nodes := []parse.Node{first}
+	for p.tok.is(logic) {
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		n, err := operand(depth)
+		if err != nil {
+			return parse.Node{}, err
+		}
+		nodes = append(nodes, n)
+	}
+	return parse.Combine(logic, nodes...), nil
+}
+
+// unary parses: "not" unary | primary
+func (p *parser) unary(depth int) (parse.Node, error) {
+	if depth >= parse.MaxDepth {
+		return parse.Node{}, syntaxError(p.tok.pos, "expression nested deeper than %d levels", parse.MaxDepth)
+	}
+	if !p.tok.is("not") {
+		return p.primary(depth)
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+	n, err := p.unary(depth + 1)
+	if err != nil {
+		return parse.Node{}, err
+	}
+	return n.Negate(), nil
+}
+
+// primary parses: "(" or ")" | function call | comparison
+func (p *parser) primary(depth int) (parse.Node, error) {
+	switch {
+	case p.tok.kind == tokenLParen:
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		n, err := p.or(depth + 1)
+		if err != nil {
+			return parse.Node{}, err
+		}
+		return n, p.expect(tokenRParen, `")"`)
+	case p.tok.kind == tokenWord && p.peekParen():
+		return p.function(depth)
+	default:
+		return p.comparison()
+	}
+}
+
+// peekParen reports whether the current word is immediately followed by "(", i.e. a function call.
+func (p *parser) peekParen() bool {
+	rest := p.lexer.input[p.lexer.pos:]
+	return strings.HasPrefix(strings.TrimLeft(rest, " \t\n\r"), "(")
+}
+
+// function parses contains/startswith/endswith(property, 'value') [eq|ne true|false].
+func (p *parser) function(depth int) (parse.Node, error) {
+	name := p.tok
+	ops, ok := stringFunctions[strings.ToLower(name.text)]
+	if !ok {
+		return parse.Node{}, &SyntaxError{
+			Pos: name.pos,
+			Msg: fmt.Sprintf("unsupported function %q", name.text),
+			Err: filter.ErrUnsupportedOperator,
+		}
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+	if err := p.expect(tokenLParen, `"("`); err != nil {
+		return parse.Node{}, err
+	}
+
+	field, caseInsensitive, err := p.stringProperty(depth)
+	if err != nil {
+		return parse.Node{}, err
+	}
+	if err := p.expect(tokenComma, `","`); err != nil {
+		return parse.Node{}, err
+	}
+	arg := p.tok
+	value, ok := arg.value.(string)
+	if arg.kind != tokenLiteral || !ok {
+		return parse.Node{}, &SyntaxError{
+			Pos: arg.pos,
+			Msg: fmt.Sprintf("%s expects a string argument, got %q", name.text, arg.text),
+			Err: filter.ErrInvalidValue,
+		}
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+	if err := p.expect(tokenRParen, `")"`); err != nil {
+		return parse.Node{}, err
+	}
+
+	op := ops[0]
+	if caseInsensitive {
+		op = ops[1]
+	}
+	n := parse.FilterNode(field, op, value)
+
+	// contains(Name,'x') eq false
+	if p.tok.is("eq") || p.tok.is("ne") {
+		negate := p.tok.is("ne")
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		b, ok := p.tok.value.(bool)
+		if p.tok.kind != tokenLiteral || !ok {
+			return parse.Node{}, syntaxError(p.tok.pos, "expected true or false, got %q", p.tok.text)
+		}
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		if b == negate {
+			n = n.Negate()
+		}
+	}
+	return n, nil
+}
+
+// stringProperty parses a property, optionally wrapped in tolower() or toupper(). Wrappers
+// count towards the nesting limit like parentheses do.
+func (p *parser) stringProperty(depth int) (field string, caseInsensitive bool, err error) {
+	if depth >= parse.MaxDepth {
+		return "", false, syntaxError(p.tok.pos, "expression nested deeper than %d levels", parse.MaxDepth)
+	}
+	if p.tok.kind == tokenWord && p.peekParen() {
+		fn := p.tok
+		if !fn.is("tolower") && !fn.is("toupper") {
+			return "", false, &SyntaxError{
+				Pos: fn.pos,
+				Msg: fmt.Sprintf("unsupported function %q", fn.text),
+				Err: filter.ErrUnsupportedOperator,
+			}
+		}
+		if err := p.advance(); err != nil {
+			return "", false, err
+		}
+		if err := p.expect(tokenLParen, `"("`); err != nil {
+			return "", false, err
+		}
+		field, _, err := p.stringProperty(depth + 1)
+		if err != nil {
+			return "", false, err
+		}
+		return field, true, p.expect(tokenRParen, `")"`)
+	}
+
+	if p.tok.kind != tokenWord {
+		return "", false, syntaxError(p.tok.pos, "expected property, got %q", p.tok.text)
+	}
+	field = fieldName(p.tok.text)
+	return field, false, p.advance()
+}
+
+// comparison parses: operand (eq|ne|gt|ge|lt|le) operand | property in (literal, ...)
+func (p *parser) comparison() (parse.Node, error) {
+	left := p.tok
+	if left.kind != tokenWord && left.kind != tokenLiteral {
+		return parse.Node{}, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	opTok := p.tok
+	if opTok.is("in") {
+		if left.kind != tokenWord {
+			return parse.Node{}, syntaxError(left.pos, "expected property before in, got %q", left.text)
+		}
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		values, err := p.literalList()
+		if err != nil {
+			return parse.Node{}, err
+		}
+		return parse.FilterNode(fieldName(left.text), filter.OpIn, values), nil
+	}
+
+	op, ok := filter.Operator(""), false
+	if opTok.kind == tokenWord {
+		op, ok = comparisons[strings.ToLower(opTok.text)]
+	}
+	if !ok {
+		if opTok.kind == tokenEOF {
+			return parse.Node{}, syntaxError(opTok.pos, "expected comparison operator, got end of input")
+		}
+		return parse.Node{}, syntaxError(opTok.pos, "expected comparison operator, got %q", opTok.text)
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	right := p.tok
+	if right.kind != tokenWord && right.kind != tokenLiteral {
+		return parse.Node{}, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	switch {
+	case left.kind == tokenWord && right.kind == tokenLiteral:
+	case left.kind == tokenLiteral && right.kind == tokenWord:
+		left, right, op = right, left, flipped[op]
+	default:
+		return parse.Node{}, syntaxError(left.pos, "comparisons need one property and one literal")
+	}
+
+	field := fieldName(left.text)
+	if right.value == nil {
+		switch op {
+		case filter.OpEq:
+			return parse.FilterNode(field, filter.OpNull, nil), nil
+		case filter.OpNe:
+			return parse.FilterNode(field, filter.OpNnull, nil), nil
+		default:
+			return parse.Node{}, syntaxError(opTok.pos, "null can only be compared with eq or ne")
+		}
+	}
+	return parse.FilterNode(field, op, right.value), nil
+}
+
+// literalList parses: "(" literal { "," literal } ")"
+func (p *parser) literalList() ([]any, error) {
+	if err := p.expect(tokenLParen, `"("`); err != nil {
+		return nil, err
+	}
+
+	var values []any
+	for {
+		if p.tok.kind != tokenLiteral {
+			if p.tok.kind == tokenEOF {
+				return nil, syntaxError(p.tok.pos, "expected literal, got end of input")
+			}
+			return nil, syntaxError(p.tok.pos, "expected literal, got %q", p.tok.text)
+		}
+		values = append(values, p.tok.value)
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenRParen {
+			return values, p.advance()
+		}
+		if err := p.expect(tokenComma, `"," or ")"`); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package odata
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF     tokenKind = iota
+	tokenLParen            // (
+	tokenRParen            // )
+	tokenComma             // ,
+	tokenWord              // keyword, property path or function name
+	tokenLiteral           // string, number, boolean, null, date, datetime or GUID
+)
+
+type token struct {
+	kind tokenKind
+	pos  int    // byte offset of the token in the input
+	text string // the token as written
+
+	// value holds the decoded literal: string, int64, float64, bool or nil.
+	// Dates, datetimes and GUIDs are kept as strings for the field builders to parse.
+	value any
+}
+
+// is reports whether the token is the given keyword (case-insensitive).
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+var (
+	identPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(/[A-Za-z_][A-Za-z0-9_]*)*$`)
+	guidPattern  = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+)
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+// next returns the next token, skipping whitespace.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && strings.IndexByte(" \t\n\r", l.input[l.pos]) >= 0 {
+		l.pos++
+	}
+	if l.pos == len(l.input) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	switch l.input[l.pos] {
+	case '(':
+		l.pos++
+		return token{kind: tokenLParen, pos: start, text: "("}, nil
+	case ')':
+		l.pos++
+		return token{kind: tokenRParen, pos: start, text: ")"}, nil
+	case ',':
+		l.pos++
+		return token{kind: tokenComma, pos: start, text: ","}, nil
+	case '\'':
+		return l.string()
+	}
+
+	for l.pos < len(l.input) && strings.IndexByte(" \t\n\r(),'", l.input[l.pos]) < 0 {
+		l.pos++
+	}
+	return classify(l.input[start:l.pos], start)
+}
+
+// string lexes a single-quoted string. A quote inside the string is escaped by doubling it.
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		l.pos++
+		if c != '\'' {
+			b.WriteByte(c)
+			continue
+		}
+		if l.pos < len(l.input) && l.input[l.pos] == '\'' {
+			b.WriteByte('\'')
+			l.pos++
+			continue
+		}
+		return token{kind: tokenLiteral, pos: start, text: l.input[start:l.pos], value: b.String()}, nil
+	}
+
+	return token{}, syntaxError(start, "unterminated string literal")
+}
+
+// classify turns an unquoted word into a literal or a word token.
+func classify(text string, pos int) (token, error) {
+	tok := token{kind: tokenLiteral, pos: pos, text: text}
+
+	switch strings.ToLower(text) {
+	case "true":
+		tok.value = true
+		return tok, nil
+	case "false":
+		tok.value = false
+		return tok, nil
+	case "null":
+		return tok, nil
+	}
+
+	if guidPattern.MatchString(text) {
+		tok.value = text
+		return tok, nil
+	}
+	if identPattern.MatchString(text) {
+		tok.kind = tokenWord
+		return tok, nil
+	}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		tok.value = i
+		return tok, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && !strings.ContainsAny(text, "xXpP_") {
+		tok.value = f
+		return tok, nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339Nano} {
+		if _, err := time.Parse(layout, text); err == nil {
+			tok.value = text
+			return tok, nil
+		}
+	}
+
+	return token{}, syntaxError(pos, "invalid token %q", text)
+}
@@ -0,0 +1,162 @@
+// Package odata translates OData v4 system query options into dewey filters, sorts and pagination.
+//
+// $filter becomes a filter.FilterGroup, $orderby becomes []sort.Criteria and $top/$skip
+// become a limit and offset, so one request drives the existing utilities:
+//
+//	// GET /users?$filter=age ge 18 and contains(tolower(email),'@acme.com')&$orderby=name desc&$top=25
+//	q, err := odata.ParseQuery(r.URL.Query())
+//	if err != nil {
+//	    return http.StatusBadRequest, err
+//	}
+//	query, err = filter.ApplyStructuredFiltersE(query, filterCfg, q.Filter, userFilterBuilders, predicates)
+//	query = sort.ApplyMultiple(query, sortCfg, sortFields, orderBuilder, q.OrderBy)
+//	query = pagination.Apply(query, pageCfg, q.Limit, q.Offset)
+//
+// Property paths such as Address/City are converted to the dotted field "Address.City".
+package odata
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/internal/parse"
+	"github.com/tone-labs/dewey/sort"
+)
+
+// SyntaxError reports a malformed $filter or $orderby expression. Pos is the byte offset in the
+// expression where the problem was found. Err is set when the problem maps to a filter
+// sentinel, e.g. an unsupported function wraps filter.ErrUnsupportedOperator.
+type SyntaxError parse.Error
+
+func (e *SyntaxError) Error() string {
+	return (*parse.Error)(e).Message("odata")
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+func syntaxError(pos int, format string, args ...any) *SyntaxError {
+	return (*SyntaxError)(parse.Errorf(pos, format, args...))
+}
+
+// Query holds the dewey equivalents of the supported OData system query options.
+type Query struct {
+	// Filter is parsed from $filter (an empty group when absent)
+	Filter filter.FilterGroup
+
+	// OrderBy is parsed from $orderby
+	OrderBy []sort.Criteria
+
+	// Limit and Offset are parsed from $top and $skip (0 when absent)
+	Limit  int
+	Offset int
+}
+
+// ParseQuery reads $filter, $orderby, $top and $skip from values. Other query options,
+// including $select, $expand and $count, are ignored.
+func ParseQuery(values url.Values) (Query, error) {
+	var q Query
+	var err error
+
+	if q.Filter, err = ParseFilter(values.Get("$filter")); err != nil {
+		return Query{}, fmt.Errorf("$filter: %w", err)
+	}
+	if q.OrderBy, err = ParseOrderBy(values.Get("$orderby")); err != nil {
+		return Query{}, fmt.Errorf("$orderby: %w", err)
+	}
+	if q.Limit, err = parseCount(values, "$top"); err != nil {
+		return Query{}, err
+	}
+	if q.Limit == 0 && values.Has("$top") {
+		// pagination.Apply treats 0 as "no limit", the opposite of what $top=0 asks for
+		return Query{}, fmt.Errorf("odata: $top must be greater than 0")
+	}
+	if q.Offset, err = parseCount(values, "$skip"); err != nil {
+		return Query{}, err
+	}
+
+	return q, nil
+}
+
+func parseCount(values url.Values, option string) (int, error) {
+	raw := values.Get(option)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("odata: %s must be a non-negative integer, got %q", option, raw)
+	}
+	return n, nil
+}
+
+// ParseOrderBy parses a $orderby expression such as "LastName asc, CreatedAt desc".
+// The direction defaults to ascending.
+func ParseOrderBy(expr string) ([]sort.Criteria, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	var criteria []sort.Criteria
+	for start := 0; start <= len(expr); {
+		end := strings.IndexByte(expr[start:], ',')
+		if end < 0 {
+			end = len(expr)
+		} else {
+			end += start
+		}
+
+		words := splitWords(expr[start:end], start)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, syntaxError(start, "expected property and optional direction, got %q", strings.TrimSpace(expr[start:end]))
+		}
+		if !identPattern.MatchString(words[0].text) {
+			return nil, syntaxError(words[0].pos, "invalid property %q", words[0].text)
+		}
+
+		c := sort.Criteria{Field: fieldName(words[0].text), Order: sort.Asc}
+		if len(words) == 2 {
+			switch strings.ToLower(words[1].text) {
+			case "asc":
+			case "desc":
+				c.Order = sort.Desc
+			default:
+				return nil, syntaxError(words[1].pos, "expected asc or desc, got %q", words[1].text)
+			}
+		}
+		criteria = append(criteria, c)
+		start = end + 1
+	}
+	return criteria, nil
+}
+
+type word struct {
+	text string
+	pos  int
+}
+
+// splitWords splits s on whitespace, recording each word's position relative to offset.
+func splitWords(s string, offset int) []word {
+	var words []word
+	for i := 0; i < len(s); {
+		if strings.IndexByte(" \t\n\r", s[i]) >= 0 {
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && strings.IndexByte(" \t\n\r", s[i]) < 0 {
+			i++
+		}
+		words = append(words, word{s[start:i], offset + start})
+	}
+	return words
+}
+
+// fieldName converts an OData property path to a dotted field name.
+func fieldName(path string) string {
+	return strings.ReplaceAll(path, "/", ".")
+}
@@ -0,0 +1,266 @@
+package odata_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/odata"
+	"github.com/tone-labs/dewey/sort"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected filter.FilterGroup
+	}{
+		{
+			name: "comparison",
+			expr: "Age ge 18",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "Age", Operator: filter.OpGte, Value: int64(18)}},
+			},
+		},
+		{
+			name: "and binds tighter than or",
+			expr: "Status eq 'active' and Price lt 9.99 or Vip eq true",
+			expected: filter.FilterGroup{
+				Logic:   "or",
+				Filters: []filter.Filter{{Field: "Vip", Operator: filter.OpEq, Value: true}},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "and",
+						Filters: []filter.Filter{
+							{Field: "Status", Operator: filter.OpEq, Value: "active"},
+							{Field: "Price", Operator: filter.OpLt, Value: 9.99},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "parentheses and not",
+			expr: "not (Role eq 'admin' or Role eq 'owner') and Address/City ne 'Paris'",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "Address.City", Operator: filter.OpNe, Value: "Paris"}},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "or",
+						Not:   true,
+						Filters: []filter.Filter{
+							{Field: "Role", Operator: filter.OpEq, Value: "admin"},
+							{Field: "Role", Operator: filter.OpEq, Value: "owner"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "string functions",
+			expr: "contains(Name,'Ann') and startswith(tolower(Email),'admin') and endswith(Code,'X') eq false",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "Name", Operator: filter.OpContainsCS, Value: "Ann"},
+					{Field: "Email", Operator: filter.OpStartsWith, Value: "admin"},
+				},
+				Groups: []filter.FilterGroup{
+					{
+						Logic:   "and",
+						Not:     true,
+						Filters: []filter.Filter{{Field: "Code", Operator: filter.OpEndsWithCS, Value: "X"}},
+					},
+				},
+			},
+		},
+		{
+			name: "in",
+			expr: "Status in ('active', 'pending')",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "Status", Operator: filter.OpIn, Value: []any{"active", "pending"}}},
+			},
+		},
+		{
+			name: "null comparisons",
+			expr: "DeletedAt eq null and ManagerId ne null",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "DeletedAt", Operator: filter.OpNull},
+					{Field: "ManagerId", Operator: filter.OpNnull},
+				},
+			},
+		},
+		{
+			name: "literal on the left is flipped",
+			expr: "18 lt Age",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "Age", Operator: filter.OpGt, Value: int64(18)}},
+			},
+		},
+		{
+			name: "dates, datetimes, guids and escaped quotes",
+			expr: "Born ge 2000-01-01 and CreatedAt lt 2024-03-01T10:00:00Z and Id eq 0d5c9c6e-63e2-4f4e-9d0b-1b1e0b7c1f5a and Name eq 'O''Brien'",
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "Born", Operator: filter.OpGte, Value: "2000-01-01"},
+					{Field: "CreatedAt", Operator: filter.OpLt, Value: "2024-03-01T10:00:00Z"},
+					{Field: "Id", Operator: filter.OpEq, Value: "0d5c9c6e-63e2-4f4e-9d0b-1b1e0b7c1f5a"},
+					{Field: "Name", Operator: filter.OpEq, Value: "O'Brien"},
+				},
+			},
+		},
+		{
+			name: "keywords are case-insensitive",
+			expr: "Age GE 18 AND NOT Banned EQ true",
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "Age", Operator: filter.OpGte, Value: int64(18)}},
+				Groups: []filter.FilterGroup{
+					{
+						Logic:   "and",
+						Not:     true,
+						Filters: []filter.Filter{{Field: "Banned", Operator: filter.OpEq, Value: true}},
+					},
+				},
+			},
+		},
+		{
+			name:     "empty",
+			expr:     "",
+			expected: filter.FilterGroup{Logic: "and"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := odata.ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(group, tt.expected) {
+				t.Errorf("\nexpected: %#v\ngot:      %#v", tt.expected, group)
+			}
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		pos  int
+		err  error
+	}{
+		{name: "missing operand", expr: "Age ge", pos: 6},
+		{name: "unknown operator", expr: "Age has 1", pos: 4},
+		{name: "unsupported function", expr: "length(Name) eq 3", pos: 0, err: filter.ErrUnsupportedOperator},
+		{name: "unsupported wrapper", expr: "contains(trim(Name),'a')", pos: 9, err: filter.ErrUnsupportedOperator},
+		{name: "non-string function argument", expr: "contains(Name,1)", pos: 14, err: filter.ErrInvalidValue},
+		{name: "two properties", expr: "Age eq Height", pos: 0},
+		{name: "null ordering", expr: "Age gt null", pos: 4},
+		{name: "unclosed parenthesis", expr: "(Age eq 1", pos: 9},
+		{name: "unterminated string", expr: "Name eq 'abc", pos: 8},
+		{name: "invalid token", expr: "Age eq 1abc", pos: 7},
+		{name: "trailing input", expr: "Age eq 1 Age", pos: 9},
+		{
+			name: "nested too deeply",
+			expr: "contains(" + strings.Repeat("tolower(", 100) + "Name" + strings.Repeat(")", 100) + ",'a')",
+			pos:  9 + 64*len("tolower("),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := odata.ParseFilter(tt.expr)
+
+			var syntaxErr *odata.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected SyntaxError, got %v", err)
+			}
+			if syntaxErr.Pos != tt.pos {
+				t.Errorf("expected position %d, got %d (%v)", tt.pos, syntaxErr.Pos, err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestParseOrderBy(t *testing.T) {
+	criteria, err := odata.ParseOrderBy("LastName, CreatedAt desc,Address/City ASC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []sort.Criteria{
+		{Field: "LastName", Order: sort.Asc},
+		{Field: "CreatedAt", Order: sort.Desc},
+		{Field: "Address.City", Order: sort.Asc},
+	}
+	if !reflect.DeepEqual(criteria, expected) {
+		t.Errorf("expected %v, got %v", expected, criteria)
+	}
+
+	for expr, pos := range map[string]int{
+		"Name sideways": 5,
+		"Name,,Age":     5,
+		"Name asc desc": 0,
+		"1Name":         0,
+	} {
+		var syntaxErr *odata.SyntaxError
+		if _, err := odata.ParseOrderBy(expr); !errors.As(err, &syntaxErr) || syntaxErr.Pos != pos {
+			t.Errorf("ParseOrderBy(%q): expected SyntaxError at %d, got %v", expr, pos, err)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	values := url.Values{
+		"$filter":  {"Age ge 18"},
+		"$orderby": {"Name desc"},
+		"$top":     {"25"},
+		"$skip":    {"50"},
+		"$select":  {"Name"},
+	}
+
+	q, err := odata.ParseQuery(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := odata.Query{
+		Filter: filter.FilterGroup{
+			Logic:   "and",
+			Filters: []filter.Filter{{Field: "Age", Operator: filter.OpGte, Value: int64(18)}},
+		},
+		OrderBy: []sort.Criteria{{Field: "Name", Order: sort.Desc}},
+		Limit:   25,
+		Offset:  50,
+	}
+	if !reflect.DeepEqual(q, expected) {
+		t.Errorf("\nexpected: %#v\ngot:      %#v", expected, q)
+	}
+
+	for _, invalid := range []url.Values{
+		{"$filter": {"Age ge"}},
+		{"$orderby": {"Name up"}},
+		{"$top": {"-1"}},
+		{"$top": {"0"}},
+		{"$skip": {"many"}},
+	} {
+		if _, err := odata.ParseQuery(invalid); err == nil {
+			t.Errorf("ParseQuery(%v): expected error", invalid)
+		}
+	}
+}
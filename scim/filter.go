@@ -0,0 +1,297 @@
+package scim
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/internal/parse"
+)
+
+// comparisons maps SCIM comparison operators onto dewey operators. co, sw and ew use the
+// case-insensitive string operators, matching the default caseExact=false of SCIM attributes.
+var comparisons = map[string]filter.Operator{
+	"eq": filter.OpEq,
+	"ne": filter.OpNe,
+	"co": filter.OpContains,
+	"sw": filter.OpStartsWith,
+	"ew": filter.OpEndsWith,
+	"gt": filter.OpGt,
+	"ge": filter.OpGte,
+	"lt": filter.OpLt,
+	"le": filter.OpLte,
+}
+
+type parser struct {
+	lexer      lexer
+	tok        token
+	attributes map[string]string // lower-cased name -> canonical name
+
+	// prefix is the multi-valued attribute while parsing a value filter, e.g. "emails" inside
+	// emails[type eq "work"]
+	prefix string
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return syntaxError(p.tok.pos, "unexpected end of input")
+	}
+	return syntaxError(p.tok.pos, "unexpected %q", p.tok.text)
+}
+
+// expect consumes a token of the given kind.
+func (p *parser) expect(kind tokenKind, what string) error {
+	if p.tok.kind != kind {
+		if p.tok.kind == tokenEOF {
+			return syntaxError(p.tok.pos, "expected %s, got end of input", what)
+		}
+		return syntaxError(p.tok.pos, "expected %s, got %q", what, p.tok.text)
+	}
+	return p.advance()
+}
+
+// or parses: and { "or" and }
+func (p *parser) or(depth int) (parse.Node, error) {
+	return p.list("or", depth, p.and)
+}
+
+// and parses: unary { "and" unary }
+func (p *parser) and(depth int) (parse.Node, error) {
+	return p.list("and", depth, p.unary)
+}
+
+func (p *parser) list(logic string, depth int, operand func(int) (parse.Node, error)) (parse.Node, error) {
+	first, err := operand(depth)
+	if err != nil {
+		return parse.Node{}, err
+	}
+
+	nodes := []parse.Node{first}
+	for p.tok.is(logic) {
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		n, err := operand(depth)
+		if err != nil {
+			return parse.Node{}, err
+		}
+		nodes = append(nodes, n)
+	}
+	return parse.Combine(logic, nodes...), nil
+}
+
+// unary parses: "not" "(" or ")" | "(" or ")" | attribute expression
+func (p *parser) unary(depth int) (parse.Node, error) {
+	if depth >= parse.MaxDepth {
+		return parse.Node{}, syntaxError(p.tok.pos, "filter nested deeper than %d levels", parse.MaxDepth)
+	}
+
+	negate := p.tok.is("not")
+	if negate {
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		if p.tok.kind != tokenLParen {
+			return parse.Node{}, syntaxError(p.tok.pos, `expected "(" after not`)
+		}
+	}
+
+	if p.tok.kind != tokenLParen {
+		return p.attributeExpression()
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+	n, err := p.or(depth + 1)
+	if err != nil {
+		return parse.Node{}, err
+	}
+	if err := p.expect(tokenRParen, `")"`); err != nil {
+		return parse.Node{}, err
+	}
+	if negate {
+		return n.Negate(), nil
+	}
+	return n, nil
+}
+
+// attributeExpression parses: attrPath "pr" | attrPath compareOp compValue | valuePath
+// valuePath is attrPath "[" valFilter "]", optionally followed by a sub-attribute comparison
+// such as emails[type eq "work"].value co "@example.com".
+func (p *parser) attributeExpression() (parse.Node, error) {
+	attr := p.tok
+	if attr.kind != tokenWord {
+		return parse.Node{}, p.unexpected()
+	}
+	field, err := p.attribute(attr.text, attr.pos)
+	if err != nil {
+		return parse.Node{}, err
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	if p.tok.kind != tokenLBracket {
+		return p.comparison(field)
+	}
+	if p.prefix != "" {
+		return parse.Node{}, syntaxError(p.tok.pos, "value filters cannot be nested")
+	}
+
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+	p.prefix = field
+	inner, err := p.or(1)
+	p.prefix = ""
+	if err != nil {
+		return parse.Node{}, err
+	}
+	end := p.tok.pos
+	if err := p.expect(tokenRBracket, `"]"`); err != nil {
+		return parse.Node{}, err
+	}
+
+	// emails[type eq "work"].value co "@example.com"
+	if p.tok.kind == tokenWord && p.tok.pos == end+1 && strings.HasPrefix(p.tok.text, ".") {
+		sub, err := p.attribute(field+p.tok.text, p.tok.pos)
+		if err != nil {
+			return parse.Node{}, err
+		}
+		if err := p.advance(); err != nil {
+			return parse.Node{}, err
+		}
+		n, err := p.comparison(sub)
+		if err != nil {
+			return parse.Node{}, err
+		}
+		return parse.Combine("and", inner, n), nil
+	}
+	return inner, nil
+}
+
+// comparison parses the operator and value following an attribute path.
+func (p *parser) comparison(field string) (parse.Node, error) {
+	opTok := p.tok
+	if opTok.is("pr") {
+		return parse.FilterNode(field, filter.OpNnull, nil), p.advance()
+	}
+
+	op, ok := filter.Operator(""), false
+	if opTok.kind == tokenWord {
+		op, ok = comparisons[strings.ToLower(opTok.text)]
+	}
+	if !ok {
+		if opTok.kind == tokenWord {
+			return parse.Node{}, &SyntaxError{
+				Pos: opTok.pos,
+				Msg: fmt.Sprintf("unknown operator %q", opTok.text),
+				Err: filter.ErrUnsupportedOperator,
+			}
+		}
+		if opTok.kind == tokenEOF {
+			return parse.Node{}, syntaxError(opTok.pos, "expected operator, got end of input")
+		}
+		return parse.Node{}, syntaxError(opTok.pos, "expected operator, got %q", opTok.text)
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	valTok := p.tok
+	if valTok.kind != tokenLiteral {
+		if valTok.kind == tokenEOF {
+			return parse.Node{}, syntaxError(valTok.pos, "expected value, got end of input")
+		}
+		return parse.Node{}, syntaxError(valTok.pos, "expected value, got %q", valTok.text)
+	}
+	if err := p.advance(); err != nil {
+		return parse.Node{}, err
+	}
+
+	invalid := func(format string) error {
+		return &SyntaxError{
+			Pos: valTok.pos,
+			Msg: fmt.Sprintf(format, opTok.text, valTok.text),
+			Err: filter.ErrInvalidValue,
+		}
+	}
+
+	switch valTok.value.(type) {
+	case nil:
+		switch op {
+		case filter.OpEq:
+			return parse.FilterNode(field, filter.OpNull, nil), nil
+		case filter.OpNe:
+			return parse.FilterNode(field, filter.OpNnull, nil), nil
+		}
+		return parse.Node{}, invalid("%s cannot compare with %s")
+	case string:
+	case bool:
+		if op != filter.OpEq && op != filter.OpNe {
+			return parse.Node{}, invalid("%s cannot compare with %s")
+		}
+	default:
+		if op == filter.OpContains || op == filter.OpStartsWith || op == filter.OpEndsWith {
+			return parse.Node{}, invalid("%s expects a string, got %s")
+		}
+	}
+	return parse.FilterNode(field, op, valTok.value), nil
+}
+
+// attribute validates an attribute path and returns its canonical field name. A schema URN
+// prefix is dropped ("urn:ietf:params:scim:schemas:core:2.0:User:userName" is "userName"),
+// sub-attributes are joined with dots, and inside a value filter the path is relative to the
+// multi-valued attribute.
+func (p *parser) attribute(path string, pos int) (string, error) {
+	if i := strings.LastIndexByte(path, ':'); i >= 0 {
+		path = path[i+1:]
+	}
+	for _, name := range strings.Split(path, ".") {
+		if !validName(name) {
+			return "", syntaxError(pos, "invalid attribute path %q", path)
+		}
+	}
+	if p.prefix != "" {
+		path = p.prefix + "." + path
+	}
+	return p.canonical(path), nil
+}
+
+// canonical maps a path onto the spelling in Parser.Attributes, resolving the longest listed
+// prefix so that "EMAILS.Value" becomes "emails.value" even if only "emails" is listed.
+// Paths that are not listed are lower-cased.
+func (p *parser) canonical(path string) string {
+	lower := strings.ToLower(path)
+	if name, ok := p.attributes[lower]; ok {
+		return name
+	}
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		return p.canonical(path[:i]) + lower[i:]
+	}
+	return lower
+}
+
+// validName reports whether s is an ATTRNAME: ALPHA *( "-" / "_" / DIGIT / ALPHA ), or the
+// reserved "$ref".
+func validName(s string) bool {
+	if s == "$ref" {
+		return true
+	}
+	for i, c := range s {
+		letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if !letter && (i == 0 || c != '-' && c != '_' && (c < '0' || c > '9')) {
+			return false
+		}
+	}
+	return s != ""
+}
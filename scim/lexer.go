@@ -0,0 +1,136 @@
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF      tokenKind = iota
+	tokenLParen             // (
+	tokenRParen             // )
+	tokenLBracket           // [
+	tokenRBracket           // ]
+	tokenWord               // keyword or attribute path
+	tokenLiteral            // string, number, true, false or null
+)
+
+type token struct {
+	kind tokenKind
+	pos  int    // byte offset of the token in the input
+	text string // the token as written
+
+	// value holds the decoded literal: string, int64, float64, bool or nil.
+	// Dates are kept as strings for the field builders to parse.
+	value any
+}
+
+// is reports whether the token is the given keyword. Keywords are case-insensitive.
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// wordEnd reports whether c ends an unquoted word.
+func wordEnd(c byte) bool {
+	return strings.IndexByte(" \t\n\r()[]\"", c) >= 0
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+// next returns the next token, skipping whitespace.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && strings.IndexByte(" \t\n\r", l.input[l.pos]) >= 0 {
+		l.pos++
+	}
+	if l.pos == len(l.input) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	single := func(kind tokenKind) (token, error) {
+		l.pos++
+		return token{kind: kind, pos: start, text: l.input[start:l.pos]}, nil
+	}
+
+	switch l.input[l.pos] {
+	case '(':
+		return single(tokenLParen)
+	case ')':
+		return single(tokenRParen)
+	case '[':
+		return single(tokenLBracket)
+	case ']':
+		return single(tokenRBracket)
+	case '"':
+		return l.string()
+	}
+
+	for l.pos < len(l.input) && !wordEnd(l.input[l.pos]) {
+		l.pos++
+	}
+	return classify(l.input[start:l.pos], start), nil
+}
+
+// string lexes a JSON string literal, including its escape sequences.
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	l.pos++
+
+	for l.pos < len(l.input) {
+		switch l.input[l.pos] {
+		case '\\':
+			l.pos += 2
+			continue
+		case '"':
+			l.pos++
+			text := l.input[start:l.pos]
+			var s string
+			if err := json.Unmarshal([]byte(text), &s); err != nil {
+				return token{}, syntaxError(start, "invalid string literal %s", text)
+			}
+			return token{kind: tokenLiteral, pos: start, text: text, value: s}, nil
+		}
+		l.pos++
+	}
+
+	return token{}, syntaxError(start, "unterminated string literal")
+}
+
+// classify turns an unquoted word into a literal or a word token. Anything that is not
+// true, false, null or a number is a word; the parser validates attribute paths.
+func classify(text string, pos int) token {
+	tok := token{kind: tokenLiteral, pos: pos, text: text}
+
+	switch strings.ToLower(text) {
+	case "true":
+		tok.value = true
+		return tok
+	case "false":
+		tok.value = false
+		return tok
+	case "null":
+		return tok
+	}
+
+	if c := text[0]; c == '-' || c >= '0' && c <= '9' {
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			tok.value = i
+			return tok
+		}
+		if json.Valid([]byte(text)) {
+			if f, err := strconv.ParseFloat(text, 64); err == nil {
+				tok.value = f
+				return tok
+			}
+		}
+	}
+
+	tok.kind = tokenWord
+	return tok
+}
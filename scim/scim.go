@@ -0,0 +1,174 @@
+// Package scim translates SCIM 2.0 (RFC 7644) list query parameters into dewey filters, sorts
+// and pagination, for /Users and /Groups endpoints consumed by identity providers.
+//
+// filter becomes a filter.FilterGroup, sortBy/sortOrder become []sort.Criteria and the 1-based
+// startIndex/count become a limit and offset:
+//
+//	// GET /Users?filter=userName sw "j" and emails[type eq "work"].value co "@example.com"&startIndex=1&count=25
+//	q, err := scim.ParseQuery(r.URL.Query())
+//	if err != nil {
+//	    return scimError(http.StatusBadRequest, "invalidFilter", err)
+//	}
+//	query, err = filter.ApplyStructuredFiltersE(query, filterCfg, q.Filter, userFilterBuilders, predicates)
+//	query = sort.ApplyMultiple(query, sortCfg, sortFields, orderBuilder, q.SortBy)
+//	query = pagination.Apply(query, pageCfg, q.Limit, q.Offset)
+//
+// Attribute names are case-insensitive, as RFC 7644 requires. By default they are lower-cased
+// ("userName" and "USERNAME" both become "username"); set Parser.Attributes to map them onto
+// the spelling your field builders are registered under instead.
+package scim
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/internal/parse"
+	"github.com/tone-labs/dewey/sort"
+)
+
+// SyntaxError reports a malformed filter or sortBy value. Pos is the byte offset in the
+// expression where the problem was found. SCIM servers respond to these with a 400 and the
+// scimType "invalidFilter". An unknown operator sets Err to filter.ErrUnsupportedOperator.
+type SyntaxError parse.Error
+
+func (e *SyntaxError) Error() string {
+	return (*parse.Error)(e).Message("scim")
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+func syntaxError(pos int, format string, args ...any) *SyntaxError {
+	return (*SyntaxError)(parse.Errorf(pos, format, args...))
+}
+
+// Query holds the dewey equivalents of the SCIM list query parameters.
+type Query struct {
+	// Filter is parsed from filter (an empty group when absent)
+	Filter filter.FilterGroup
+
+	// SortBy is parsed from sortBy and sortOrder (empty when sortBy is absent)
+	SortBy []sort.Criteria
+
+	// Limit and Offset are parsed from count and startIndex (0 when absent)
+	Limit  int
+	Offset int
+
+	// CountOnly is set for count=0, which asks for totalResults without any resources.
+	// pagination.Apply treats a limit of 0 as "no limit", so handlers should check this first.
+	CountOnly bool
+}
+
+// Parser parses SCIM filters and query parameters. The zero value is ready to use.
+type Parser struct {
+	// Attributes lists the canonical spelling of filterable and sortable attributes, e.g.
+	// "userName" or "name.familyName". Attribute paths are matched against it
+	// case-insensitively; paths that are not listed are lower-cased.
+	Attributes []string
+}
+
+// ParseFilter parses a filter expression with a zero Parser.
+func ParseFilter(expr string) (filter.FilterGroup, error) {
+	return Parser{}.ParseFilter(expr)
+}
+
+// ParseQuery parses list query parameters with a zero Parser.
+func ParseQuery(values url.Values) (Query, error) {
+	return Parser{}.ParseQuery(values)
+}
+
+// ParseFilter parses a SCIM filter expression into a filter group. An empty expression yields
+// an empty group.
+//
+// Supported: eq, ne, co, sw, ew, pr, gt, ge, lt, le, and, or, not (...), parentheses and value
+// paths. co, sw and ew map to contains, startswith and endswith; pr maps to nnull, and
+// "eq null"/"ne null" map to null and nnull. Operators and keywords are case-insensitive.
+// Values are passed to the field builders as string, int64, float64 or bool; dateTime values
+// are strings.
+//
+// A value path such as emails[type eq "work" and value co "@example.com"] becomes a group over
+// the dotted fields "emails.type" and "emails.value". dewey has no per-element quantifier, so
+// whether both conditions must hold for the same element is up to the field builders.
+func (sp Parser) ParseFilter(expr string) (filter.FilterGroup, error) {
+	p := &parser{lexer: lexer{input: expr}, attributes: sp.attributeMap()}
+	if err := p.advance(); err != nil {
+		return filter.FilterGroup{}, err
+	}
+	if p.tok.kind == tokenEOF {
+		return filter.FilterGroup{Logic: "and"}, nil
+	}
+
+	n, err := p.or(0)
+	if err != nil {
+		return filter.FilterGroup{}, err
+	}
+	if p.tok.kind != tokenEOF {
+		return filter.FilterGroup{}, p.unexpected()
+	}
+	return n.ToGroup(), nil
+}
+
+// ParseQuery reads filter, sortBy, sortOrder, startIndex and count from values. Other
+// parameters, including attributes and excludedAttributes, are ignored.
+//
+// As RFC 7644 specifies, sortOrder defaults to ascending, a startIndex below 1 is treated as 1
+// and a negative count is treated as 0.
+func (sp Parser) ParseQuery(values url.Values) (Query, error) {
+	var q Query
+	var err error
+
+	if q.Filter, err = sp.ParseFilter(values.Get("filter")); err != nil {
+		return Query{}, fmt.Errorf("filter: %w", err)
+	}
+
+	if sortBy := strings.TrimSpace(values.Get("sortBy")); sortBy != "" {
+		p := &parser{attributes: sp.attributeMap()}
+		field, err := p.attribute(sortBy, 0)
+		if err != nil {
+			return Query{}, fmt.Errorf("sortBy: %w", err)
+		}
+
+		c := sort.Criteria{Field: field, Order: sort.Asc}
+		switch order := values.Get("sortOrder"); {
+		case order == "" || strings.EqualFold(order, "ascending"):
+		case strings.EqualFold(order, "descending"):
+			c.Order = sort.Desc
+		default:
+			return Query{}, fmt.Errorf("scim: sortOrder must be ascending or descending, got %q", order)
+		}
+		q.SortBy = []sort.Criteria{c}
+	}
+
+	if raw := values.Get("startIndex"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return Query{}, fmt.Errorf("scim: startIndex must be an integer, got %q", raw)
+		}
+		q.Offset = max(n, 1) - 1
+	}
+	if raw := values.Get("count"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return Query{}, fmt.Errorf("scim: count must be an integer, got %q", raw)
+		}
+		q.Limit = max(n, 0)
+		q.CountOnly = q.Limit == 0
+	}
+
+	return q, nil
+}
+
+func (sp Parser) attributeMap() map[string]string {
+	if len(sp.Attributes) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(sp.Attributes))
+	for _, name := range sp.Attributes {
+		m[strings.ToLower(name)] = name
+	}
+	return m
+}
@@ -0,0 +1,284 @@
+package scim_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/tone-labs/dewey/filter"
+	"github.com/tone-labs/dewey/scim"
+	"github.com/tone-labs/dewey/sort"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected filter.FilterGroup
+	}{
+		{
+			name: "comparison",
+			expr: `userName eq "bjensen"`,
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "username", Operator: filter.OpEq, Value: "bjensen"}},
+			},
+		},
+		{
+			name: "string operators and presence",
+			expr: `name.familyName co "O'Malley" and userName sw "J" and title ew "er" and title pr`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "name.familyname", Operator: filter.OpContains, Value: "O'Malley"},
+					{Field: "username", Operator: filter.OpStartsWith, Value: "J"},
+					{Field: "title", Operator: filter.OpEndsWith, Value: "er"},
+					{Field: "title", Operator: filter.OpNnull},
+				},
+			},
+		},
+		{
+			name: "and binds tighter than or",
+			expr: `userType eq "Employee" and title pr or userType eq "Intern"`,
+			expected: filter.FilterGroup{
+				Logic: "or",
+				Filters: []filter.Filter{
+					{Field: "usertype", Operator: filter.OpEq, Value: "Intern"},
+				},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "and",
+						Filters: []filter.Filter{
+							{Field: "usertype", Operator: filter.OpEq, Value: "Employee"},
+							{Field: "title", Operator: filter.OpNnull},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "keywords are case-insensitive",
+			expr: `userType EQ "Employee" AND NOT (emails.value CO "example.org" Or emails.value co "example.com")`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "usertype", Operator: filter.OpEq, Value: "Employee"},
+				},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "or",
+						Not:   true,
+						Filters: []filter.Filter{
+							{Field: "emails.value", Operator: filter.OpContains, Value: "example.org"},
+							{Field: "emails.value", Operator: filter.OpContains, Value: "example.com"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "literals",
+			expr: `meta.lastModified gt "2011-05-13T04:42:34Z" and active eq true and loginCount ge 3 and score lt 0.5 and manager eq null`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "meta.lastmodified", Operator: filter.OpGt, Value: "2011-05-13T04:42:34Z"},
+					{Field: "active", Operator: filter.OpEq, Value: true},
+					{Field: "logincount", Operator: filter.OpGte, Value: int64(3)},
+					{Field: "score", Operator: filter.OpLt, Value: 0.5},
+					{Field: "manager", Operator: filter.OpNull},
+				},
+			},
+		},
+		{
+			name: "escaped string",
+			expr: `displayName eq "say \"hi\"!"`,
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "displayname", Operator: filter.OpEq, Value: `say "hi"!`}},
+			},
+		},
+		{
+			name: "schema urn",
+			expr: `urn:ietf:params:scim:schemas:core:2.0:User:name.givenName eq "Barbara"`,
+			expected: filter.FilterGroup{
+				Logic:   "and",
+				Filters: []filter.Filter{{Field: "name.givenname", Operator: filter.OpEq, Value: "Barbara"}},
+			},
+		},
+		{
+			name: "value path",
+			expr: `userType eq "Employee" and emails[type eq "work" and value co "@example.com"]`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "usertype", Operator: filter.OpEq, Value: "Employee"},
+				},
+				Groups: []filter.FilterGroup{
+					{
+						Logic: "and",
+						Filters: []filter.Filter{
+							{Field: "emails.type", Operator: filter.OpEq, Value: "work"},
+							{Field: "emails.value", Operator: filter.OpContains, Value: "@example.com"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "value path with sub-attribute",
+			expr: `emails[type eq "work"].value co "@x.com"`,
+			expected: filter.FilterGroup{
+				Logic: "and",
+				Filters: []filter.Filter{
+					{Field: "emails.type", Operator: filter.OpEq, Value: "work"},
+					{Field: "emails.value", Operator: filter.OpContains, Value: "@x.com"},
+				},
+			},
+		},
+		{
+			name:     "empty",
+			expr:     " ",
+			expected: filter.FilterGroup{Logic: "and"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := scim.ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(group, tt.expected) {
+				t.Errorf("\nexpected: %#v\ngot:      %#v", tt.expected, group)
+			}
+		})
+	}
+}
+
+func TestParser_Attributes(t *testing.T) {
+	p := scim.Parser{Attributes: []string{"userName", "name.familyName", "emails"}}
+
+	group, err := p.ParseFilter(`USERNAME eq "x" and NAME.FAMILYNAME pr and Emails[Type eq "work"]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := filter.FilterGroup{
+		Logic: "and",
+		Filters: []filter.Filter{
+			{Field: "userName", Operator: filter.OpEq, Value: "x"},
+			{Field: "name.familyName", Operator: filter.OpNnull},
+			{Field: "emails.type", Operator: filter.OpEq, Value: "work"},
+		},
+	}
+	if !reflect.DeepEqual(group, expected) {
+		t.Errorf("\nexpected: %#v\ngot:      %#v", expected, group)
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		pos  int
+		err  error
+	}{
+		{name: "unknown operator", expr: `userName like "j"`, pos: 9, err: filter.ErrUnsupportedOperator},
+		{name: "substring of a number", expr: `loginCount co 3`, pos: 14, err: filter.ErrInvalidValue},
+		{name: "ordering a boolean", expr: `active gt false`, pos: 10, err: filter.ErrInvalidValue},
+		{name: "ordering null", expr: `manager lt null`, pos: 11, err: filter.ErrInvalidValue},
+		{name: "missing value", expr: `userName eq`, pos: 11},
+		{name: "unquoted string", expr: `userName eq bjensen`, pos: 12},
+		{name: "not without parentheses", expr: `not userName pr`, pos: 4},
+		{name: "nested value path", expr: `groups[members[value pr]]`, pos: 14},
+		{name: "unclosed value path", expr: `emails[type eq "work"`, pos: 21},
+		{name: "invalid attribute", expr: `1st eq "x"`, pos: 0},
+		{name: "unterminated string", expr: `title eq "VP`, pos: 9},
+		{name: "dangling or", expr: `title pr or`, pos: 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := scim.ParseFilter(tt.expr)
+
+			var syntaxErr *scim.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected SyntaxError, got %v", err)
+			}
+			if syntaxErr.Pos != tt.pos {
+				t.Errorf("expected position %d, got %d (%v)", tt.pos, syntaxErr.Pos, err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected scim.Query
+		wantErr  bool
+	}{
+		{
+			name:  "all parameters",
+			query: `filter=title+pr&sortBy=name.familyName&sortOrder=descending&startIndex=11&count=10`,
+			expected: scim.Query{
+				Filter: filter.FilterGroup{
+					Logic:   "and",
+					Filters: []filter.Filter{{Field: "title", Operator: filter.OpNnull}},
+				},
+				SortBy: []sort.Criteria{{Field: "name.familyname", Order: sort.Desc}},
+				Limit:  10,
+				Offset: 10,
+			},
+		},
+		{
+			name:  "defaults",
+			query: `sortBy=userName`,
+			expected: scim.Query{
+				Filter: filter.FilterGroup{Logic: "and"},
+				SortBy: []sort.Criteria{{Field: "username", Order: sort.Asc}},
+			},
+		},
+		{
+			name:  "out of range values are coerced",
+			query: `startIndex=0&count=-5`,
+			expected: scim.Query{
+				Filter:    filter.FilterGroup{Logic: "and"},
+				CountOnly: true,
+			},
+		},
+		{name: "invalid sortOrder", query: `sortBy=userName&sortOrder=down`, wantErr: true},
+		{name: "invalid sortBy", query: `sortBy=user+name`, wantErr: true},
+		{name: "invalid startIndex", query: `startIndex=first`, wantErr: true},
+		{name: "invalid filter", query: `filter=title`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			q, err := scim.ParseQuery(values)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %#v", q)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(q, tt.expected) {
+				t.Errorf("\nexpected: %#v\ngot:      %#v", tt.expected, q)
+			}
+		})
+	}
+}